@@ -2,26 +2,23 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	// Embeds the tz database so city timezones resolve on hosts without
+	// zoneinfo files, such as scratch and distroless images
+	_ "time/tzdata"
 
 	"github.com/alexivanou/geocity-api/internal/api"
 	"github.com/alexivanou/geocity-api/internal/config"
 	"github.com/alexivanou/geocity-api/internal/database"
-	"github.com/alexivanou/geocity-api/internal/model"
 	"github.com/alexivanou/geocity-api/internal/repository"
 	"github.com/alexivanou/geocity-api/internal/seeder"
 	"github.com/alexivanou/geocity-api/internal/service"
 	"github.com/alexivanou/geocity-api/internal/stats"
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/sqlite3"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
@@ -52,22 +49,33 @@ func main() {
 
 	ctx := context.Background()
 	// Run migrations
-	if err := runMigrations(db, cfg); err != nil {
+	if err := database.Migrate(db, cfg.DB, "migrations"); err != nil {
 		logger.Fatal("Failed to run migrations", zap.Error(err))
 	}
 
 	isEmpty, err := repository.IsDatabaseEmpty(ctx, db)
 	if err != nil {
 		logger.Warn("Failed to check if database is empty", zap.Error(err))
-	} else if isEmpty {
-		logger.Info("Database is empty, auto-seeding data...")
-		if err := autoSeedDatabase(ctx, db, repos, cfg, logger); err != nil {
+	} else if needed, err := seeder.SeedNeeded(ctx, repos.SeedState, isEmpty); err != nil {
+		logger.Fatal("Failed to check seed state", zap.Error(err))
+	} else if needed {
+		// An interrupted seed is resumed rather than serving partial data
+		logger.Info("Database is empty or partially seeded, auto-seeding data...")
+		if err := autoSeedDatabase(ctx, repos, cfg, logger); err != nil {
 			logger.Fatal("Failed to auto-seed database", zap.Error(err))
 		}
 		logger.Info("Database seeded successfully")
 	}
 
-	svc := service.NewService(repos.City, repos.Country, repos.Translation)
+	if err := applyDailyUpdates(ctx, repos, cfg, logger); err != nil {
+		logger.Warn("Failed to apply daily updates", zap.Error(err))
+	}
+
+	if err := repository.WarmUp(ctx, repos); err != nil {
+		logger.Warn("Failed to build in-memory indexes", zap.Error(err))
+	}
+
+	svc := service.NewService(repos.City, repos.Country, repos.Translation, repos.PostalCode, repos.Update)
 	statsCollector := stats.NewCollector(db, cfg.DB)
 	router := api.NewRouter(svc, statsCollector)
 
@@ -101,102 +109,25 @@ func main() {
 	logger.Info("Server exited")
 }
 
-func runMigrations(db *sqlx.DB, cfg *config.Config) error {
-	var m *migrate.Migrate
-	var err error
-
-	// Choose migration source based on DB type
-	sourcePath := "file://migrations/postgres"
-
-	if cfg.DB.IsMemory() {
-		sourcePath = "file://migrations/sqlite"
-		// Use driver instance directly to avoid DSN parsing issues with in-memory SQLite
-		driver, err := sqlite3.WithInstance(db.DB, &sqlite3.Config{})
-		if err != nil {
-			return fmt.Errorf("could not create sqlite driver: %w", err)
-		}
-		m, err = migrate.NewWithDatabaseInstance(
-			sourcePath,
-			"sqlite3",
-			driver,
-		)
-		if err != nil {
-			return fmt.Errorf("could not create migrate instance: %w", err)
-		}
-	} else {
-		// For Postgres, standard connection string works fine
-		m, err = migrate.New(sourcePath, cfg.DB.DSN())
-		if err != nil {
-			return err
-		}
-	}
-
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return err
-	}
-	return nil
+// autoSeedDatabase imports the GeoNames dumps in data/, resuming an
+// interrupted earlier seed
+func autoSeedDatabase(ctx context.Context, repos *repository.Container, cfg *config.Config, logger *zap.Logger) error {
+	return seeder.Seed(ctx, seeder.NewParser("data", cfg.Seeder), repos, logger)
 }
 
-func autoSeedDatabase(ctx context.Context, db *sqlx.DB, repos *repository.Container, cfg *config.Config, logger *zap.Logger) error {
-	parser := seeder.NewParser("data", cfg.Seeder)
-
-	logger.Info("Parsing countries...")
-	countries, err := parser.ParseCountries()
-	if err != nil {
-		return fmt.Errorf("failed to parse countries: %w", err)
-	}
-
-	logger.Info("Parsing cities...")
-	cities, err := parser.ParseCities()
-	if err != nil {
-		return fmt.Errorf("failed to parse cities: %w", err)
-	}
-
-	logger.Info("Inserting countries...")
-	if err := repos.Country.BulkInsertCountries(ctx, countries); err != nil {
-		return fmt.Errorf("failed to insert countries: %w", err)
-	}
-
-	logger.Info("Inserting cities...")
-	if err := repos.City.BulkInsertCities(ctx, cities); err != nil {
-		return fmt.Errorf("failed to insert cities: %w", err)
-	}
-
-	cityIDMap := seeder.CreateCityIDMap(cities)
-	countryCodeMap := seeder.CreateCountryCodeMap(countries)
-	geonameIDToCountryCode := seeder.CreateCountryGeonameIDMap(countries)
-
-	logger.Info("Parsing alternate names (streaming mode)...")
-	var totalCityTranslations int
-	var totalCountryTranslations int
-
-	err = parser.ProcessAlternateNamesWithCountries(
-		cityIDMap,
-		countryCodeMap,
-		geonameIDToCountryCode,
-		func(batch []model.CityTranslation) error {
-			if err := repos.Translation.BulkInsertCityTranslations(ctx, batch); err != nil {
-				return err
-			}
-			totalCityTranslations += len(batch)
-			return nil
-		},
-		func(batch []model.CountryTranslation) error {
-			if err := repos.Translation.BulkInsertCountryTranslations(ctx, batch); err != nil {
-				return err
-			}
-			totalCountryTranslations += len(batch)
-			return nil
-		},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to process alternate names: %w", err)
+// applyDailyUpdates applies the GeoNames daily update files in data/ that
+// are newer than the recorded data date, so a seeded database does not need
+// a full reseed to stay current
+func applyDailyUpdates(ctx context.Context, repos *repository.Container, cfg *config.Config, logger *zap.Logger) error {
+	applied, err := seeder.ApplyPendingUpdates(ctx, seeder.NewParser("data", cfg.Seeder), repos.Update)
+	for _, update := range applied {
+		logger.Info("Applied daily update",
+			zap.String("date", update.Date),
+			zap.Int("cities_modified", update.CitiesModified),
+			zap.Int("cities_deleted", update.CitiesDeleted),
+			zap.Int("names_modified", update.NamesModified),
+			zap.Int("names_deleted", update.NamesDeleted),
+		)
 	}
-
-	logger.Info("Processed translations",
-		zap.Int("city_translations", totalCityTranslations),
-		zap.Int("country_translations", totalCountryTranslations),
-	)
-
-	return nil
+	return err
 }
@@ -2,21 +2,25 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/alexivanou/geocity-api/internal/config"
 	"github.com/alexivanou/geocity-api/internal/database"
-	"github.com/alexivanou/geocity-api/internal/model"
 	"github.com/alexivanou/geocity-api/internal/repository"
 	"github.com/alexivanou/geocity-api/internal/seeder"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
 func main() {
+	updatesOnly := flag.Bool("updates", false, "Apply the GeoNames daily update files in data/ instead of a full import")
+	force := flag.Bool("force", false, "Swap in a reload even if it holds far fewer cities or names than the live data")
+	dumpDate := flag.String("dump-date", "", "Date (YYYY-MM-DD) the data files are current as of; defaults to the day before their download's Last-Modified; with -updates, recorded if no dump date is")
+	flag.Parse()
+
 	logger, err := zap.NewDevelopment()
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
@@ -27,6 +31,12 @@ func main() {
 	if err != nil {
 		logger.Fatal("Failed to load config", zap.Error(err))
 	}
+	if *dumpDate != "" {
+		if _, err := time.Parse(time.DateOnly, *dumpDate); err != nil {
+			logger.Fatal("Invalid -dump-date, expected YYYY-MM-DD", zap.Error(err))
+		}
+		cfg.Seeder.DumpDate = *dumpDate
+	}
 
 	db, err := database.Connect(context.Background(), cfg.DB)
 	if err != nil {
@@ -39,87 +49,109 @@ func main() {
 	}
 
 	logger.Info("Connected to database", zap.String("type", string(cfg.DB.Type)))
-	logger.Info("Starting data import...")
 
 	parser := seeder.NewParser("data", cfg.Seeder)
 
-	logger.Info("Parsing countries...")
-	countries, err := parser.ParseCountries()
-	if err != nil {
-		logger.Fatal("Failed to parse countries", zap.Error(err))
+	if *updatesOnly {
+		applyUpdates(context.Background(), parser, repository.NewRepositories(db, cfg.DB.Type), logger)
+		return
 	}
 
-	logger.Info("Parsing cities...")
-	cities, err := parser.ParseCities()
+	ctx := context.Background()
+	if err := database.Migrate(db, cfg.DB, "migrations"); err != nil {
+		logger.Fatal("Failed to run migrations", zap.Error(err))
+	}
+
+	repos := repository.NewRepositories(db, cfg.DB.Type)
+
+	isEmpty, err := repository.IsDatabaseEmpty(ctx, db)
+	if err != nil {
+		logger.Fatal("Failed to check if database is empty", zap.Error(err))
+	}
+	needed, err := seeder.SeedNeeded(ctx, repos.SeedState, isEmpty)
 	if err != nil {
-		logger.Fatal("Failed to parse cities", zap.Error(err))
+		logger.Fatal("Failed to check seed state", zap.Error(err))
 	}
 
-	ctx := context.Background()
-	// Auto-migrate if using memory DB to ensure schema exists
-	if cfg.DB.IsMemory() {
-		m, err := migrate.New("file://migrations", "sqlite3://"+cfg.DB.DSN())
-		if err != nil {
-			logger.Fatal("Failed to init migration", zap.Error(err))
+	if needed {
+		// Nothing is served from an empty or partially seeded database, so
+		// it is seeded in place
+		logger.Info("Starting data import...")
+		if err := seeder.Seed(ctx, parser, repos, logger); err != nil {
+			logger.Fatal("Data import failed; run the seeder again to resume", zap.Error(err))
 		}
-		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-			logger.Fatal("Failed to run migration", zap.Error(err))
+		logger.Info("Data import completed successfully!")
+	} else {
+		logger.Info("Database is seeded, reloading it through staging tables...")
+		if err := reload(ctx, db, repos, cfg.DB, parser, *force, logger); err != nil {
+			logger.Fatal("Reload failed; the live data is unchanged", zap.Error(err))
 		}
+		logger.Info("Reload swapped in successfully!")
 	}
 
-	// Begin transaction is handled by Repository usually or here if we want atomic seed
-	// For simplicity using repos directly.
-	repos := repository.NewRepositories(db, cfg.DB.Type)
+	applyUpdates(ctx, parser, repos, logger)
+}
 
-	// Clear existing data (optional, simplified)
-	if cfg.DB.IsMemory() {
-		// Fast truncate for testing
-		_, _ = db.Exec("DELETE FROM city_translations; DELETE FROM country_translations; DELETE FROM cities; DELETE FROM countries;")
-	}
-
-	logger.Info("Inserting countries...")
-	if err := repos.Country.BulkInsertCountries(ctx, countries); err != nil {
-		logger.Fatal("Failed to insert countries", zap.Error(err))
-	}
-
-	logger.Info("Inserting cities...")
-	if err := repos.City.BulkInsertCities(ctx, cities); err != nil {
-		logger.Fatal("Failed to insert cities", zap.Error(err))
-	}
-
-	cityIDMap := seeder.CreateCityIDMap(cities)
-	countryCodeMap := seeder.CreateCountryCodeMap(countries)
-	geonameIDToCountryCode := seeder.CreateCountryGeonameIDMap(countries)
-
-	logger.Info("Parsing alternate names (streaming mode)...")
-	var totalCityTranslations int
-	var totalCountryTranslations int
-
-	err = parser.ProcessAlternateNamesWithCountries(
-		cityIDMap,
-		countryCodeMap,
-		geonameIDToCountryCode,
-		func(batch []model.CityTranslation) error {
-			if err := repos.Translation.BulkInsertCityTranslations(ctx, batch); err != nil {
-				return fmt.Errorf("failed to insert city translations batch: %w", err)
-			}
-			totalCityTranslations += len(batch)
-			return nil
-		},
-		func(batch []model.CountryTranslation) error {
-			if err := repos.Translation.BulkInsertCountryTranslations(ctx, batch); err != nil {
-				return fmt.Errorf("failed to insert country translations batch: %w", err)
-			}
-			totalCountryTranslations += len(batch)
-			return nil
-		},
-	)
+// reload seeds a staging copy of the data tables while the live ones keep
+// serving, validates its row counts and swaps it in within one transaction.
+// An interrupted staging seed resumes on the next run, like a live one.
+func reload(ctx context.Context, db *sqlx.DB, repos *repository.Container, dbCfg config.DBConfig,
+	parser *seeder.Parser, force bool, logger *zap.Logger) error {
+	reloads := repository.NewReloadRepository(db, dbCfg, repos)
+	if err := reloads.PrepareStaging(ctx); err != nil {
+		return fmt.Errorf("failed to prepare staging: %w", err)
+	}
+
+	stagingCfg := dbCfg.Staging()
+	stagingDB, err := database.Connect(ctx, stagingCfg)
 	if err != nil {
-		logger.Fatal("Failed to process alternate names", zap.Error(err))
+		return fmt.Errorf("failed to connect to staging: %w", err)
+	}
+	defer stagingDB.Close()
+
+	if err := database.Migrate(stagingDB, stagingCfg, "migrations"); err != nil {
+		return fmt.Errorf("failed to migrate staging: %w", err)
+	}
+	if err := seeder.Seed(ctx, parser, repository.NewRepositories(stagingDB, dbCfg.Type), logger); err != nil {
+		return fmt.Errorf("failed to seed staging; run the seeder again to resume: %w", err)
 	}
 
-	logger.Info("Data import completed successfully!",
-		zap.Int("cities", len(cities)),
-		zap.Int("city_translations", totalCityTranslations),
+	staged, err := repository.CountRows(ctx, stagingDB)
+	if err != nil {
+		return err
+	}
+	live, err := repository.CountRows(ctx, db)
+	if err != nil {
+		return err
+	}
+	logger.Info("Staged reload",
+		zap.Int64("cities", staged["cities"]), zap.Int64("live_cities", live["cities"]),
+		zap.Int64("city_translations", staged["city_translations"]), zap.Int64("live_city_translations", live["city_translations"]),
 	)
+	if err := seeder.ValidateReload(staged, live, force); err != nil {
+		if dropErr := reloads.DropStaging(ctx); dropErr != nil {
+			logger.Warn("Failed to drop staging", zap.Error(dropErr))
+		}
+		return fmt.Errorf("staged reload rejected: %w", err)
+	}
+
+	return reloads.SwapStaging(ctx)
+}
+
+// applyUpdates applies the daily update files newer than the recorded data date
+func applyUpdates(ctx context.Context, parser *seeder.Parser, repos *repository.Container, logger *zap.Logger) {
+	applied, err := seeder.ApplyPendingUpdates(ctx, parser, repos.Update)
+	for _, update := range applied {
+		logger.Info("Applied daily update",
+			zap.String("date", update.Date),
+			zap.Int("cities_modified", update.CitiesModified),
+			zap.Int("cities_deleted", update.CitiesDeleted),
+			zap.Int("names_modified", update.NamesModified),
+			zap.Int("names_deleted", update.NamesDeleted),
+		)
+	}
+	if err != nil {
+		logger.Fatal("Failed to apply daily updates", zap.Error(err))
+	}
+	logger.Info("Daily updates applied", zap.Int("days", len(applied)))
 }
@@ -2,9 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alexivanou/geocity-api/internal/model"
 	"github.com/alexivanou/geocity-api/internal/service"
@@ -55,45 +59,144 @@ func (h *Handler) SuggestCities(w http.ResponseWriter, r *http.Request) {
 		Limit: limit,
 	}
 
+	if fuzzyStr := r.URL.Query().Get("fuzzy"); fuzzyStr != "" {
+		fuzzy, err := strconv.ParseBool(fuzzyStr)
+		if err != nil {
+			http.Error(w, "invalid fuzzy parameter", http.StatusBadRequest)
+			return
+		}
+		req.Fuzzy = fuzzy
+	}
+	if thresholdStr := r.URL.Query().Get("fuzzy_threshold"); thresholdStr != "" {
+		threshold, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			http.Error(w, "invalid fuzzy_threshold parameter", http.StatusBadRequest)
+			return
+		}
+		req.FuzzyThreshold = threshold
+	}
+
+	if aliasesStr := r.URL.Query().Get("include_aliases"); aliasesStr != "" {
+		includeAliases, err := strconv.ParseBool(aliasesStr)
+		if err != nil {
+			http.Error(w, "invalid include_aliases parameter", http.StatusBadRequest)
+			return
+		}
+		req.IncludeAliases = includeAliases
+	}
+
+	req.CountryCodes = parseListParam(r, "country")
+	var ok bool
+	if req.MinPopulation, ok = parseIntParam(w, r, "min_population", 0); !ok {
+		return
+	}
+	if req.MaxPopulation, ok = parseIntParam(w, r, "max_population", 0); !ok {
+		return
+	}
+	req.Timezone = r.URL.Query().Get("timezone")
+	req.FeatureCodes = parseListParam(r, "feature_code")
+	req.FeatureRank = r.URL.Query().Get("feature_rank")
+	req.Cursor = r.URL.Query().Get("cursor")
+
 	response, err := h.service.SuggestCities(r.Context(), req)
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		log.Printf("Error suggesting cities: %v", err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Error encoding response: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
-		return
-	}
+	writeJSON(w, response)
 }
 
 // FindNearestCity handles GET /api/v1/nearest
 func (h *Handler) FindNearestCity(w http.ResponseWriter, r *http.Request) {
-	latStr := r.URL.Query().Get("lat")
-	lonStr := r.URL.Query().Get("lon")
+	lat, lon, ok := parseCoordinates(w, r)
+	if !ok {
+		return
+	}
 
-	if latStr == "" || lonStr == "" {
-		http.Error(w, "parameters 'lat' and 'lon' are required", http.StatusBadRequest)
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	// count, max_km and the feature filters switch the endpoint to the
+	// k-nearest list response
+	countStr := r.URL.Query().Get("count")
+	maxKmStr := r.URL.Query().Get("max_km")
+	featureCodes := parseListParam(r, "feature_code")
+	featureRank := r.URL.Query().Get("feature_rank")
+	if countStr != "" || maxKmStr != "" || len(featureCodes) > 0 || featureRank != "" {
+		req := model.NearestRequest{
+			Lat: lat, Lon: lon, Count: 1, Lang: lang,
+			FeatureCodes: featureCodes, FeatureRank: featureRank,
+		}
+		if countStr != "" {
+			count, err := strconv.Atoi(countStr)
+			if err != nil || count <= 0 {
+				http.Error(w, "invalid count parameter", http.StatusBadRequest)
+				return
+			}
+			req.Count = count
+		}
+		if maxKmStr != "" {
+			maxKm, err := strconv.ParseFloat(maxKmStr, 64)
+			if err != nil || maxKm <= 0 {
+				http.Error(w, "invalid max_km parameter", http.StatusBadRequest)
+				return
+			}
+			req.MaxKm = maxKm
+		}
+
+		response, err := h.service.FindNearestCities(r.Context(), req)
+		if errors.Is(err, service.ErrInvalidArgument) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Error finding nearest cities: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, response)
 		return
 	}
 
-	lat, err := strconv.ParseFloat(latStr, 64)
+	response, err := h.service.FindNearestCity(r.Context(), lat, lon, lang)
 	if err != nil {
-		http.Error(w, "invalid lat parameter", http.StatusBadRequest)
+		log.Printf("Error finding nearest city: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	lon, err := strconv.ParseFloat(lonStr, 64)
-	if err != nil {
-		http.Error(w, "invalid lon parameter", http.StatusBadRequest)
+	if response == nil {
+		http.Error(w, "no cities found", http.StatusNotFound)
 		return
 	}
 
-	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
-		http.Error(w, "invalid coordinates range", http.StatusBadRequest)
+	writeJSON(w, response)
+}
+
+// FindCitiesWithin handles GET /api/v1/within
+func (h *Handler) FindCitiesWithin(w http.ResponseWriter, r *http.Request) {
+	lat, lon, ok := parseCoordinates(w, r)
+	if !ok {
+		return
+	}
+
+	radiusStr := r.URL.Query().Get("radius_km")
+	if radiusStr == "" {
+		http.Error(w, "parameter 'radius_km' is required", http.StatusBadRequest)
+		return
+	}
+	radius, err := strconv.ParseFloat(radiusStr, 64)
+	if err != nil || radius <= 0 {
+		http.Error(w, "invalid radius_km parameter", http.StatusBadRequest)
 		return
 	}
 
@@ -102,24 +205,87 @@ func (h *Handler) FindNearestCity(w http.ResponseWriter, r *http.Request) {
 		lang = "en"
 	}
 
-	response, err := h.service.FindNearestCity(r.Context(), lat, lon, lang)
+	limit, ok := parseIntParam(w, r, "limit", 10)
+	if !ok {
+		return
+	}
+	req := model.WithinRequest{
+		Lat:      lat,
+		Lon:      lon,
+		RadiusKm: radius,
+		Lang:     lang,
+		SortBy:   r.URL.Query().Get("sort"),
+		Limit:    limit,
+		Cursor:   r.URL.Query().Get("cursor"),
+	}
+
+	response, err := h.service.FindCitiesWithin(r.Context(), req)
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		log.Printf("Error finding nearest city: %v", err)
+		log.Printf("Error finding cities within radius: %v", err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	if response == nil {
-		http.Error(w, "no cities found", http.StatusNotFound)
+	writeJSON(w, response)
+}
+
+// FindCitiesInBBox handles GET /api/v1/bbox
+func (h *Handler) FindCitiesInBBox(w http.ResponseWriter, r *http.Request) {
+	var bounds [4]float64
+	for i, name := range []string{"min_lat", "min_lon", "max_lat", "max_lon"} {
+		str := r.URL.Query().Get(name)
+		if str == "" {
+			http.Error(w, "parameters 'min_lat', 'min_lon', 'max_lat' and 'max_lon' are required", http.StatusBadRequest)
+			return
+		}
+		value, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			http.Error(w, "invalid "+name+" parameter", http.StatusBadRequest)
+			return
+		}
+		bounds[i] = value
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	minPopulation, ok := parseIntParam(w, r, "min_population", 0)
+	if !ok {
+		return
+	}
+	limit, ok := parseIntParam(w, r, "limit", 0)
+	if !ok {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Error encoding response: %v", err)
+	req := model.BBoxRequest{
+		MinLat:        bounds[0],
+		MinLon:        bounds[1],
+		MaxLat:        bounds[2],
+		MaxLon:        bounds[3],
+		MinPopulation: minPopulation,
+		Lang:          lang,
+		Limit:         limit,
+	}
+
+	response, err := h.service.FindCitiesInBBox(r.Context(), req)
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error finding cities in bounding box: %v", err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
+
+	writeJSON(w, response)
 }
 
 // GetCity handles GET /api/v1/city/{id}
@@ -150,12 +316,319 @@ func (h *Handler) GetCity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	if err := json.NewEncoder(w).Encode(city); err != nil {
-		log.Printf("Error encoding response: %v", err)
+	writeJSON(w, city)
+}
+
+// GetCityNames handles GET /api/v1/city/{id}/names
+func (h *Handler) GetCityNames(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid city id", http.StatusBadRequest)
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	response, err := h.service.GetCityNames(r.Context(), id, lang)
+	if err != nil {
+		log.Printf("Error getting city names: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if response == nil {
+		http.Error(w, "city not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// GetCityHierarchy handles GET /api/v1/city/{id}/hierarchy
+func (h *Handler) GetCityHierarchy(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid city id", http.StatusBadRequest)
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	response, err := h.service.GetCityHierarchy(r.Context(), id, lang)
+	if err != nil {
+		log.Printf("Error getting city hierarchy: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if response == nil {
+		http.Error(w, "city not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// GetCityTime handles GET /api/v1/city/{id}/time
+func (h *Handler) GetCityTime(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid city id", http.StatusBadRequest)
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	// at is an RFC 3339 timestamp or Unix seconds; it defaults to now
+	var at time.Time
+	if atStr := r.URL.Query().Get("at"); atStr != "" {
+		at, err = parseInstant(atStr)
+		if err != nil {
+			http.Error(w, "invalid at parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	response, err := h.service.GetCityTime(r.Context(), id, at, lang)
+	if errors.Is(err, service.ErrNoTimezone) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting city time: %v", err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
+
+	if response == nil {
+		http.Error(w, "city not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// ConvertTime handles GET /api/v1/time/convert
+func (h *Handler) ConvertTime(w http.ResponseWriter, r *http.Request) {
+	from, ok := parseCityRef(w, r, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseCityRef(w, r, "to")
+	if !ok {
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	response, err := h.service.ConvertTime(r.Context(), model.TimeConversionRequest{
+		From:      from,
+		To:        to,
+		LocalTime: r.URL.Query().Get("at"),
+		Lang:      lang,
+	})
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrNoTimezone) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error converting time: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if response == nil {
+		http.Error(w, "city not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// ListCountries handles GET /api/v1/countries
+func (h *Handler) ListCountries(w http.ResponseWriter, r *http.Request) {
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	response, err := h.service.ListCountries(r.Context(), lang)
+	if err != nil {
+		log.Printf("Error listing countries: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// GetCountry handles GET /api/v1/countries/{code}
+func (h *Handler) GetCountry(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	country, err := h.service.GetCountry(r.Context(), code, lang)
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting country: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if country == nil {
+		http.Error(w, "country not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, country)
+}
+
+// GetCountryNeighbours handles GET /api/v1/countries/{code}/neighbours
+func (h *Handler) GetCountryNeighbours(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	response, err := h.service.GetCountryNeighbours(r.Context(), code, lang)
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting country neighbours: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if response == nil {
+		http.Error(w, "country not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// FindBorderPath handles GET /api/v1/countries/{code}/path/{to}
+func (h *Handler) FindBorderPath(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	response, err := h.service.FindBorderPath(r.Context(), vars["code"], vars["to"], lang)
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error finding border path: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if response == nil {
+		http.Error(w, "country not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// GetPostalCode handles GET /api/v1/postal/{country}/{code}
+func (h *Handler) GetPostalCode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	response, err := h.service.GetPostalCode(r.Context(), vars["country"], vars["code"], lang)
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting postal code: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if response == nil {
+		http.Error(w, "postal code not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, response)
+}
+
+// FindNearestPostalCodes handles GET /api/v1/postal/nearest
+func (h *Handler) FindNearestPostalCodes(w http.ResponseWriter, r *http.Request) {
+	lat, lon, ok := parseCoordinates(w, r)
+	if !ok {
+		return
+	}
+
+	var radius float64
+	if radiusStr := r.URL.Query().Get("radius_km"); radiusStr != "" {
+		var err error
+		radius, err = strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius <= 0 {
+			http.Error(w, "invalid radius_km parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit, ok := parseIntParam(w, r, "limit", 10)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.FindNearestPostalCodes(r.Context(), model.PostalNearestRequest{
+		Lat:      lat,
+		Lon:      lon,
+		RadiusKm: radius,
+		Limit:    limit,
+	})
+	if errors.Is(err, service.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error finding nearest postal codes: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, response)
 }
 
 // GetAvailableLanguages handles GET /api/v1/languages
@@ -172,11 +645,114 @@ func (h *Handler) GetAvailableLanguages(w http.ResponseWriter, r *http.Request)
 		"count":     len(languages),
 	}
 
+	writeJSON(w, response)
+}
+
+// parseCoordinates reads and validates the lat/lon query parameters.
+// On failure it writes a 400 response and returns ok = false.
+func parseCoordinates(w http.ResponseWriter, r *http.Request) (lat, lon float64, ok bool) {
+	return parseCoordinatePair(w, r, "lat", "lon")
+}
+
+// parseCoordinatePair reads and validates a pair of latitude and longitude
+// query parameters with the given names.
+// On failure it writes a 400 response and returns ok = false.
+func parseCoordinatePair(w http.ResponseWriter, r *http.Request, latName, lonName string) (lat, lon float64, ok bool) {
+	latStr := r.URL.Query().Get(latName)
+	lonStr := r.URL.Query().Get(lonName)
+
+	if latStr == "" || lonStr == "" {
+		http.Error(w, fmt.Sprintf("parameters '%s' and '%s' are required", latName, lonName), http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil {
+		http.Error(w, "invalid "+latName+" parameter", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	lon, err = strconv.ParseFloat(lonStr, 64)
+	if err != nil {
+		http.Error(w, "invalid "+lonName+" parameter", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		http.Error(w, "invalid coordinates range", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	return lat, lon, true
+}
+
+// parseCityRef reads a city given either by ID (?from=2950159) or by
+// coordinates (?from_lat=52.52&from_lon=13.40).
+// On failure it writes a 400 response and returns ok = false.
+func parseCityRef(w http.ResponseWriter, r *http.Request, name string) (model.CityRef, bool) {
+	if idStr := r.URL.Query().Get(name); idStr != "" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil || id <= 0 {
+			http.Error(w, "invalid "+name+" parameter", http.StatusBadRequest)
+			return model.CityRef{}, false
+		}
+		return model.CityRef{ID: id}, true
+	}
+
+	if r.URL.Query().Get(name+"_lat") == "" && r.URL.Query().Get(name+"_lon") == "" {
+		http.Error(w, fmt.Sprintf("parameter '%[1]s' or '%[1]s_lat' and '%[1]s_lon' is required", name), http.StatusBadRequest)
+		return model.CityRef{}, false
+	}
+	lat, lon, ok := parseCoordinatePair(w, r, name+"_lat", name+"_lon")
+	if !ok {
+		return model.CityRef{}, false
+	}
+	return model.CityRef{Coordinates: &model.Coordinate{Lat: lat, Lon: lon}}, true
+}
+
+// parseIntParam reads an optional non-negative integer query parameter.
+// On failure it writes a 400 response and returns ok = false.
+func parseIntParam(w http.ResponseWriter, r *http.Request, name string, defaultValue int) (int, bool) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return defaultValue, true
+	}
+	value, err := strconv.Atoi(str)
+	if err != nil || value < 0 {
+		http.Error(w, "invalid "+name+" parameter", http.StatusBadRequest)
+		return 0, false
+	}
+	return value, true
+}
+
+// parseListParam collects the values of a query parameter that may be
+// repeated and/or comma-separated, e.g. ?country=DE,AT&country=CH
+func parseListParam(r *http.Request, name string) []string {
+	var values []string
+	for _, raw := range r.URL.Query()[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// parseInstant parses an RFC 3339 timestamp or a number of Unix seconds
+func parseInstant(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// writeJSON encodes response as the JSON body of a 200 reply
+func writeJSON(w http.ResponseWriter, response interface{}) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Error encoding response: %v", err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
-		return
 	}
 }
 
@@ -2,11 +2,15 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/alexivanou/geocity-api/internal/service"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -33,6 +37,54 @@ func (m *MockService) GetCityByID(ctx context.Context, id int, lang string) (*mo
 	return args.Get(0).(*model.CityDetailResponse), args.Error(1)
 }
 
+func (m *MockService) GetCityTime(ctx context.Context, id int, at time.Time, lang string) (*model.CityTimeResponse, error) {
+	args := m.Called(ctx, id, at, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CityTimeResponse), args.Error(1)
+}
+
+func (m *MockService) GetCityNames(ctx context.Context, id int, lang string) (*model.CityNamesResponse, error) {
+	args := m.Called(ctx, id, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CityNamesResponse), args.Error(1)
+}
+
+func (m *MockService) GetCityHierarchy(ctx context.Context, id int, lang string) (*model.CityHierarchyResponse, error) {
+	args := m.Called(ctx, id, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CityHierarchyResponse), args.Error(1)
+}
+
+func (m *MockService) ConvertTime(ctx context.Context, req model.TimeConversionRequest) (*model.TimeConversionResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TimeConversionResponse), args.Error(1)
+}
+
+func (m *MockService) GetPostalCode(ctx context.Context, country, code string, lang string) (*model.PostalCodeResponse, error) {
+	args := m.Called(ctx, country, code, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PostalCodeResponse), args.Error(1)
+}
+
+func (m *MockService) FindNearestPostalCodes(ctx context.Context, req model.PostalNearestRequest) (*model.PostalCodeResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PostalCodeResponse), args.Error(1)
+}
+
 func (m *MockService) FindNearestCity(ctx context.Context, lat, lon float64, lang string) (*model.NearestCityResponse, error) {
 	args := m.Called(ctx, lat, lon, lang)
 	if args.Get(0) == nil {
@@ -41,6 +93,62 @@ func (m *MockService) FindNearestCity(ctx context.Context, lat, lon float64, lan
 	return args.Get(0).(*model.NearestCityResponse), args.Error(1)
 }
 
+func (m *MockService) FindNearestCities(ctx context.Context, req model.NearestRequest) (*model.NearestCitiesResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.NearestCitiesResponse), args.Error(1)
+}
+
+func (m *MockService) FindCitiesWithin(ctx context.Context, req model.WithinRequest) (*model.WithinResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WithinResponse), args.Error(1)
+}
+
+func (m *MockService) FindCitiesInBBox(ctx context.Context, req model.BBoxRequest) (*model.BBoxResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BBoxResponse), args.Error(1)
+}
+
+func (m *MockService) ListCountries(ctx context.Context, lang string) (*model.CountriesResponse, error) {
+	args := m.Called(ctx, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CountriesResponse), args.Error(1)
+}
+
+func (m *MockService) GetCountry(ctx context.Context, code string, lang string) (*model.CountryResponse, error) {
+	args := m.Called(ctx, code, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CountryResponse), args.Error(1)
+}
+
+func (m *MockService) GetCountryNeighbours(ctx context.Context, code string, lang string) (*model.CountriesResponse, error) {
+	args := m.Called(ctx, code, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CountriesResponse), args.Error(1)
+}
+
+func (m *MockService) FindBorderPath(ctx context.Context, from, to string, lang string) (*model.BorderPathResponse, error) {
+	args := m.Called(ctx, from, to, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BorderPathResponse), args.Error(1)
+}
+
 func (m *MockService) GetAvailableLanguages(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -55,6 +163,9 @@ func TestHandler_SuggestCities(t *testing.T) {
 		query          string
 		lang           string
 		limit          string
+		fuzzy          string
+		thresh         string
+		filters        url.Values
 		mockSetup      func(*MockService)
 		expectedStatus int
 		expectedBody   bool
@@ -76,6 +187,98 @@ func TestHandler_SuggestCities(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody:   true,
 		},
+		{
+			name:   "fuzzy request",
+			query:  "Berlln",
+			fuzzy:  "true",
+			thresh: "0.4",
+			mockSetup: func(ms *MockService) {
+				ms.On("SuggestCities", mock.Anything, model.SuggestRequest{
+					Query: "Berlln", Lang: "en", Limit: 10, Fuzzy: true, FuzzyThreshold: 0.4,
+				}).Return(&model.SuggestResponse{
+					Results: []model.CityResult{{ID: 2950159, Name: "Berlin"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "filters",
+			query: "Fr",
+			filters: url.Values{
+				"country":        {"de,AT", "CH"},
+				"min_population": {"100000"},
+				"max_population": {"2000000"},
+				"timezone":       {"Europe/Berlin"},
+				"feature_code":   {"PPLC,PPLA"},
+			},
+			mockSetup: func(ms *MockService) {
+				ms.On("SuggestCities", mock.Anything, model.SuggestRequest{
+					Query: "Fr", Lang: "en", Limit: 10,
+					CountryCodes:  []string{"de", "AT", "CH"},
+					MinPopulation: 100000, MaxPopulation: 2000000,
+					Timezone:     "Europe/Berlin",
+					FeatureCodes: []string{"PPLC", "PPLA"},
+				}).Return(&model.SuggestResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "cursor passed through",
+			query:   "Par",
+			filters: url.Values{"cursor": {"eyJzIjozMDAwLCJwIjoxLCJpIjoyfQ"}},
+			mockSetup: func(ms *MockService) {
+				ms.On("SuggestCities", mock.Anything, mock.MatchedBy(func(req model.SuggestRequest) bool {
+					return req.Cursor == "eyJzIjozMDAwLCJwIjoxLCJpIjoyfQ"
+				})).Return(&model.SuggestResponse{NextCursor: "next"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "aliases",
+			query:   "Bombay",
+			filters: url.Values{"include_aliases": {"true"}},
+			mockSetup: func(ms *MockService) {
+				ms.On("SuggestCities", mock.Anything, model.SuggestRequest{
+					Query: "Bombay", Lang: "en", Limit: 10, IncludeAliases: true,
+				}).Return(&model.SuggestResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid include_aliases",
+			query:          "Bombay",
+			filters:        url.Values{"include_aliases": {"sometimes"}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid min_population",
+			query:          "Fr",
+			filters:        url.Values{"min_population": {"-5"}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "invalid country rejected by service",
+			query:   "Fr",
+			filters: url.Values{"country": {"DEU"}},
+			mockSetup: func(ms *MockService) {
+				ms.On("SuggestCities", mock.Anything, mock.Anything).
+					Return(nil, fmt.Errorf("%w: invalid country code", service.ErrInvalidArgument))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid fuzzy flag",
+			query:          "Berlln",
+			fuzzy:          "maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid fuzzy threshold",
+			query:          "Berlln",
+			fuzzy:          "true",
+			thresh:         "2",
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:           "missing query parameter",
 			query:          "",
@@ -108,6 +311,15 @@ func TestHandler_SuggestCities(t *testing.T) {
 			if tt.limit != "" {
 				q.Add("limit", tt.limit)
 			}
+			if tt.fuzzy != "" {
+				q.Add("fuzzy", tt.fuzzy)
+			}
+			if tt.thresh != "" {
+				q.Add("fuzzy_threshold", tt.thresh)
+			}
+			for key, values := range tt.filters {
+				q[key] = values
+			}
 			req.URL.RawQuery = q.Encode()
 
 			rr := httptest.NewRecorder()
@@ -123,6 +335,9 @@ func TestHandler_FindNearestCity(t *testing.T) {
 		name           string
 		lat            string
 		lon            string
+		count          string
+		maxKm          string
+		featureRank    string
 		mockSetup      func(*MockService)
 		expectedStatus int
 	}{
@@ -138,6 +353,54 @@ func TestHandler_FindNearestCity(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:  "k-nearest request",
+			lat:   "52.52",
+			lon:   "13.40",
+			count: "5",
+			maxKm: "50",
+			mockSetup: func(ms *MockService) {
+				ms.On("FindNearestCities", mock.Anything, model.NearestRequest{
+					Lat: 52.52, Lon: 13.40, Count: 5, MaxKm: 50, Lang: "en",
+				}).Return(&model.NearestCitiesResponse{
+					Results: []model.NearestCityResponse{
+						{City: model.CityDetailResponse{Name: "Berlin"}, DistanceKm: 0.5},
+						{City: model.CityDetailResponse{Name: "Potsdam"}, DistanceKm: 26.1},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "nearest capital",
+			lat:         "52.52",
+			lon:         "13.40",
+			featureRank: "PPLC",
+			mockSetup: func(ms *MockService) {
+				ms.On("FindNearestCities", mock.Anything, model.NearestRequest{
+					Lat: 52.52, Lon: 13.40, Count: 1, Lang: "en", FeatureRank: "PPLC",
+				}).Return(&model.NearestCitiesResponse{
+					Results: []model.NearestCityResponse{
+						{City: model.CityDetailResponse{Name: "Berlin", FeatureCode: "PPLC"}, DistanceKm: 0.5},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid count",
+			lat:            "52.52",
+			lon:            "13.40",
+			count:          "0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid max_km",
+			lat:            "52.52",
+			lon:            "13.40",
+			maxKm:          "far",
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -155,6 +418,15 @@ func TestHandler_FindNearestCity(t *testing.T) {
 			if tt.lon != "" {
 				q.Add("lon", tt.lon)
 			}
+			if tt.count != "" {
+				q.Add("count", tt.count)
+			}
+			if tt.maxKm != "" {
+				q.Add("max_km", tt.maxKm)
+			}
+			if tt.featureRank != "" {
+				q.Add("feature_rank", tt.featureRank)
+			}
 			req.URL.RawQuery = q.Encode()
 			rr := httptest.NewRecorder()
 			handler.FindNearestCity(rr, req)
@@ -213,3 +485,507 @@ func TestHandler_GetCity(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_FindCitiesInBBox(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name:  "viewport crossing the antimeridian",
+			query: "min_lat=-25&min_lon=175&max_lat=-15&max_lon=-170&min_population=1000&limit=20",
+			mockSetup: func(ms *MockService) {
+				ms.On("FindCitiesInBBox", mock.Anything, model.BBoxRequest{
+					MinLat: -25, MinLon: 175, MaxLat: -15, MaxLon: -170, MinPopulation: 1000, Lang: "en", Limit: 20,
+				}).Return(&model.BBoxResponse{Results: []model.CityResult{{ID: 10, Name: "Suva"}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing bound",
+			query:          "min_lat=-25&min_lon=175&max_lat=-15",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid bound",
+			query:          "min_lat=-25&min_lon=east&max_lat=-15&max_lon=-170",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := &Handler{service: mockService}
+			req, _ := http.NewRequest("GET", "/api/v1/bbox?"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			handler.FindCitiesInBBox(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_GetCountry(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name: "successful request",
+			code: "at",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCountry", mock.Anything, "at", "de").Return(&model.CountryResponse{
+					Code: "AT", Name: "Österreich", Languages: []string{"de-AT"}, Neighbours: []string{"DE"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "unknown country",
+			code: "ZZ",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCountry", mock.Anything, "ZZ", "de").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "invalid code",
+			code: "AUT",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCountry", mock.Anything, "AUT", "de").Return(nil, fmt.Errorf("%w: invalid country code", service.ErrInvalidArgument))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockSetup(mockService)
+			handler := &Handler{service: mockService}
+
+			req, _ := http.NewRequest("GET", "/api/v1/countries/"+tt.code+"?lang=de", nil)
+			req = mux.SetURLVars(req, map[string]string{"code": tt.code})
+			rr := httptest.NewRecorder()
+			handler.GetCountry(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_ListCountries(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("ListCountries", mock.Anything, "en").Return(&model.CountriesResponse{
+		Results: []model.CountryResponse{{Code: "AT", Name: "Austria"}},
+	}, nil)
+	handler := &Handler{service: mockService}
+
+	req, _ := http.NewRequest("GET", "/api/v1/countries", nil)
+	rr := httptest.NewRecorder()
+	handler.ListCountries(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"AT"`)
+}
+
+func TestHandler_FindBorderPath(t *testing.T) {
+	tests := []struct {
+		name           string
+		from, to       string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name: "route found",
+			from: "de", to: "es",
+			mockSetup: func(ms *MockService) {
+				ms.On("FindBorderPath", mock.Anything, "de", "es", "en").Return(&model.BorderPathResponse{
+					From: "DE", To: "ES", Reachable: true, Borders: 2,
+					Path: []model.CountryResponse{{Code: "DE"}, {Code: "FR"}, {Code: "ES"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "unknown country",
+			from: "DE", to: "ZZ",
+			mockSetup: func(ms *MockService) {
+				ms.On("FindBorderPath", mock.Anything, "DE", "ZZ", "en").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "invalid code",
+			from: "DEU", to: "ES",
+			mockSetup: func(ms *MockService) {
+				ms.On("FindBorderPath", mock.Anything, "DEU", "ES", "en").Return(nil, fmt.Errorf("%w: invalid country code", service.ErrInvalidArgument))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockSetup(mockService)
+			handler := &Handler{service: mockService}
+
+			req, _ := http.NewRequest("GET", "/api/v1/countries/"+tt.from+"/path/"+tt.to, nil)
+			req = mux.SetURLVars(req, map[string]string{"code": tt.from, "to": tt.to})
+			rr := httptest.NewRecorder()
+			handler.FindBorderPath(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_GetCountryNeighbours(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("GetCountryNeighbours", mock.Anything, "AT", "en").Return(&model.CountriesResponse{
+		Results: []model.CountryResponse{{Code: "CH"}, {Code: "DE"}},
+	}, nil)
+	handler := &Handler{service: mockService}
+
+	req, _ := http.NewRequest("GET", "/api/v1/countries/AT/neighbours", nil)
+	req = mux.SetURLVars(req, map[string]string{"code": "AT"})
+	rr := httptest.NewRecorder()
+	handler.GetCountryNeighbours(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"CH"`)
+}
+
+func TestHandler_GetCityTime(t *testing.T) {
+	at := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		cityID         string
+		at             string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name:   "RFC 3339 instant",
+			cityID: "2950159",
+			at:     "2024-07-01T14:00:00+02:00",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCityTime", mock.Anything, 2950159, mock.MatchedBy(at.Equal), "en").Return(&model.CityTimeResponse{
+					ID: 2950159, Name: "Berlin", TimeInfo: model.TimeInfo{LocalTime: "2024-07-01T14:00:00+02:00"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Unix seconds",
+			cityID: "2950159",
+			at:     "1719835200",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCityTime", mock.Anything, 2950159, mock.MatchedBy(at.Equal), "en").Return(&model.CityTimeResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "defaults to now",
+			cityID: "2950159",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCityTime", mock.Anything, 2950159, time.Time{}, "en").Return(&model.CityTimeResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid instant",
+			cityID:         "2950159",
+			at:             "yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "city without timezone",
+			cityID: "7",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCityTime", mock.Anything, 7, time.Time{}, "en").Return(nil, service.ErrNoTimezone)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "unknown city",
+			cityID: "8",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCityTime", mock.Anything, 8, time.Time{}, "en").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := &Handler{service: mockService}
+
+			req, _ := http.NewRequest("GET", "/api/v1/city/"+tt.cityID+"/time", nil)
+			if tt.at != "" {
+				q := req.URL.Query()
+				q.Add("at", tt.at)
+				req.URL.RawQuery = q.Encode()
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tt.cityID})
+			rr := httptest.NewRecorder()
+			handler.GetCityTime(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_ConvertTime(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name:  "by ids",
+			query: "from=2950159&to=5128581&at=2024-03-20T09:00",
+			mockSetup: func(ms *MockService) {
+				ms.On("ConvertTime", mock.Anything, model.TimeConversionRequest{
+					From: model.CityRef{ID: 2950159}, To: model.CityRef{ID: 5128581}, LocalTime: "2024-03-20T09:00", Lang: "en",
+				}).Return(&model.TimeConversionResponse{OffsetDifference: "-05:00"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "by coordinates",
+			query: "from=2950159&to_lat=40.71&to_lon=-74.01",
+			mockSetup: func(ms *MockService) {
+				ms.On("ConvertTime", mock.Anything, model.TimeConversionRequest{
+					From: model.CityRef{ID: 2950159}, To: model.CityRef{Coordinates: &model.Coordinate{Lat: 40.71, Lon: -74.01}}, Lang: "en",
+				}).Return(&model.TimeConversionResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing target",
+			query:          "from=2950159",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "half of a coordinate pair",
+			query:          "from=2950159&to_lat=40.71",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "coordinates out of range",
+			query:          "from_lat=100&from_lon=0&to=1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid id",
+			query:          "from=abc&to=1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid local time",
+			query: "from=1&to=2&at=noon",
+			mockSetup: func(ms *MockService) {
+				ms.On("ConvertTime", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: bad at", service.ErrInvalidArgument))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "city without timezone",
+			query: "from=1&to=2",
+			mockSetup: func(ms *MockService) {
+				ms.On("ConvertTime", mock.Anything, mock.Anything).Return(nil, service.ErrNoTimezone)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:  "unknown city",
+			query: "from=1&to=2",
+			mockSetup: func(ms *MockService) {
+				ms.On("ConvertTime", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := &Handler{service: mockService}
+
+			req, _ := http.NewRequest("GET", "/api/v1/time/convert?"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			handler.ConvertTime(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetPostalCode(t *testing.T) {
+	tests := []struct {
+		name           string
+		country        string
+		code           string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name:    "successful request",
+			country: "DE",
+			code:    "10115",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetPostalCode", mock.Anything, "DE", "10115", "en").Return(&model.PostalCodeResponse{
+					Results: []model.PostalCodeResult{{CountryCode: "DE", PostalCode: "10115", PlaceName: "Berlin"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "unknown code",
+			country: "DE",
+			code:    "00000",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetPostalCode", mock.Anything, "DE", "00000", "en").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:    "invalid country",
+			country: "DEU",
+			code:    "10115",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetPostalCode", mock.Anything, "DEU", "10115", "en").Return(nil, fmt.Errorf("%w: invalid country code", service.ErrInvalidArgument))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockSetup(mockService)
+			handler := &Handler{service: mockService}
+
+			req, _ := http.NewRequest("GET", "/api/v1/postal/"+tt.country+"/"+tt.code, nil)
+			req = mux.SetURLVars(req, map[string]string{"country": tt.country, "code": tt.code})
+			rr := httptest.NewRecorder()
+			handler.GetPostalCode(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_FindNearestPostalCodes(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("FindNearestPostalCodes", mock.Anything, model.PostalNearestRequest{Lat: 52.52, Lon: 13.4, RadiusKm: 5, Limit: 3}).
+		Return(&model.PostalCodeResponse{Results: []model.PostalCodeResult{{PostalCode: "10178"}}}, nil)
+	mockService.On("FindNearestPostalCodes", mock.Anything, model.PostalNearestRequest{Lat: 52.52, Lon: 13.4, Limit: 10}).
+		Return(&model.PostalCodeResponse{Results: []model.PostalCodeResult{}}, nil)
+	handler := &Handler{service: mockService}
+
+	for query, expected := range map[string]int{
+		"lat=52.52&lon=13.4&radius_km=5&limit=3": http.StatusOK,
+		"lat=52.52&lon=13.4":                     http.StatusOK,
+		"lat=52.52&lon=13.4&radius_km=-1":        http.StatusBadRequest,
+		"lat=52.52":                              http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", "/api/v1/postal/nearest?"+query, nil)
+		rr := httptest.NewRecorder()
+		handler.FindNearestPostalCodes(rr, req)
+		assert.Equal(t, expected, rr.Code, query)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCityHierarchy(t *testing.T) {
+	tests := []struct {
+		name           string
+		cityID         string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name:   "successful request",
+			cityID: "2950159",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCityHierarchy", mock.Anything, 2950159, "de").Return(&model.CityHierarchyResponse{
+					ID: 2950159, Name: "Berlin", Hierarchy: []model.PlaceResponse{
+						{GeonameID: 6255148, Type: model.PlaceContinent, Code: "EU", Name: "Europa"},
+						{GeonameID: 2950159, Type: model.PlaceCity, Name: "Berlin"},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "unknown city",
+			cityID: "1",
+			mockSetup: func(ms *MockService) {
+				ms.On("GetCityHierarchy", mock.Anything, 1, "de").Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			cityID:         "berlin",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := &Handler{service: mockService}
+
+			req, _ := http.NewRequest("GET", "/api/v1/city/"+tt.cityID+"/hierarchy?lang=de", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.cityID})
+			rr := httptest.NewRecorder()
+			handler.GetCityHierarchy(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_GetCityNames(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("GetCityNames", mock.Anything, 2950159, "en").Return(&model.CityNamesResponse{
+		ID: 2950159, Name: "Berlin", Names: []model.AlternateNameResponse{
+			{Name: "Berlin", Lang: "de", IsPreferred: true},
+			{Name: "Spree-Athen", Lang: "de", IsColloquial: true},
+		},
+	}, nil)
+	mockService.On("GetCityNames", mock.Anything, 1, "en").Return(nil, nil)
+	handler := &Handler{service: mockService}
+
+	for cityID, expected := range map[string]int{
+		"2950159": http.StatusOK,
+		"1":       http.StatusNotFound,
+		"berlin":  http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", "/api/v1/city/"+cityID+"/names", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": cityID})
+		rr := httptest.NewRecorder()
+		handler.GetCityNames(rr, req)
+		assert.Equal(t, expected, rr.Code, cityID)
+
+		if expected == http.StatusOK {
+			assert.Contains(t, rr.Body.String(), `"name":"Spree-Athen","lang":"de","is_preferred":false,"is_short":false,"is_colloquial":true`)
+		}
+	}
+	mockService.AssertExpectations(t)
+}
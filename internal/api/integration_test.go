@@ -57,7 +57,7 @@ func setupIntegrationStack(t *testing.T) *http.Handler {
 	require.NoError(t, err)
 
 	repos := repository.NewRepositories(db, config.DBTypeMemory)
-	svc := service.NewService(repos.City, repos.Country, repos.Translation)
+	svc := service.NewService(repos.City, repos.Country, repos.Translation, repos.PostalCode, repos.Update)
 	statsCollector := stats.NewCollector(db, cfg)
 
 	router := NewRouter(svc, statsCollector)
@@ -94,3 +94,28 @@ func TestAPI_Integration_Nearest(t *testing.T) {
 
 	assert.Equal(t, "Dublin", resp.City.Name)
 }
+
+func TestAPI_Integration_Within(t *testing.T) {
+	handler := *setupIntegrationStack(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/within?lat=53.30&lon=-6.20&radius_km=20&lang=ga", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp model.WithinResponse
+	err := json.Unmarshal(rr.Body.Bytes(), &resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "Baile Átha Cliath", resp.Results[0].Name)
+	require.NotNil(t, resp.Results[0].DistanceKm)
+	assert.Less(t, *resp.Results[0].DistanceKm, 20.0)
+	assert.Empty(t, resp.NextCursor)
+
+	req = httptest.NewRequest("GET", "/api/v1/within?lat=53.30&lon=-6.20&radius_km=2", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Empty(t, resp.Results)
+}
@@ -20,7 +20,19 @@ func NewRouter(service service.ServiceInterface, statsCollector *stats.Collector
 	v1 := router.PathPrefix("/api/v1").Subrouter()
 	v1.HandleFunc("/suggest", handler.SuggestCities).Methods("GET")
 	v1.HandleFunc("/nearest", handler.FindNearestCity).Methods("GET")
+	v1.HandleFunc("/within", handler.FindCitiesWithin).Methods("GET")
+	v1.HandleFunc("/bbox", handler.FindCitiesInBBox).Methods("GET")
 	v1.HandleFunc("/city/{id}", handler.GetCity).Methods("GET")
+	v1.HandleFunc("/city/{id}/time", handler.GetCityTime).Methods("GET")
+	v1.HandleFunc("/city/{id}/hierarchy", handler.GetCityHierarchy).Methods("GET")
+	v1.HandleFunc("/city/{id}/names", handler.GetCityNames).Methods("GET")
+	v1.HandleFunc("/time/convert", handler.ConvertTime).Methods("GET")
+	v1.HandleFunc("/countries", handler.ListCountries).Methods("GET")
+	v1.HandleFunc("/countries/{code}", handler.GetCountry).Methods("GET")
+	v1.HandleFunc("/countries/{code}/neighbours", handler.GetCountryNeighbours).Methods("GET")
+	v1.HandleFunc("/countries/{code}/path/{to}", handler.FindBorderPath).Methods("GET")
+	v1.HandleFunc("/postal/nearest", handler.FindNearestPostalCodes).Methods("GET")
+	v1.HandleFunc("/postal/{country}/{code}", handler.GetPostalCode).Methods("GET")
 	v1.HandleFunc("/languages", handler.GetAvailableLanguages).Methods("GET")
 	v1.HandleFunc("/stats", statsHandler.GetStats).Methods("GET")
 
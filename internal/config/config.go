@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -33,13 +35,24 @@ type DBConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+	// SearchPath, if set, is the Postgres schema search path of the connection
+	SearchPath string
 }
 
+// StagingSchema is the Postgres schema a reload is seeded into before it is
+// swapped in for the live tables
+const StagingSchema = "geocity_staging"
+
 // SeederConfig holds settings for data import
 type SeederConfig struct {
 	BatchSize        int
 	MinPopulation    int
 	AllowedLanguages []string
+	// Workers is the number of goroutines parsing each data file
+	Workers int
+	// DumpDate (YYYY-MM-DD) overrides the date the data files are current
+	// as of; it is set by the seeder's -dump-date flag
+	DumpDate string
 }
 
 // DSN returns the database connection string
@@ -52,10 +65,31 @@ func (c DBConfig) DSN() string {
 		return "file::memory:?cache=shared"
 	}
 	// PostgreSQL connection string
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
 		c.User, c.Password, c.Host, c.Port, c.Name, c.SSLMode,
 	)
+	if c.SearchPath != "" {
+		dsn += "&search_path=" + url.QueryEscape(c.SearchPath)
+	}
+	return dsn
+}
+
+// Staging returns the configuration of the database a reload is seeded
+// into: on Postgres the staging schema, which falls back to public for
+// extension functions, and in memory a separate database
+func (c DBConfig) Staging() DBConfig {
+	staging := c
+	if c.Type == DBTypeMemory {
+		name := c.Name
+		if name == "" {
+			name = "geocity"
+		}
+		staging.Name = name + "_staging"
+		return staging
+	}
+	staging.SearchPath = StagingSchema + ",public"
+	return staging
 }
 
 // IsMemory returns true if using in-memory database
@@ -92,6 +126,7 @@ func Load() (*Config, error) {
 		},
 		Seeder: SeederConfig{
 			BatchSize:        getEnvAsInt("SEEDER_BATCH_SIZE", 10000),
+			Workers:          getEnvAsInt("SEEDER_WORKERS", runtime.NumCPU()),
 			MinPopulation:    getEnvAsInt("SEEDER_MIN_POPULATION", 10000),
 			AllowedLanguages: getEnvAsSlice("SEEDER_ALLOWED_LANGUAGES"),
 		},
@@ -7,7 +7,6 @@ import (
 	"github.com/alexivanou/geocity-api/internal/config"
 	_ "github.com/jackc/pgx/v5/stdlib" // Postgres driver for database/sql
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
 )
 
 // Connect creates a database connection based on configuration using sqlx
@@ -16,7 +15,7 @@ func Connect(ctx context.Context, cfg config.DBConfig) (*sqlx.DB, error) {
 	var dsn string
 
 	if cfg.IsMemory() {
-		driverName = "sqlite3"
+		driverName = sqliteDriverName
 		dsn = cfg.DSN()
 	} else {
 		driverName = "pgx"
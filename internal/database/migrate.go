@@ -0,0 +1,45 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alexivanou/geocity-api/internal/config"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
+)
+
+// Migrate applies the migrations in migrationsDir ("migrations" when run
+// from the repository root) for the configured backend. db must be connected
+// with cfg.
+func Migrate(db *sqlx.DB, cfg config.DBConfig, migrationsDir string) error {
+	var m *migrate.Migrate
+	var err error
+
+	if cfg.IsMemory() {
+		// Use driver instance directly to avoid DSN parsing issues with in-memory SQLite
+		driver, err := sqlite3.WithInstance(db.DB, &sqlite3.Config{})
+		if err != nil {
+			return fmt.Errorf("could not create sqlite driver: %w", err)
+		}
+		m, err = migrate.NewWithDatabaseInstance("file://"+migrationsDir+"/sqlite", "sqlite3", driver)
+		if err != nil {
+			return fmt.Errorf("could not create migrate instance: %w", err)
+		}
+	} else {
+		// For Postgres, standard connection string works fine; a search path
+		// in it selects the schema the tables are created in
+		m, err = migrate.New("file://"+migrationsDir+"/postgres", cfg.DSN())
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
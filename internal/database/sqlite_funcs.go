@@ -0,0 +1,130 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// sqliteDriverName is the sqlite3 driver extended with the Go functions below.
+// SQLite lacks several functions the Postgres queries rely on, so they are
+// provided here under the same names to keep the SQL of both backends aligned.
+const sqliteDriverName = "sqlite3_geocity"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			funcs := map[string]interface{}{
+				"similarity": similarity,
+				// Overrides the built-in, which only folds ASCII letters
+				"lower":    textFunc(lower),
+				"unaccent": textFunc(unaccent),
+			}
+			for name, impl := range funcs {
+				if err := conn.RegisterFunc(name, impl, true); err != nil {
+					return fmt.Errorf("failed to register %s: %w", name, err)
+				}
+			}
+			return nil
+		},
+	})
+	sqlx.BindDriver(sqliteDriverName, sqlx.QUESTION)
+}
+
+// textFunc adapts a string function to SQL semantics: NULL in, NULL out, and
+// non-text values are converted to text first, like the built-in lower() does
+func textFunc(f func(string) string) func(interface{}) interface{} {
+	return func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case string:
+			return f(v)
+		case []byte:
+			if v == nil {
+				return nil
+			}
+			return f(string(v))
+		default:
+			return f(fmt.Sprint(v))
+		}
+	}
+}
+
+// lower lowercases s using the full Unicode case mappings, as LOWER() does in
+// Postgres on a UTF-8 database
+func lower(s string) string {
+	// Casers are stateful and cannot be shared between connections
+	return cases.Lower(language.Und).String(s)
+}
+
+// unaccentLetters spells out letters that have no Unicode decomposition, the
+// same way Postgres' unaccent.rules does
+var unaccentLetters = strings.NewReplacer(
+	"ß", "ss", "ẞ", "SS",
+	"æ", "ae", "Æ", "AE",
+	"œ", "oe", "Œ", "OE",
+	"ø", "o", "Ø", "O",
+	"ł", "l", "Ł", "L",
+	"đ", "d", "Đ", "D",
+	"ð", "d", "Ð", "D",
+	"þ", "th", "Þ", "TH",
+	"ħ", "h", "Ħ", "H",
+	"ŧ", "t", "Ŧ", "T",
+	"ı", "i",
+)
+
+// unaccent strips diacritics from s ("Zürich" becomes "Zurich"), standing in
+// for the Postgres unaccent extension
+func unaccent(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(t, s)
+	if err != nil {
+		stripped = s
+	}
+	return unaccentLetters.Replace(stripped)
+}
+
+// similarity returns how alike two strings are, from 0 (nothing in common)
+// to 1 (identical), based on their Levenshtein edit distance. It stands in for
+// pg_trgm's similarity(), which SQLite does not have.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the number of single-rune edits turning a into b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"berlin", "berlin", 1},
+		{"berlin", "berlln", 1 - 1.0/6},
+		{"münchen", "munchen", 1 - 1.0/7},
+		{"", "", 1},
+		{"abc", "", 0},
+		{"abc", "xyz", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, similarity(tt.a, tt.b), 1e-9)
+		})
+	}
+}
+
+func TestLower(t *testing.T) {
+	assert.Equal(t, "zürich", lower("ZÜRICH"))
+	assert.Equal(t, "москва", lower("МОСКВА"))
+	assert.Equal(t, "łódź", lower("ŁÓDŹ"))
+}
+
+func TestUnaccent(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{"Zürich", "Zurich"},
+		{"São Paulo", "Sao Paulo"},
+		{"Kraków", "Krakow"},
+		{"Łódź", "Lodz"},
+		{"Straße", "Strasse"},
+		{"Tromsø", "Tromso"},
+		{"Reykjavík", "Reykjavik"},
+		{"Đà Nẵng", "Da Nang"},
+		{"Москва", "Москва"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.expected, unaccent(tt.in))
+		})
+	}
+}
+
+func TestConnect_SQLiteFunctions(t *testing.T) {
+	db, err := Connect(context.Background(), config.DBConfig{Type: config.DBTypeMemory})
+	require.NoError(t, err)
+	defer db.Close()
+
+	var score float64
+	err = db.Get(&score, "SELECT similarity(?, ?)", "berlin", "berlln")
+	require.NoError(t, err)
+	assert.InDelta(t, 1-1.0/6, score, 1e-9)
+}
+
+func TestConnect_SQLiteTextFunctions(t *testing.T) {
+	db, err := Connect(context.Background(), config.DBConfig{Type: config.DBTypeMemory})
+	require.NoError(t, err)
+	defer db.Close()
+
+	var folded string
+	err = db.Get(&folded, "SELECT unaccent(LOWER(?))", "ZÜRICH")
+	require.NoError(t, err)
+	assert.Equal(t, "zurich", folded)
+
+	var number string
+	err = db.Get(&number, "SELECT LOWER(42)")
+	require.NoError(t, err)
+	assert.Equal(t, "42", number)
+
+	var null *string
+	err = db.Get(&null, "SELECT unaccent(LOWER(NULL))")
+	require.NoError(t, err)
+	assert.Nil(t, null)
+}
@@ -5,20 +5,58 @@ type SuggestRequest struct {
 	Query string
 	Lang  string
 	Limit int
+	// Fuzzy also matches names within FuzzyThreshold similarity (0..1) of the query
+	Fuzzy          bool
+	FuzzyThreshold float64
+	// Optional filters; zero values disable them
+	CountryCodes  []string
+	MinPopulation int
+	MaxPopulation int
+	Timezone      string
+	// FeatureCodes keeps only cities with one of the given GeoNames feature
+	// codes. FeatureRank ("PPLA") is resolved by the service into the codes of
+	// that rank or higher.
+	FeatureCodes []string
+	FeatureRank  string
+	// Cursor is the opaque next_cursor of the previous page; the service
+	// decodes it into After, which is what repositories page from
+	Cursor string
+	After  *SuggestCursor
+	// IncludeAliases also matches historic and colloquial names ("Bombay")
+	IncludeAliases bool
+}
+
+// SuggestCursor is the ranking position of the last result of a suggest page.
+// Results are ordered by score DESC, population DESC, id ASC.
+type SuggestCursor struct {
+	Score      int `json:"s"`
+	Population int `json:"p"`
+	ID         int `json:"i"`
 }
 
 // SuggestResponse represents the response for city search
 type SuggestResponse struct {
-	Results []CityResult `json:"results"`
+	Results    []CityResult `json:"results"`
+	NextCursor string       `json:"next_cursor,omitempty"`
 }
 
 // CityResult represents a city in the search results
 type CityResult struct {
-	ID          int    `json:"id" db:"id"`
-	Name        string `json:"name" db:"name"`
-	Country     string `json:"country" db:"country"`
-	CountryCode string `json:"country_code" db:"country_code"`
-	Population  int    `json:"population" db:"population"`
+	ID          int         `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Country     string      `json:"country" db:"country"`
+	CountryCode string      `json:"country_code" db:"country_code"`
+	Region      string      `json:"region,omitempty" db:"region"`
+	Subregion   string      `json:"subregion,omitempty" db:"subregion"`
+	Population  int         `json:"population" db:"population"`
+	Coordinates *Coordinate `json:"coordinates,omitempty" db:"coordinates"`
+	DistanceKm  *float64    `json:"distance_km,omitempty" db:"distance"`
+	MatchedName string      `json:"matched_name,omitempty" db:"matched_name"`
+	// MatchedAlias is set when the city matched by a historic or colloquial
+	// name; AliasHistoric then tells which of the two it is
+	MatchedAlias  string `json:"matched_alias,omitempty" db:"matched_alias"`
+	AliasHistoric *bool  `json:"alias_historic,omitempty" db:"alias_historic"`
+	Score         int    `json:"-" db:"score"`
 }
 
 // CityDetailResponse represents detailed information about a city
@@ -26,10 +64,36 @@ type CityDetailResponse struct {
 	ID          int        `json:"id"`
 	Name        string     `json:"name"`
 	Country     string     `json:"country"`
+	Region      string     `json:"region,omitempty"`
+	Subregion   string     `json:"subregion,omitempty"`
 	Coordinates Coordinate `json:"coordinates"`
 	Elevation   *int       `json:"elevation"`
 	Population  int        `json:"population"`
 	Timezone    *string    `json:"timezone"`
+	// FeatureClass and FeatureCode are the GeoNames classification, e.g. "P" and "PPLC"
+	FeatureClass string `json:"feature_class,omitempty"`
+	FeatureCode  string `json:"feature_code,omitempty"`
+	// Time is the current time in the city; nil when its timezone is unknown
+	Time *TimeInfo `json:"time,omitempty"`
+}
+
+// TimeInfo describes an instant in a city's timezone
+type TimeInfo struct {
+	Timezone         string `json:"timezone"`
+	LocalTime        string `json:"local_time"`
+	UTCOffset        string `json:"utc_offset"`
+	UTCOffsetSeconds int    `json:"utc_offset_seconds"`
+	Abbreviation     string `json:"abbreviation"`
+	DST              bool   `json:"dst"`
+}
+
+// CityTimeResponse represents an instant converted into a city's local time
+type CityTimeResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// At is the converted instant in UTC
+	At string `json:"at"`
+	TimeInfo
 }
 
 // Coordinate represents geographic coordinates
@@ -38,9 +102,200 @@ type Coordinate struct {
 	Lon float64 `json:"lon"`
 }
 
+// PlaceResponse represents one level of a city's hierarchy
+type PlaceResponse struct {
+	GeonameID int    `json:"geoname_id"`
+	Type      string `json:"type"`
+	Code      string `json:"code,omitempty"`
+	Name      string `json:"name"`
+}
+
+// CityHierarchyResponse lists a city's ancestry from the continent down to
+// the city itself
+type CityHierarchyResponse struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	Hierarchy []PlaceResponse `json:"hierarchy"`
+}
+
+// AlternateNameResponse is one name of a city with its GeoNames flags
+type AlternateNameResponse struct {
+	Name         string `json:"name"`
+	Lang         string `json:"lang,omitempty"`
+	IsPreferred  bool   `json:"is_preferred"`
+	IsShort      bool   `json:"is_short"`
+	IsColloquial bool   `json:"is_colloquial"`
+	IsHistoric   bool   `json:"is_historic"`
+}
+
+// CityNamesResponse lists every stored name of a city
+type CityNamesResponse struct {
+	ID    int                     `json:"id"`
+	Name  string                  `json:"name"`
+	Names []AlternateNameResponse `json:"names"`
+}
+
 // NearestCityResponse represents the response for nearest city search
 type NearestCityResponse struct {
 	City               CityDetailResponse `json:"city"`
 	RequestCoordinates Coordinate         `json:"request_coordinates"`
 	DistanceKm         float64            `json:"distance_km"`
 }
+
+// NearestRequest represents the request parameters for k-nearest city search
+type NearestRequest struct {
+	Lat   float64
+	Lon   float64
+	Count int
+	MaxKm float64
+	Lang  string
+	// FeatureCodes and FeatureRank filter like they do in SuggestRequest
+	FeatureCodes []string
+	FeatureRank  string
+}
+
+// NearestCitiesResponse represents the response for k-nearest city search, ordered by distance
+type NearestCitiesResponse struct {
+	Results []NearestCityResponse `json:"results"`
+}
+
+// Sort orders supported by radius search
+const (
+	SortByDistance   = "distance"
+	SortByPopulation = "population"
+)
+
+// WithinRequest represents the request parameters for radius search
+type WithinRequest struct {
+	Lat      float64
+	Lon      float64
+	RadiusKm float64
+	Lang     string
+	SortBy   string
+	Limit    int
+	// Cursor is the opaque next_cursor of the previous page; the service
+	// decodes it into After, which is what repositories page from
+	Cursor string
+	After  *WithinCursor
+}
+
+// WithinCursor is the position of the last result of a radius search page.
+// Results are ordered by distance ASC, id ASC, or by population DESC first.
+type WithinCursor struct {
+	Distance   float64 `json:"d"`
+	Population int     `json:"p"`
+	ID         int     `json:"i"`
+}
+
+// WithinResponse represents a page of cities inside a radius
+type WithinResponse struct {
+	Results    []CityResult `json:"results"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// BBoxRequest represents the request parameters for a map viewport query.
+// MinLon greater than MaxLon describes a viewport crossing the antimeridian.
+type BBoxRequest struct {
+	MinLat        float64
+	MinLon        float64
+	MaxLat        float64
+	MaxLon        float64
+	MinPopulation int
+	Lang          string
+	Limit         int
+}
+
+// BBoxResponse represents the cities inside a viewport, largest first
+type BBoxResponse struct {
+	Results []CityResult `json:"results"`
+}
+
+// CountryResponse represents a country of the catalogue
+type CountryResponse struct {
+	Code             string   `json:"code"`
+	ISO3             string   `json:"iso3"`
+	ISONumeric       string   `json:"iso_numeric"`
+	Name             string   `json:"name"`
+	Capital          string   `json:"capital,omitempty"`
+	AreaSqKm         float64  `json:"area_sq_km"`
+	Population       int64    `json:"population"`
+	Continent        string   `json:"continent"`
+	TLD              string   `json:"tld,omitempty"`
+	CurrencyCode     string   `json:"currency_code,omitempty"`
+	CurrencyName     string   `json:"currency_name,omitempty"`
+	Phone            string   `json:"phone,omitempty"`
+	PostalCodeFormat string   `json:"postal_code_format,omitempty"`
+	PostalCodeRegex  string   `json:"postal_code_regex,omitempty"`
+	Languages        []string `json:"languages"`
+	Neighbours       []string `json:"neighbours"`
+}
+
+// CountriesResponse represents the country catalogue, ordered by code
+type CountriesResponse struct {
+	Results []CountryResponse `json:"results"`
+}
+
+// BorderPathResponse represents the land route between two countries that
+// crosses the fewest borders. Path lists the countries in travel order,
+// both ends included, and is empty when no land route exists.
+type BorderPathResponse struct {
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Reachable bool              `json:"reachable"`
+	Borders   int               `json:"borders"`
+	Path      []CountryResponse `json:"path"`
+}
+
+// CityRef identifies a city by ID or, when ID is zero, as the city nearest
+// to Coordinates
+type CityRef struct {
+	ID          int
+	Coordinates *Coordinate
+}
+
+// TimeConversionRequest represents the request parameters for converting a
+// local time in one city into the local time of another
+type TimeConversionRequest struct {
+	From CityRef
+	To   CityRef
+	// LocalTime is a wall-clock time in From ("2024-03-10T09:00"); empty means now
+	LocalTime string
+	Lang      string
+}
+
+// TimeConversionResponse represents the same instant in two cities.
+// OffsetDifference is To's UTC offset minus From's at that instant.
+type TimeConversionResponse struct {
+	From                    CityTimeResponse `json:"from"`
+	To                      CityTimeResponse `json:"to"`
+	OffsetDifference        string           `json:"offset_difference"`
+	OffsetDifferenceSeconds int              `json:"offset_difference_seconds"`
+}
+
+// PostalCodeResult represents a place covered by a postal code
+type PostalCodeResult struct {
+	CountryCode string     `json:"country_code"`
+	PostalCode  string     `json:"postal_code"`
+	PlaceName   string     `json:"place_name"`
+	Region      string     `json:"region,omitempty"`
+	Subregion   string     `json:"subregion,omitempty"`
+	Coordinates Coordinate `json:"coordinates"`
+	Accuracy    *int       `json:"accuracy,omitempty"`
+	DistanceKm  *float64   `json:"distance_km,omitempty"`
+	// NearestCity is the city closest to the place (postal code lookups only)
+	NearestCity *NearestCityResponse `json:"nearest_city,omitempty"`
+}
+
+// PostalCodeResponse represents the response for postal code lookups
+type PostalCodeResponse struct {
+	Results []PostalCodeResult `json:"results"`
+}
+
+// PostalNearestRequest represents the request parameters for a reverse
+// postal code lookup
+type PostalNearestRequest struct {
+	Lat      float64
+	Lon      float64
+	RadiusKm float64
+	Limit    int
+}
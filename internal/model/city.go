@@ -10,6 +10,31 @@ type City struct {
 	Lon         float64 `db:"lon"`
 	Elevation   *int    `db:"elevation"`
 	Timezone    *string `db:"timezone"`
+	// GeoNames administrative division codes; empty when not applicable
+	Admin1Code string `db:"admin1_code"`
+	Admin2Code string `db:"admin2_code"`
+	Admin3Code string `db:"admin3_code"`
+	Admin4Code string `db:"admin4_code"`
+	// GeoNames feature class and code, e.g. "P" and "PPLC" for a capital
+	FeatureClass string `db:"feature_class"`
+	FeatureCode  string `db:"feature_code"`
+}
+
+// CityWithDistance represents a city together with its distance in km from a query point
+// and its names in the requested language
+type CityWithDistance struct {
+	City
+	Distance float64 `db:"distance"`
+	CityNames
+}
+
+// CityNames are the localized names of a city, its country and its admin1
+// (region) and admin2 (subregion) divisions
+type CityNames struct {
+	Name      string `db:"name"`
+	Country   string `db:"country"`
+	Region    string `db:"region"`
+	Subregion string `db:"subregion"`
 }
 
 // CityTranslation represents a translation of a city name
@@ -19,13 +44,48 @@ type CityTranslation struct {
 	Name   string `db:"name"`
 }
 
-// Country represents a country in the database
+// CityAlternateName is one row of alternateNames.txt for a city, with its
+// GeoNames flags. Lang is the GeoNames language code as given ("en",
+// "zh-CN"), or "" for names without a language.
+type CityAlternateName struct {
+	ID           int64  `db:"id"`
+	CityID       int    `db:"city_id"`
+	Lang         string `db:"lang"`
+	Name         string `db:"name"`
+	IsPreferred  bool   `db:"is_preferred"`
+	IsShort      bool   `db:"is_short"`
+	IsColloquial bool   `db:"is_colloquial"`
+	IsHistoric   bool   `db:"is_historic"`
+}
+
+// Country represents a country in the database, with the columns of
+// countryInfo.txt
 type Country struct {
 	Code        string `db:"code"`
 	NameDefault string `db:"name_default"`
-	// GeonameID is used during seeding to link alternate names to the country
-	// It is not stored in the countries table (which uses Code as PK)
-	GeonameID int `db:"-"`
+	// GeonameID links alternate names to the country during seeding
+	GeonameID        int     `db:"geoname_id"`
+	ISO3             string  `db:"iso3"`
+	ISONumeric       string  `db:"iso_numeric"`
+	Capital          string  `db:"capital"`
+	AreaSqKm         float64 `db:"area_sq_km"`
+	Population       int64   `db:"population"`
+	Continent        string  `db:"continent"`
+	TLD              string  `db:"tld"`
+	CurrencyCode     string  `db:"currency_code"`
+	CurrencyName     string  `db:"currency_name"`
+	Phone            string  `db:"phone"`
+	PostalCodeFormat string  `db:"postal_code_format"`
+	PostalCodeRegex  string  `db:"postal_code_regex"`
+	// Languages ("de-AT,hr,hu") and Neighbours ("CH,DE") are comma-separated
+	Languages  string `db:"languages"`
+	Neighbours string `db:"neighbours"`
+}
+
+// CountryWithName represents a country together with its localized name
+type CountryWithName struct {
+	Country
+	Name string `db:"name"`
 }
 
 // CountryTranslation represents a translation of a country name
@@ -34,3 +94,146 @@ type CountryTranslation struct {
 	Lang        string `db:"lang"`
 	Name        string `db:"name"`
 }
+
+// AdminDivision represents a first (admin1) or second (admin2) level
+// administrative division, such as a state or a county
+type AdminDivision struct {
+	// Code is the GeoNames key: "US.IL" for admin1, "US.IL.031" for admin2
+	Code        string `db:"code"`
+	CountryCode string `db:"country_code"`
+	Level       int    `db:"level"`
+	NameDefault string `db:"name_default"`
+	// GeonameID links alternate names and hierarchy.txt to the division
+	GeonameID int `db:"geoname_id"`
+}
+
+// AdminDivisionTranslation represents a translation of a division name
+type AdminDivisionTranslation struct {
+	DivisionCode string `db:"division_code"`
+	Lang         string `db:"lang"`
+	Name         string `db:"name"`
+}
+
+// Continent represents a continent, the top level of the place hierarchy
+type Continent struct {
+	Code        string `db:"code"`
+	GeonameID   int    `db:"geoname_id"`
+	NameDefault string `db:"name_default"`
+}
+
+// ContinentTranslation represents a translation of a continent name
+type ContinentTranslation struct {
+	ContinentCode string `db:"continent_code"`
+	Lang          string `db:"lang"`
+	Name          string `db:"name"`
+}
+
+// HierarchyEdge is a parent/child pair of geonameids from hierarchy.txt.
+// Type is "ADM" for the administrative hierarchy.
+type HierarchyEdge struct {
+	ParentID int    `db:"parent_id"`
+	ChildID  int    `db:"child_id"`
+	Type     string `db:"type"`
+}
+
+// Place kinds in the hierarchy
+const (
+	PlaceContinent = "continent"
+	PlaceCountry   = "country"
+	PlaceAdmin1    = "admin1"
+	PlaceAdmin2    = "admin2"
+	PlaceCity      = "city"
+)
+
+// Place is a hierarchy node the database holds, with its localized name.
+// Code is the continent, country or division code; cities have none.
+type Place struct {
+	GeonameID int    `db:"geoname_id"`
+	Kind      string `db:"kind"`
+	Code      string `db:"code"`
+	Name      string `db:"name"`
+}
+
+// Admin1Key returns the admin_divisions code of the city's first-level
+// division, or "" if it has none
+func (c *City) Admin1Key() string {
+	if c.Admin1Code == "" {
+		return ""
+	}
+	return c.CountryCode + "." + c.Admin1Code
+}
+
+// Admin2Key returns the admin_divisions code of the city's second-level
+// division, or "" if it has none
+func (c *City) Admin2Key() string {
+	if c.Admin1Code == "" || c.Admin2Code == "" {
+		return ""
+	}
+	return c.CountryCode + "." + c.Admin1Code + "." + c.Admin2Code
+}
+
+// PostalCode represents a place covered by a postal code. A code may cover
+// several places, and GB, CA and NL codes only carry the outward part
+// ("SW1A", "H2X", "1012").
+type PostalCode struct {
+	ID          int64   `db:"id"`
+	CountryCode string  `db:"country_code"`
+	PostalCode  string  `db:"postal_code"`
+	PlaceName   string  `db:"place_name"`
+	Admin1Name  string  `db:"admin1_name"`
+	Admin1Code  string  `db:"admin1_code"`
+	Admin2Name  string  `db:"admin2_name"`
+	Admin2Code  string  `db:"admin2_code"`
+	Lat         float64 `db:"lat"`
+	Lon         float64 `db:"lon"`
+	// Accuracy of the coordinates: 1 estimated, 4 geonameid, 6 centroid of
+	// addresses or shape; nil when unknown
+	Accuracy *int `db:"accuracy"`
+}
+
+// PostalCodeWithDistance represents a postal code together with its distance in km from a query point
+type PostalCodeWithDistance struct {
+	PostalCode
+	Distance float64 `db:"distance"`
+}
+
+// DailyUpdate holds one day of GeoNames changes, read from the
+// modifications-, deletes-, alternateNamesModifications- and
+// alternateNamesDeletes- files of that date
+type DailyUpdate struct {
+	// Date is the day of the files, as YYYY-MM-DD
+	Date string
+	// Cities are the modified places that qualify as cities
+	Cities []City
+	// DeletedCityIDs are deleted places and modified places that no longer
+	// qualify as cities (e.g. whose population fell below the threshold)
+	DeletedCityIDs []int
+	// Names are modified alternate names of any place; CityID is its geonameid
+	Names          []CityAlternateName
+	DeletedNameIDs []int64
+}
+
+// Sources of data updates
+const (
+	UpdateSourceDump  = "dump"
+	UpdateSourceDaily = "daily"
+)
+
+// DataUpdate records a full dump import or an applied day of updates. The
+// data is current as of Date (YYYY-MM-DD).
+type DataUpdate struct {
+	Date           string `db:"update_date"`
+	Source         string `db:"source"`
+	CitiesModified int    `db:"cities_modified"`
+	CitiesDeleted  int    `db:"cities_deleted"`
+	NamesModified  int    `db:"names_modified"`
+	NamesDeleted   int    `db:"names_deleted"`
+}
+
+// SeedCheckpoint records how far the seeder got in an import phase: Offset
+// lines of the phase's file are imported
+type SeedCheckpoint struct {
+	Phase     string `db:"phase"`
+	Offset    int64  `db:"line_offset"`
+	Completed bool   `db:"completed"`
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/alexivanou/geocity-api/internal/config"
@@ -38,8 +39,8 @@ func setupRepo(t *testing.T) (*Container, func()) {
 	require.NoError(t, err)
 
 	cities := []model.City{
-		{ID: 1, CountryCode: "DE", NameDefault: "Berlin", Population: 3600000, Lat: 52.5200, Lon: 13.4050},
-		{ID: 2, CountryCode: "DE", NameDefault: "Potsdam", Population: 180000, Lat: 52.3967, Lon: 13.0583},
+		{ID: 1, CountryCode: "DE", NameDefault: "Berlin", Population: 3600000, Lat: 52.5200, Lon: 13.4050, FeatureClass: "P", FeatureCode: "PPLC"},
+		{ID: 2, CountryCode: "DE", NameDefault: "Potsdam", Population: 180000, Lat: 52.3967, Lon: 13.0583, FeatureClass: "P", FeatureCode: "PPLA"},
 	}
 	err = repos.City.BulkInsertCities(ctx, cities)
 	require.NoError(t, err)
@@ -94,7 +95,7 @@ func TestCityRepository_SearchCitiesWithLang(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := repos.City.SearchCitiesWithLang(ctx, tt.query, tt.lang, 10)
+			results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: tt.query, Lang: tt.lang, Limit: 10})
 			require.NoError(t, err)
 			assert.Len(t, results, tt.expectedCount)
 			if tt.expectedCount > 0 {
@@ -115,4 +116,738 @@ func TestCityRepository_FindNearestCity(t *testing.T) {
 	require.NotNil(t, city)
 	assert.Equal(t, "Berlin", city.NameDefault)
 	assert.Less(t, dist, 10.0)
+
+	t.Run("Open ocean", func(t *testing.T) {
+		city, dist, err := repos.City.FindNearestCity(ctx, 0, -140)
+		require.NoError(t, err)
+		require.NotNil(t, city)
+		assert.Greater(t, dist, 8000.0)
+	})
+
+	t.Run("Index sees cities inserted later", func(t *testing.T) {
+		err := repos.Country.BulkInsertCountries(ctx, []model.Country{{Code: "NZ", NameDefault: "New Zealand"}})
+		require.NoError(t, err)
+		err = repos.City.BulkInsertCities(ctx, []model.City{
+			{ID: 50, CountryCode: "NZ", NameDefault: "Wellington", Population: 210000, Lat: -41.2866, Lon: 174.7756},
+		})
+		require.NoError(t, err)
+
+		city, dist, err := repos.City.FindNearestCity(ctx, -41.3, 174.8)
+		require.NoError(t, err)
+		require.NotNil(t, city)
+		assert.Equal(t, "Wellington", city.NameDefault)
+		assert.Less(t, dist, 5.0)
+	})
+}
+
+func TestCityRepository_FindNearestCities(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	t.Run("Ordered by distance", func(t *testing.T) {
+		cities, err := repos.City.FindNearestCities(ctx, model.NearestRequest{Lat: 52.45, Lon: 13.10, Count: 5})
+		require.NoError(t, err)
+		require.Len(t, cities, 2)
+		assert.Equal(t, "Potsdam", cities[0].NameDefault)
+		assert.Equal(t, "Berlin", cities[1].NameDefault)
+		assert.Less(t, cities[0].Distance, cities[1].Distance)
+	})
+
+	t.Run("Limited by max_km", func(t *testing.T) {
+		cities, err := repos.City.FindNearestCities(ctx, model.NearestRequest{Lat: 52.52, Lon: 13.40, Count: 5, MaxKm: 10})
+		require.NoError(t, err)
+		require.Len(t, cities, 1)
+		assert.Equal(t, "Berlin", cities[0].NameDefault)
+	})
+
+	t.Run("Far away point still finds cities", func(t *testing.T) {
+		cities, err := repos.City.FindNearestCities(ctx, model.NearestRequest{Lat: -45.0, Lon: -170.0, Count: 1})
+		require.NoError(t, err)
+		require.Len(t, cities, 1)
+	})
+
+	t.Run("Filtered by feature code", func(t *testing.T) {
+		cities, err := repos.City.FindNearestCities(ctx, model.NearestRequest{
+			Lat: 52.40, Lon: 13.06, Count: 5, FeatureCodes: []string{"PPLC"},
+		})
+		require.NoError(t, err)
+		require.Len(t, cities, 1)
+		assert.Equal(t, "Berlin", cities[0].NameDefault)
+		assert.Equal(t, "PPLC", cities[0].FeatureCode)
+		assert.Equal(t, "P", cities[0].FeatureClass)
+
+		cities, err = repos.City.FindNearestCities(ctx, model.NearestRequest{
+			Lat: 52.40, Lon: 13.06, Count: 5, FeatureCodes: []string{"PPLA4"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, cities)
+	})
+
+	t.Run("Names in the requested language", func(t *testing.T) {
+		require.NoError(t, repos.Translation.BulkInsertCountryTranslations(ctx, []model.CountryTranslation{
+			{CountryCode: "DE", Lang: "de", Name: "Deutschland"},
+		}))
+		cities, err := repos.City.FindNearestCities(ctx, model.NearestRequest{Lat: 52.52, Lon: 13.40, Count: 2, Lang: "de"})
+		require.NoError(t, err)
+		require.Len(t, cities, 2)
+		assert.Equal(t, model.CityNames{Name: "Berlin", Country: "Deutschland"}, cities[0].CityNames)
+		assert.Equal(t, model.CityNames{Name: "Potsdam", Country: "Deutschland"}, cities[1].CityNames, "falls back to the default name")
+	})
+}
+
+func TestCityRepository_FindCitiesWithin(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	req := model.WithinRequest{Lat: 52.45, Lon: 13.10, RadiusKm: 50, Lang: "en", Limit: 10}
+
+	t.Run("Sorted by distance", func(t *testing.T) {
+		req := req
+		req.SortBy = model.SortByDistance
+		results, err := repos.City.FindCitiesWithin(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Potsdam", results[0].Name)
+		assert.Equal(t, "Germany", results[0].Country)
+		require.NotNil(t, results[0].Coordinates)
+		assert.Equal(t, 52.3967, results[0].Coordinates.Lat)
+	})
+
+	t.Run("Sorted by population", func(t *testing.T) {
+		req := req
+		req.SortBy = model.SortByPopulation
+		results, err := repos.City.FindCitiesWithin(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Berlin", results[0].Name)
+	})
+
+	t.Run("Paginated", func(t *testing.T) {
+		for _, sortBy := range []string{model.SortByDistance, model.SortByPopulation} {
+			req := req
+			req.SortBy, req.Limit = sortBy, 1
+			first, err := repos.City.FindCitiesWithin(ctx, req)
+			require.NoError(t, err)
+			require.Len(t, first, 1)
+
+			req.After = &model.WithinCursor{Distance: *first[0].DistanceKm, Population: first[0].Population, ID: first[0].ID}
+			second, err := repos.City.FindCitiesWithin(ctx, req)
+			require.NoError(t, err)
+			require.Len(t, second, 1, sortBy)
+			assert.NotEqual(t, first[0].ID, second[0].ID, sortBy)
+
+			req.After = &model.WithinCursor{Distance: *second[0].DistanceKm, Population: second[0].Population, ID: second[0].ID}
+			rest, err := repos.City.FindCitiesWithin(ctx, req)
+			require.NoError(t, err)
+			assert.Empty(t, rest, sortBy)
+		}
+	})
+
+	t.Run("Radius excludes far cities", func(t *testing.T) {
+		req := req
+		req.Lat, req.Lon, req.RadiusKm = 52.52, 13.40, 5
+		results, err := repos.City.FindCitiesWithin(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Berlin", results[0].Name)
+	})
+}
+
+func TestCityRepository_FindCitiesInBBox(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := repos.Country.BulkInsertCountries(ctx, []model.Country{
+		{Code: "FJ", NameDefault: "Fiji"},
+		{Code: "TO", NameDefault: "Tonga"},
+	})
+	require.NoError(t, err)
+	err = repos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 10, CountryCode: "FJ", NameDefault: "Suva", Population: 93970, Lat: -18.1416, Lon: 178.4415},
+		{ID: 11, CountryCode: "TO", NameDefault: "Nuku'alofa", Population: 22400, Lat: -21.1394, Lon: -175.2018},
+	})
+	require.NoError(t, err)
+
+	t.Run("Largest cities first", func(t *testing.T) {
+		results, err := repos.City.FindCitiesInBBox(ctx, model.BBoxRequest{
+			MinLat: 52, MinLon: 13, MaxLat: 53, MaxLon: 14, Lang: "en", Limit: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Berlin", results[0].Name)
+		assert.Equal(t, "Potsdam", results[1].Name)
+		require.NotNil(t, results[0].Coordinates)
+		assert.Equal(t, 13.405, results[0].Coordinates.Lon)
+	})
+
+	t.Run("Population threshold and limit", func(t *testing.T) {
+		results, err := repos.City.FindCitiesInBBox(ctx, model.BBoxRequest{
+			MinLat: 52, MinLon: 13, MaxLat: 53, MaxLon: 14, MinPopulation: 200000, Lang: "en", Limit: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Berlin", results[0].Name)
+	})
+
+	t.Run("Viewport crossing the antimeridian", func(t *testing.T) {
+		results, err := repos.City.FindCitiesInBBox(ctx, model.BBoxRequest{
+			MinLat: -25, MinLon: 175, MaxLat: -15, MaxLon: -170, Lang: "en", Limit: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Suva", results[0].Name)
+		assert.Equal(t, "Nuku'alofa", results[1].Name)
+	})
+
+	t.Run("Same longitudes without crossing", func(t *testing.T) {
+		results, err := repos.City.FindCitiesInBBox(ctx, model.BBoxRequest{
+			MinLat: -25, MinLon: -170, MaxLat: -15, MaxLon: 175, Lang: "en", Limit: 10,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestCityRepository_SearchCitiesWithLang_Ranking(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := repos.Country.BulkInsertCountries(ctx, []model.Country{
+		{Code: "FR", NameDefault: "France"},
+		{Code: "IT", NameDefault: "Italy"},
+	})
+	require.NoError(t, err)
+	// Populations are chosen so that ordering by population alone would be wrong
+	err = repos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 20, CountryCode: "FR", NameDefault: "Sparta", Population: 9000000},
+		{ID: 21, CountryCode: "FR", NameDefault: "Le Parc", Population: 5000000},
+		{ID: 22, CountryCode: "FR", NameDefault: "Paris", Population: 2100000},
+		{ID: 23, CountryCode: "FR", NameDefault: "Par", Population: 1000},
+		{ID: 30, CountryCode: "IT", NameDefault: "Parma", Population: 200000},
+		{ID: 31, CountryCode: "IT", NameDefault: "Parabiago", Population: 300000},
+	})
+	require.NoError(t, err)
+	err = repos.Translation.BulkInsertCityTranslations(ctx, []model.CityTranslation{
+		{CityID: 30, Lang: "it", Name: "Parma"},
+		{CityID: 21, Lang: "en", Name: "The Park"},
+	})
+	require.NoError(t, err)
+
+	t.Run("Exact, prefix, word-prefix, substring", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: "par", Lang: "fr", Limit: 10})
+		require.NoError(t, err)
+
+		var ids []int
+		for _, r := range results {
+			if r.CountryCode == "FR" {
+				ids = append(ids, r.ID)
+			}
+		}
+		// Par, Paris, Le Parc, Sparta
+		assert.Equal(t, []int{23, 22, 21, 20}, ids)
+	})
+
+	t.Run("Requested language boosted within tier", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: "parm", Lang: "it", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		results, err = repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: "pa", Lang: "it", Limit: 10})
+		require.NoError(t, err)
+		var italian []string
+		for _, r := range results {
+			if r.CountryCode == "IT" {
+				italian = append(italian, r.Name)
+			}
+		}
+		assert.Equal(t, []string{"Parma", "Parabiago"}, italian)
+	})
+
+	t.Run("Keyset pages follow ranking order", func(t *testing.T) {
+		req := model.SuggestRequest{Query: "par", Lang: "fr", Limit: 2}
+		var ids []int
+		for {
+			page, err := repos.City.SearchCitiesWithLang(ctx, req)
+			require.NoError(t, err)
+			for _, r := range page {
+				ids = append(ids, r.ID)
+			}
+			if len(page) < req.Limit {
+				break
+			}
+			last := page[len(page)-1]
+			req.After = &model.SuggestCursor{Score: last.Score, Population: last.Population, ID: last.ID}
+		}
+
+		all, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: "par", Lang: "fr", Limit: 100})
+		require.NoError(t, err)
+		var want []int
+		for _, r := range all {
+			want = append(want, r.ID)
+		}
+		assert.Len(t, want, 6)
+		assert.Equal(t, want, ids)
+	})
+
+	t.Run("Matched name reported", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: "park", Lang: "de", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, 21, results[0].ID)
+		assert.Equal(t, "The Park", results[0].Name)
+		assert.Equal(t, "The Park", results[0].MatchedName)
+	})
+}
+
+func TestCityRepository_SearchCitiesWithLang_Fuzzy(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	t.Run("Typo ignored without fuzzy", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: "Berlln", Lang: "en", Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Typo matched with fuzzy", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+			Query: "Berlln", Lang: "en", Limit: 10, Fuzzy: true, FuzzyThreshold: 0.6,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Berlin", results[0].Name)
+		assert.Less(t, results[0].Score, scoreSubstring)
+	})
+
+	t.Run("Threshold filters weak matches", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+			Query: "Bxxlxx", Lang: "en", Limit: 10, Fuzzy: true, FuzzyThreshold: 0.6,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Substring matches outrank fuzzy ones", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+			Query: "Potsdam", Lang: "en", Limit: 10, Fuzzy: true, FuzzyThreshold: 0.1,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "Potsdam", results[0].Name)
+	})
+}
+
+func TestCityRepository_SearchCitiesWithLang_Aliases(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, repos.Translation.BulkInsertCityAlternateNames(ctx, []model.CityAlternateName{
+		{ID: 1, CityID: 1, Lang: "de", Name: "Berlin", IsPreferred: true},
+		{ID: 2, CityID: 1, Lang: "de", Name: "Spree-Athen", IsColloquial: true},
+		{ID: 3, CityID: 1, Lang: "la", Name: "Berolinum", IsHistoric: true},
+		{ID: 4, CityID: 2, Lang: "de", Name: "Potsdam"},
+	}))
+
+	t.Run("Aliases are opt-in", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: "Berolinum", Lang: "en", Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Historic alias", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+			Query: "Berolinum", Lang: "en", Limit: 10, IncludeAliases: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Berlin", results[0].Name)
+		assert.Equal(t, "Berolinum", results[0].MatchedAlias)
+		require.NotNil(t, results[0].AliasHistoric)
+		assert.True(t, *results[0].AliasHistoric)
+	})
+
+	t.Run("Colloquial alias", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+			Query: "spree", Lang: "en", Limit: 10, IncludeAliases: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Spree-Athen", results[0].MatchedAlias)
+		require.NotNil(t, results[0].AliasHistoric)
+		assert.False(t, *results[0].AliasHistoric)
+	})
+
+	t.Run("Current names win over aliases", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+			Query: "Ber", Lang: "en", Limit: 10, IncludeAliases: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Berlin", results[0].MatchedName)
+		assert.Empty(t, results[0].MatchedAlias)
+		assert.Nil(t, results[0].AliasHistoric)
+	})
+
+	t.Run("Fuzzy alias", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+			Query: "Berolinun", Lang: "en", Limit: 10, IncludeAliases: true, Fuzzy: true, FuzzyThreshold: 0.5,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "Berolinum", results[0].MatchedAlias)
+	})
+}
+
+func TestCityRepository_SearchCitiesWithLang_Filters(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := repos.Country.BulkInsertCountries(ctx, []model.Country{
+		{Code: "AT", NameDefault: "Austria"},
+		{Code: "CH", NameDefault: "Switzerland"},
+	})
+	require.NoError(t, err)
+	berlin, vienna, zurich := "Europe/Berlin", "Europe/Vienna", "Europe/Zurich"
+	err = repos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 40, CountryCode: "DE", NameDefault: "Frankfurt", Population: 750000, Timezone: &berlin},
+		{ID: 41, CountryCode: "DE", NameDefault: "Freiburg", Population: 230000, Timezone: &berlin},
+		{ID: 42, CountryCode: "AT", NameDefault: "Freistadt", Population: 8000, Timezone: &vienna},
+		{ID: 43, CountryCode: "CH", NameDefault: "Fribourg", Population: 38000, Timezone: &zurich},
+	})
+	require.NoError(t, err)
+
+	search := func(req model.SuggestRequest) []int {
+		req.Query, req.Lang, req.Limit = "Fr", "en", 10
+		results, err := repos.City.SearchCitiesWithLang(ctx, req)
+		require.NoError(t, err)
+		ids := []int{}
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int{40, 41, 43, 42}, search(model.SuggestRequest{}))
+	assert.Equal(t, []int{40, 41}, search(model.SuggestRequest{CountryCodes: []string{"DE"}}))
+	assert.Equal(t, []int{43, 42}, search(model.SuggestRequest{CountryCodes: []string{"AT", "CH"}}))
+	assert.Equal(t, []int{40, 41}, search(model.SuggestRequest{MinPopulation: 100000}))
+	assert.Equal(t, []int{43, 42}, search(model.SuggestRequest{MaxPopulation: 100000}))
+	assert.Equal(t, []int{41, 43}, search(model.SuggestRequest{MinPopulation: 10000, MaxPopulation: 500000}))
+	assert.Equal(t, []int{42}, search(model.SuggestRequest{Timezone: vienna}))
+	assert.Equal(t, []int{41}, search(model.SuggestRequest{
+		CountryCodes: []string{"DE", "AT"}, MaxPopulation: 500000, Timezone: "Europe/Berlin", Fuzzy: true, FuzzyThreshold: 0.5,
+	}))
+}
+
+func TestCityRepository_SearchCitiesWithLang_FeatureCodes(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := repos.Country.BulkInsertCountries(ctx, []model.Country{{Code: "US", NameDefault: "United States"}})
+	require.NoError(t, err)
+	err = repos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 50, CountryCode: "US", NameDefault: "Washington", Population: 690000, FeatureClass: "P", FeatureCode: "PPLC"},
+		{ID: 51, CountryCode: "US", NameDefault: "Washington Heights", Population: 2000000, FeatureClass: "P", FeatureCode: "PPLX"},
+		{ID: 52, CountryCode: "US", NameDefault: "Washingtonville", Population: 5800, FeatureClass: "P", FeatureCode: "PPL"},
+		{ID: 53, CountryCode: "US", NameDefault: "Washington Court House", Population: 14000, FeatureClass: "P", FeatureCode: "PPLA2"},
+	})
+	require.NoError(t, err)
+
+	search := func(req model.SuggestRequest) []int {
+		req.Lang, req.Limit = "en", 10
+		results, err := repos.City.SearchCitiesWithLang(ctx, req)
+		require.NoError(t, err)
+		ids := []int{}
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	// The capital outranks a larger city in the same tier but never jumps a tier
+	assert.Equal(t, []int{50, 51, 53, 52}, search(model.SuggestRequest{Query: "Washingto"}))
+	assert.Equal(t, []int{50, 53}, search(model.SuggestRequest{Query: "Wash", FeatureCodes: []string{"PPLC", "PPLA2"}}))
+	assert.Equal(t, []int{52}, search(model.SuggestRequest{Query: "Wash", FeatureCodes: []string{"PPL"}}))
+}
+
+func TestCityColumnList(t *testing.T) {
+	plain := strings.Split(cityColumnList(""), ", ")
+	aliased := strings.Split(cityColumnList("c"), ", ")
+	assert.Equal(t, cityColumns, plain)
+	require.Len(t, aliased, len(cityColumns))
+	for i, column := range cityColumns {
+		assert.Equal(t, "c."+column, aliased[i])
+	}
+}
+
+func TestCityRepository_AdminDivisions(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := repos.Country.BulkInsertAdminDivisions(ctx, []model.AdminDivision{
+		{Code: "DE.16", CountryCode: "DE", Level: 1, NameDefault: "Land Berlin"},
+		{Code: "DE.16.00", CountryCode: "DE", Level: 2, NameDefault: "Kreisfreie Stadt Berlin"},
+	})
+	require.NoError(t, err)
+	err = repos.Translation.BulkInsertAdminDivisionTranslations(ctx, []model.AdminDivisionTranslation{
+		{DivisionCode: "DE.16", Lang: "en", Name: "State of Berlin"},
+		{DivisionCode: "DE.16", Lang: "de", Name: "Berlin"},
+	})
+	require.NoError(t, err)
+	err = repos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 3, CountryCode: "DE", NameDefault: "Spandau", Population: 4000000, Lat: 52.5350, Lon: 13.2000, Admin1Code: "16", Admin2Code: "00"},
+	})
+	require.NoError(t, err)
+
+	t.Run("Localized name with fallbacks", func(t *testing.T) {
+		name, err := repos.Country.GetAdminDivisionName(ctx, "DE.16", "de")
+		require.NoError(t, err)
+		assert.Equal(t, "Berlin", name)
+
+		name, err = repos.Country.GetAdminDivisionName(ctx, "DE.16", "fr")
+		require.NoError(t, err)
+		assert.Equal(t, "State of Berlin", name)
+
+		name, err = repos.Country.GetAdminDivisionName(ctx, "DE.16.00", "fr")
+		require.NoError(t, err)
+		assert.Equal(t, "Kreisfreie Stadt Berlin", name)
+
+		name, err = repos.Country.GetAdminDivisionName(ctx, "DE.99", "en")
+		require.NoError(t, err)
+		assert.Empty(t, name)
+	})
+
+	t.Run("Listings carry region names", func(t *testing.T) {
+		suggest, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{Query: "Spand", Lang: "en", Limit: 10})
+		require.NoError(t, err)
+		require.NotEmpty(t, suggest)
+		assert.Equal(t, "State of Berlin", suggest[0].Region)
+		assert.Equal(t, "Kreisfreie Stadt Berlin", suggest[0].Subregion)
+
+		within, err := repos.City.FindCitiesWithin(ctx, model.WithinRequest{
+			Lat: 52.535, Lon: 13.2, RadiusKm: 50, Lang: "de", SortBy: model.SortByDistance, Limit: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, within, 3)
+		assert.Equal(t, "Spandau", within[0].Name)
+		assert.Equal(t, "Berlin", within[0].Region)
+		assert.Empty(t, within[1].Region, "Berlin has no admin codes in the fixture")
+
+		bbox, err := repos.City.FindCitiesInBBox(ctx, model.BBoxRequest{
+			MinLat: 52, MinLon: 13, MaxLat: 53, MaxLon: 14, Lang: "en", Limit: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, bbox, 3)
+		assert.Equal(t, "Spandau", bbox[0].Name)
+		assert.Equal(t, "State of Berlin", bbox[0].Region)
+	})
+}
+
+func TestCountryRepository_Catalogue(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := repos.Country.BulkInsertCountries(ctx, []model.Country{{
+		Code: "AT", NameDefault: "Austria", GeonameID: 2782113, ISO3: "AUT", ISONumeric: "040",
+		Capital: "Vienna", AreaSqKm: 83858, Population: 8847037, Continent: "EU", TLD: ".at",
+		CurrencyCode: "EUR", CurrencyName: "Euro", Phone: "43", PostalCodeFormat: "####",
+		PostalCodeRegex: "^(\\d{4})$", Languages: "de-AT,hr,hu,sl", Neighbours: "CH,DE,HU,SK,CZ,IT,SI,LI",
+	}})
+	require.NoError(t, err)
+	err = repos.Translation.BulkInsertCountryTranslations(ctx, []model.CountryTranslation{
+		{CountryCode: "AT", Lang: "de", Name: "Österreich"},
+		{CountryCode: "DE", Lang: "de", Name: "Deutschland"},
+	})
+	require.NoError(t, err)
+
+	t.Run("List ordered by code and localized", func(t *testing.T) {
+		countries, err := repos.Country.ListCountries(ctx, "de")
+		require.NoError(t, err)
+		require.Len(t, countries, 2)
+		assert.Equal(t, "AT", countries[0].Code)
+		assert.Equal(t, "Österreich", countries[0].Name)
+		assert.Equal(t, "Deutschland", countries[1].Name)
+
+		countries, err = repos.Country.ListCountries(ctx, "fr")
+		require.NoError(t, err)
+		assert.Equal(t, "Austria", countries[0].Name)
+	})
+
+	t.Run("Single country keeps every column", func(t *testing.T) {
+		country, err := repos.Country.GetCountry(ctx, "AT", "de")
+		require.NoError(t, err)
+		require.NotNil(t, country)
+		assert.Equal(t, "Österreich", country.Name)
+		assert.Equal(t, "AUT", country.ISO3)
+		assert.Equal(t, "040", country.ISONumeric)
+		assert.Equal(t, "Vienna", country.Capital)
+		assert.Equal(t, 83858.0, country.AreaSqKm)
+		assert.Equal(t, int64(8847037), country.Population)
+		assert.Equal(t, 2782113, country.GeonameID)
+		assert.Equal(t, "CH,DE,HU,SK,CZ,IT,SI,LI", country.Neighbours)
+	})
+
+	t.Run("Unknown country", func(t *testing.T) {
+		country, err := repos.Country.GetCountry(ctx, "ZZ", "en")
+		require.NoError(t, err)
+		assert.Nil(t, country)
+	})
+}
+
+func TestPostalCodeRepository(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	accuracy := 4
+	err := repos.PostalCode.BulkInsertPostalCodes(ctx, []model.PostalCode{
+		{ID: 1, CountryCode: "DE", PostalCode: "10115", PlaceName: "Berlin", Admin1Name: "Berlin", Admin1Code: "BE", Lat: 52.5323, Lon: 13.3846, Accuracy: &accuracy},
+		{ID: 2, CountryCode: "DE", PostalCode: "14467", PlaceName: "Potsdam", Admin1Name: "Brandenburg", Admin1Code: "BB", Lat: 52.4, Lon: 13.0667},
+		{ID: 3, CountryCode: "DE", PostalCode: "14467", PlaceName: "Brandenburger Vorstadt", Lat: 52.3989, Lon: 13.0458},
+	})
+	require.NoError(t, err)
+
+	t.Run("Import again", func(t *testing.T) {
+		err := repos.PostalCode.BulkInsertPostalCodes(ctx, []model.PostalCode{
+			{ID: 3, CountryCode: "DE", PostalCode: "14467", PlaceName: "Brandenburger Vorstadt", Lat: 52.3989, Lon: 13.0458},
+		})
+		require.NoError(t, err)
+		codes, err := repos.PostalCode.FindPostalCodes(ctx, "DE", "14467")
+		require.NoError(t, err)
+		assert.Len(t, codes, 2)
+	})
+
+	t.Run("Lookup by code", func(t *testing.T) {
+		codes, err := repos.PostalCode.FindPostalCodes(ctx, "DE", "14467")
+		require.NoError(t, err)
+		require.Len(t, codes, 2)
+		assert.Equal(t, "Brandenburger Vorstadt", codes[0].PlaceName, "ordered by place name")
+		assert.Nil(t, codes[0].Accuracy)
+
+		codes, err = repos.PostalCode.FindPostalCodes(ctx, "DE", "10115")
+		require.NoError(t, err)
+		require.Len(t, codes, 1)
+		assert.Equal(t, "BE", codes[0].Admin1Code)
+		require.NotNil(t, codes[0].Accuracy)
+		assert.Equal(t, 4, *codes[0].Accuracy)
+
+		codes, err = repos.PostalCode.FindPostalCodes(ctx, "AT", "10115")
+		require.NoError(t, err)
+		assert.Empty(t, codes)
+	})
+
+	t.Run("Near a point", func(t *testing.T) {
+		codes, err := repos.PostalCode.FindPostalCodesNear(ctx, model.PostalNearestRequest{Lat: 52.40, Lon: 13.06, RadiusKm: 10, Limit: 5})
+		require.NoError(t, err)
+		require.Len(t, codes, 2, "Berlin is farther than 10 km")
+		assert.Equal(t, "Potsdam", codes[0].PlaceName)
+		assert.Less(t, codes[0].Distance, codes[1].Distance)
+
+		codes, err = repos.PostalCode.FindPostalCodesNear(ctx, model.PostalNearestRequest{Lat: 52.40, Lon: 13.06, RadiusKm: 50, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, codes, 1)
+	})
+}
+
+func TestCountryRepository_PlaceAncestors(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, repos.Country.BulkInsertCountries(ctx, []model.Country{{Code: "AT", NameDefault: "Austria", GeonameID: 2782113}}))
+	require.NoError(t, repos.Country.BulkInsertContinents(ctx, []model.Continent{{Code: "EU", GeonameID: 6255148, NameDefault: "Europe"}}))
+	require.NoError(t, repos.Country.BulkInsertAdminDivisions(ctx, []model.AdminDivision{
+		{Code: "AT.9", CountryCode: "AT", Level: 1, NameDefault: "Vienna", GeonameID: 2761367},
+	}))
+	require.NoError(t, repos.Translation.BulkInsertContinentTranslations(ctx, []model.ContinentTranslation{
+		{ContinentCode: "EU", Lang: "de", Name: "Europa"},
+	}))
+	require.NoError(t, repos.Translation.BulkInsertAdminDivisionTranslations(ctx, []model.AdminDivisionTranslation{
+		{DivisionCode: "AT.9", Lang: "de", Name: "Wien"},
+	}))
+
+	// Earth > Europe > Austria > Vienna > (district, not imported) > Berlin,
+	// plus a non-administrative parent that must not be followed
+	require.NoError(t, repos.Country.BulkInsertHierarchy(ctx, []model.HierarchyEdge{
+		{ParentID: 6295630, ChildID: 6255148},
+		{ParentID: 6255148, ChildID: 2782113},
+		{ParentID: 2782113, ChildID: 2761367, Type: "ADM"},
+		{ParentID: 2761367, ChildID: 2761333, Type: "ADM"},
+		{ParentID: 2761333, ChildID: 1, Type: "ADM"},
+		{ParentID: 2761333, ChildID: 1, Type: "ADM"},
+		{ParentID: 5, ChildID: 1, Type: "dependency"},
+	}))
+
+	ancestors, err := repos.Country.GetPlaceAncestors(ctx, 1, "de")
+	require.NoError(t, err)
+	assert.Equal(t, []model.Place{
+		{GeonameID: 2761367, Kind: model.PlaceAdmin1, Code: "AT.9", Name: "Wien"},
+		{GeonameID: 2782113, Kind: model.PlaceCountry, Code: "AT", Name: "Austria"},
+		{GeonameID: 6255148, Kind: model.PlaceContinent, Code: "EU", Name: "Europa"},
+	}, ancestors)
+
+	t.Run("Cities can be ancestors", func(t *testing.T) {
+		require.NoError(t, repos.Country.BulkInsertHierarchy(ctx, []model.HierarchyEdge{{ParentID: 1, ChildID: 2, Type: "ADM"}}))
+		ancestors, err := repos.Country.GetPlaceAncestors(ctx, 2, "en")
+		require.NoError(t, err)
+		require.Len(t, ancestors, 4)
+		assert.Equal(t, model.Place{GeonameID: 1, Kind: model.PlaceCity, Name: "Berlin"}, ancestors[0])
+		assert.Equal(t, "Europe", ancestors[3].Name)
+	})
+
+	t.Run("Cycles end the walk", func(t *testing.T) {
+		require.NoError(t, repos.Country.BulkInsertHierarchy(ctx, []model.HierarchyEdge{
+			{ParentID: 20, ChildID: 10, Type: "ADM"},
+			{ParentID: 10, ChildID: 20, Type: "ADM"},
+		}))
+		ancestors, err := repos.Country.GetPlaceAncestors(ctx, 10, "en")
+		require.NoError(t, err)
+		assert.Empty(t, ancestors)
+	})
+}
+
+func TestCityRepository_AlternateNames(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, repos.Translation.BulkInsertCityAlternateNames(ctx, []model.CityAlternateName{
+		{ID: 10, CityID: 1, Lang: "la", Name: "Berolinum", IsHistoric: true},
+		{ID: 11, CityID: 1, Lang: "de", Name: "Spree-Athen", IsColloquial: true},
+		{ID: 12, CityID: 1, Lang: "de", Name: "Berlin", IsPreferred: true},
+		{ID: 13, CityID: 1, Lang: "", Name: "BER"},
+		{ID: 14, CityID: 2, Lang: "de", Name: "Potsdam"},
+	}))
+	// Re-importing a name replaces it
+	require.NoError(t, repos.Translation.BulkInsertCityAlternateNames(ctx, []model.CityAlternateName{
+		{ID: 13, CityID: 1, Lang: "", Name: "Berlin", IsShort: true},
+	}))
+
+	names, err := repos.City.GetCityAlternateNames(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []model.CityAlternateName{
+		{ID: 13, CityID: 1, Lang: "", Name: "Berlin", IsShort: true},
+		{ID: 12, CityID: 1, Lang: "de", Name: "Berlin", IsPreferred: true},
+		{ID: 11, CityID: 1, Lang: "de", Name: "Spree-Athen", IsColloquial: true},
+		{ID: 10, CityID: 1, Lang: "la", Name: "Berolinum", IsHistoric: true},
+	}, names)
+
+	// Display names are unaffected
+	name, err := repos.City.GetCityName(ctx, 1, "de")
+	require.NoError(t, err)
+	assert.Equal(t, "Berlin", name)
+
+	names, err = repos.City.GetCityAlternateNames(ctx, 999)
+	require.NoError(t, err)
+	assert.Empty(t, names)
 }
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"math"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+)
+
+const earthRadiusKm = 6371.0
+
+func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	const R = earthRadiusKm
+	dLat := (lat2 - lat1) * (math.Pi / 180.0)
+	dLon := (lon2 - lon1) * (math.Pi / 180.0)
+	lat1Rad := lat1 * (math.Pi / 180.0)
+	lat2Rad := lat2 * (math.Pi / 180.0)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Sin(dLon/2)*math.Sin(dLon/2)*math.Cos(lat1Rad)*math.Cos(lat2Rad)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return R * c
+}
+
+// geoBox is a lat/lon rectangle. When MinLon > MaxLon the box crosses the
+// antimeridian and covers [MinLon, 180] plus [-180, MaxLon].
+type geoBox struct {
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+}
+
+// boundingBox returns the smallest box containing every point within radiusKm
+// of (lat, lon). Near the poles the box widens to all longitudes, and boxes
+// reaching past ±180° wrap around instead of being clamped.
+func boundingBox(lat, lon, radiusKm float64) geoBox {
+	angular := radiusKm / earthRadiusKm
+	dLat := angular * 180 / math.Pi
+
+	minLat, maxLat := lat-dLat, lat+dLat
+	if minLat <= -90 || maxLat >= 90 {
+		return geoBox{MinLat: math.Max(minLat, -90), MaxLat: math.Min(maxLat, 90), MinLon: -180, MaxLon: 180}
+	}
+
+	// Longitude span at the latitude where the circle is widest
+	ratio := math.Sin(angular) / math.Cos(lat*math.Pi/180)
+	if angular >= math.Pi/2 || ratio >= 1 {
+		return geoBox{MinLat: minLat, MaxLat: maxLat, MinLon: -180, MaxLon: 180}
+	}
+	dLon := math.Asin(ratio) * 180 / math.Pi
+
+	minLon, maxLon := lon-dLon, lon+dLon
+	if minLon < -180 {
+		minLon += 360
+	}
+	if maxLon > 180 {
+		maxLon -= 360
+	}
+	return geoBox{MinLat: minLat, MaxLat: maxLat, MinLon: minLon, MaxLon: maxLon}
+}
+
+// crossesAntimeridian reports whether the box wraps around ±180° longitude
+func (b geoBox) crossesAntimeridian() bool {
+	return b.MinLon > b.MaxLon
+}
+
+// withinPosition is the position of a radius search result in its ordering
+func withinPosition(city model.CityResult) model.WithinCursor {
+	return model.WithinCursor{Distance: *city.DistanceKm, Population: city.Population, ID: city.ID}
+}
+
+// withinLess orders radius search results by distance, or by population
+// first for SortByPopulation, breaking ties by id
+func withinLess(sortBy string, a, b model.WithinCursor) bool {
+	if sortBy == model.SortByPopulation && a.Population != b.Population {
+		return a.Population > b.Population
+	}
+	if a.Distance != b.Distance {
+		return a.Distance < b.Distance
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// maxHierarchyDepth bounds the walk up the hierarchy, which guards against
+// cycles in the source data
+const maxHierarchyDepth = 12
+
+// hierarchyParentSQL picks one parent of a place, preferring the
+// administrative hierarchy when hierarchy.txt lists several
+const hierarchyParentSQL = `
+	SELECT parent_id FROM hierarchy
+	WHERE child_id = ?
+	ORDER BY CASE WHEN type = 'ADM' THEN 0 ELSE 1 END, parent_id
+	LIMIT 1
+`
+
+// hierarchyPlaceSQL resolves a geonameid to a continent, country, admin
+// division or city with its name in the language bound to the first
+// placeholder of each branch, falling back to English and the default name
+const hierarchyPlaceSQL = `
+	SELECT kind, code, name FROM (
+		SELECT 1 AS priority, '` + model.PlaceContinent + `' AS kind, con.code AS code,
+			COALESCE(con_t.name, con_en.name, con.name_default) AS name
+		FROM continents con
+		LEFT JOIN continent_translations con_t ON con.code = con_t.continent_code AND con_t.lang = ?
+		LEFT JOIN continent_translations con_en ON con.code = con_en.continent_code AND con_en.lang = 'en'
+		WHERE con.geoname_id = ?
+		UNION ALL
+		SELECT 2, '` + model.PlaceCountry + `', cnt.code,
+			COALESCE(cnt_t.name, cnt_en.name, cnt.name_default)
+		FROM countries cnt
+		LEFT JOIN country_translations cnt_t ON cnt.code = cnt_t.country_code AND cnt_t.lang = ?
+		LEFT JOIN country_translations cnt_en ON cnt.code = cnt_en.country_code AND cnt_en.lang = 'en'
+		WHERE cnt.geoname_id = ?
+		UNION ALL
+		SELECT 3, CASE WHEN ad.level = 1 THEN '` + model.PlaceAdmin1 + `' ELSE '` + model.PlaceAdmin2 + `' END, ad.code,
+			COALESCE(ad_t.name, ad_en.name, ad.name_default)
+		FROM admin_divisions ad
+		LEFT JOIN admin_division_translations ad_t ON ad.code = ad_t.division_code AND ad_t.lang = ?
+		LEFT JOIN admin_division_translations ad_en ON ad.code = ad_en.division_code AND ad_en.lang = 'en'
+		WHERE ad.geoname_id = ?
+		UNION ALL
+		SELECT 4, '` + model.PlaceCity + `', '',
+			COALESCE(ct.name, ct_en.name, c.name_default)
+		FROM cities c
+		LEFT JOIN city_translations ct ON c.id = ct.city_id AND ct.lang = ?
+		LEFT JOIN city_translations ct_en ON c.id = ct_en.city_id AND ct_en.lang = 'en'
+		WHERE c.id = ?
+	) AS places
+	ORDER BY priority
+	LIMIT 1
+`
+
+// placeAncestors walks hierarchy.txt up from a place and returns the
+// ancestors the database holds, nearest first. Places that are not imported,
+// such as third-level divisions and the Earth, are walked through but left
+// out. The queries are written with ? and rebound for the driver.
+func placeAncestors(ctx context.Context, db *sqlx.DB, geonameID int, lang string) ([]model.Place, error) {
+	parentQuery := db.Rebind(hierarchyParentSQL)
+	placeQuery := db.Rebind(hierarchyPlaceSQL)
+
+	var ancestors []model.Place
+	visited := map[int]bool{geonameID: true}
+	id := geonameID
+	for depth := 0; depth < maxHierarchyDepth; depth++ {
+		var parentID int
+		err := db.GetContext(ctx, &parentID, parentQuery, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if visited[parentID] {
+			break
+		}
+		visited[parentID] = true
+		id = parentID
+
+		var place model.Place
+		err = db.GetContext(ctx, &place, placeQuery, lang, id, lang, id, lang, id, lang, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		place.GeonameID = id
+		ancestors = append(ancestors, place)
+	}
+	return ancestors, nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// kdTree is a static 3-d tree over points on the unit sphere. Working with
+// unit vectors instead of lat/lon makes the search correct everywhere: there
+// is no seam at the antimeridian and no singularity at the poles, and the
+// straight-line (chord) distance between two unit vectors grows monotonically
+// with their great-circle distance.
+//
+// The tree is implicit: points are ordered so that the median of every
+// subrange is its root, which keeps it balanced and allocation free.
+type kdTree struct {
+	points []kdPoint
+}
+
+type kdPoint struct {
+	ID          int     `db:"id"`
+	Lat         float64 `db:"lat"`
+	Lon         float64 `db:"lon"`
+	FeatureCode string  `db:"feature_code"`
+	Population  int     `db:"population"`
+	vec         [3]float64
+}
+
+// kdHit is a point found by a query, with its great-circle distance in km
+type kdHit struct {
+	ID         int
+	Population int
+	Distance   float64
+}
+
+// newKDTree builds a tree over points. The slice is reordered in place.
+func newKDTree(points []kdPoint) *kdTree {
+	for i := range points {
+		points[i].vec = unitVector(points[i].Lat, points[i].Lon)
+	}
+	t := &kdTree{points: points}
+	t.build(0, len(points), 0)
+	return t
+}
+
+func (t *kdTree) build(lo, hi, depth int) {
+	if hi-lo <= 1 {
+		return
+	}
+	axis := depth % 3
+	sub := t.points[lo:hi]
+	sort.Slice(sub, func(i, j int) bool { return sub[i].vec[axis] < sub[j].vec[axis] })
+
+	mid := (lo + hi) / 2
+	t.build(lo, mid, depth+1)
+	t.build(mid+1, hi, depth+1)
+}
+
+// Nearest returns up to k points closest to (lat, lon), nearest first. A
+// positive maxKm drops points farther away than that, and a non-nil keep
+// drops points it returns false for.
+func (t *kdTree) Nearest(lat, lon float64, k int, maxKm float64, keep func(p *kdPoint) bool) []kdHit {
+	if k <= 0 || len(t.points) == 0 {
+		return nil
+	}
+
+	// Compare squared chord lengths; they avoid trigonometry in the hot loop
+	bound := math.Inf(1)
+	if maxKm > 0 {
+		chord := chordLength(maxKm)
+		bound = chord * chord
+	}
+
+	s := &kdSearch{tree: t, query: unitVector(lat, lon), k: k, bound: bound, keep: keep}
+	s.search(0, len(t.points), 0)
+
+	hits := make([]kdHit, len(s.best))
+	for i := len(hits) - 1; i >= 0; i-- {
+		c := heap.Pop(&s.best).(kdCandidate)
+		p := t.points[c.index]
+		hits[i] = kdHit{ID: p.ID, Population: p.Population, Distance: calculateDistance(lat, lon, p.Lat, p.Lon)}
+	}
+	return hits
+}
+
+// Within returns every point within maxKm of (lat, lon), in no particular
+// order
+func (t *kdTree) Within(lat, lon, maxKm float64) []kdHit {
+	// The slack keeps rounding from pruning points on the circle, which the
+	// great-circle distance then decides on
+	chord := chordLength(maxKm)
+	bound := chord * chord * (1 + 1e-9)
+	query := unitVector(lat, lon)
+
+	var hits []kdHit
+	var search func(lo, hi, depth int)
+	search = func(lo, hi, depth int) {
+		if lo >= hi {
+			return
+		}
+		mid := (lo + hi) / 2
+		p := &t.points[mid]
+		if squaredDistance(p.vec, query) <= bound {
+			// The great-circle distance decides, as it does for the SQL queries
+			if d := calculateDistance(lat, lon, p.Lat, p.Lon); d <= maxKm {
+				hits = append(hits, kdHit{ID: p.ID, Population: p.Population, Distance: d})
+			}
+		}
+
+		axis := depth % 3
+		diff := query[axis] - p.vec[axis]
+		if diff < 0 || diff*diff <= bound {
+			search(lo, mid, depth+1)
+		}
+		if diff >= 0 || diff*diff <= bound {
+			search(mid+1, hi, depth+1)
+		}
+	}
+	search(0, len(t.points), 0)
+	return hits
+}
+
+type kdSearch struct {
+	tree  *kdTree
+	query [3]float64
+	k     int
+	bound float64
+	keep  func(p *kdPoint) bool
+	best  kdCandidates
+}
+
+func (s *kdSearch) search(lo, hi, depth int) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	p := &s.tree.points[mid]
+	if s.keep == nil || s.keep(p) {
+		s.offer(mid, squaredDistance(p.vec, s.query))
+	}
+
+	axis := depth % 3
+	diff := s.query[axis] - p.vec[axis]
+	if diff < 0 {
+		s.search(lo, mid, depth+1)
+		if diff*diff <= s.limit() {
+			s.search(mid+1, hi, depth+1)
+		}
+	} else {
+		s.search(mid+1, hi, depth+1)
+		if diff*diff <= s.limit() {
+			s.search(lo, mid, depth+1)
+		}
+	}
+}
+
+// limit is the squared distance a point must beat to enter the result
+func (s *kdSearch) limit() float64 {
+	if len(s.best) < s.k {
+		return s.bound
+	}
+	return math.Min(s.bound, s.best[0].dist2)
+}
+
+func (s *kdSearch) offer(index int, dist2 float64) {
+	if dist2 > s.bound {
+		return
+	}
+	c := kdCandidate{index: index, id: s.tree.points[index].ID, dist2: dist2}
+	if len(s.best) < s.k {
+		heap.Push(&s.best, c)
+		return
+	}
+	if c.less(s.best[0]) {
+		s.best[0] = c
+		heap.Fix(&s.best, 0)
+	}
+}
+
+type kdCandidate struct {
+	index int
+	id    int
+	dist2 float64
+}
+
+// less orders by distance, then ID so that equidistant points come back in a
+// stable order
+func (c kdCandidate) less(o kdCandidate) bool {
+	if c.dist2 != o.dist2 {
+		return c.dist2 < o.dist2
+	}
+	return c.id < o.id
+}
+
+// kdCandidates is a max-heap: the worst of the current k best sits on top
+type kdCandidates []kdCandidate
+
+func (h kdCandidates) Len() int            { return len(h) }
+func (h kdCandidates) Less(i, j int) bool  { return h[j].less(h[i]) }
+func (h kdCandidates) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *kdCandidates) Push(x interface{}) { *h = append(*h, x.(kdCandidate)) }
+func (h *kdCandidates) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+func unitVector(lat, lon float64) [3]float64 {
+	latRad := lat * math.Pi / 180
+	lonRad := lon * math.Pi / 180
+	return [3]float64{
+		math.Cos(latRad) * math.Cos(lonRad),
+		math.Cos(latRad) * math.Sin(lonRad),
+		math.Sin(latRad),
+	}
+}
+
+// chordLength converts a great-circle distance into the straight-line
+// distance between the two points on the unit sphere
+func chordLength(km float64) float64 {
+	angle := km / earthRadiusKm
+	if angle >= math.Pi {
+		return 2
+	}
+	return 2 * math.Sin(angle/2)
+}
+
+func squaredDistance(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
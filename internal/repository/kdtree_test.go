@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bruteForceNearest is the reference the tree is checked against
+func bruteForceNearest(points []kdPoint, lat, lon float64, k int, maxKm float64) []kdHit {
+	var hits []kdHit
+	for _, p := range points {
+		d := calculateDistance(lat, lon, p.Lat, p.Lon)
+		if maxKm <= 0 || d <= maxKm {
+			hits = append(hits, kdHit{ID: p.ID, Distance: d})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Distance != hits[j].Distance {
+			return hits[i].Distance < hits[j].Distance
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+func TestKDTree_MatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	points := make([]kdPoint, 5000)
+	for i := range points {
+		points[i] = kdPoint{ID: i + 1, Lat: rng.Float64()*180 - 90, Lon: rng.Float64()*360 - 180}
+	}
+	reference := append([]kdPoint(nil), points...)
+	tree := newKDTree(points)
+
+	queries := [][2]float64{
+		{90, 0}, {-90, 0}, // poles
+		{0, 180}, {0, -180}, {-17.7, 179.9}, // antimeridian
+		{52.52, 13.405}, {0, 0},
+	}
+	for i := 0; i < 50; i++ {
+		queries = append(queries, [2]float64{rng.Float64()*180 - 90, rng.Float64()*360 - 180})
+	}
+
+	for _, q := range queries {
+		for _, k := range []int{1, 5, 25} {
+			for _, maxKm := range []float64{0, 300} {
+				want := bruteForceNearest(reference, q[0], q[1], k, maxKm)
+				got := tree.Nearest(q[0], q[1], k, maxKm, nil)
+				require.Len(t, got, len(want), "query %v k=%d max=%v", q, k, maxKm)
+				for i := range want {
+					assert.Equal(t, want[i].ID, got[i].ID, "query %v k=%d max=%v", q, k, maxKm)
+					assert.InDelta(t, want[i].Distance, got[i].Distance, 1e-9)
+				}
+			}
+		}
+	}
+}
+
+func TestKDTree_WithinMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	points := make([]kdPoint, 5000)
+	for i := range points {
+		points[i] = kdPoint{ID: i + 1, Lat: rng.Float64()*180 - 90, Lon: rng.Float64()*360 - 180, Population: i}
+	}
+	reference := append([]kdPoint(nil), points...)
+	tree := newKDTree(points)
+
+	for _, q := range [][2]float64{{90, 0}, {-17.7, 179.9}, {52.52, 13.405}, {0, 0}} {
+		for _, maxKm := range []float64{50, 500, 1000} {
+			want := bruteForceNearest(reference, q[0], q[1], len(reference), maxKm)
+			got := tree.Within(q[0], q[1], maxKm)
+			sort.Slice(got, func(i, j int) bool {
+				if got[i].Distance != got[j].Distance {
+					return got[i].Distance < got[j].Distance
+				}
+				return got[i].ID < got[j].ID
+			})
+			require.Len(t, got, len(want), "query %v max=%v", q, maxKm)
+			for i := range want {
+				assert.Equal(t, want[i].ID, got[i].ID, "query %v max=%v", q, maxKm)
+				assert.Equal(t, want[i].ID-1, got[i].Population)
+			}
+		}
+	}
+}
+
+func TestKDTree_AcrossAntimeridian(t *testing.T) {
+	tree := newKDTree([]kdPoint{
+		{ID: 1, Lat: -18.1416, Lon: 178.4419},  // Suva
+		{ID: 2, Lat: -21.1394, Lon: -175.2049}, // Nuku'alofa
+		{ID: 3, Lat: -17.0, Lon: 170.0},
+	})
+
+	// Just east of the antimeridian, Suva (west of it) is still the nearest
+	hits := tree.Nearest(-18.0, -179.9, 2, 0, nil)
+	require.Len(t, hits, 2)
+	assert.Equal(t, 1, hits[0].ID)
+	assert.Equal(t, 2, hits[1].ID)
+	assert.Less(t, hits[0].Distance, 200.0)
+}
+
+func TestKDTree_Empty(t *testing.T) {
+	tree := newKDTree(nil)
+	assert.Empty(t, tree.Nearest(0, 0, 3, 0, nil))
+}
+
+func TestKDTree_Filter(t *testing.T) {
+	tree := newKDTree([]kdPoint{
+		{ID: 1, Lat: 52.52, Lon: 13.405, FeatureCode: "PPLC"},
+		{ID: 2, Lat: 52.3967, Lon: 13.0583, FeatureCode: "PPLA"},
+		{ID: 3, Lat: 52.40, Lon: 13.07, FeatureCode: "PPL"},
+	})
+
+	capitals := func(p *kdPoint) bool { return p.FeatureCode == "PPLC" }
+	hits := tree.Nearest(52.40, 13.06, 3, 0, capitals)
+	require.Len(t, hits, 1)
+	assert.Equal(t, 1, hits[0].ID)
+
+	assert.Empty(t, tree.Nearest(52.40, 13.06, 3, 5, capitals), "max_km still applies")
+}
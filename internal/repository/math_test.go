@@ -70,3 +70,32 @@ func TestCalculateDistance(t *testing.T) {
 		})
 	}
 }
+
+func TestBoundingBox(t *testing.T) {
+	t.Run("Contains circle", func(t *testing.T) {
+		box := boundingBox(52.52, 13.405, 100)
+		assert.False(t, box.crossesAntimeridian())
+		assert.InDelta(t, 52.52-0.899, box.MinLat, 0.01)
+		assert.InDelta(t, 52.52+0.899, box.MaxLat, 0.01)
+		// A degree of longitude is shorter at higher latitudes
+		assert.Greater(t, box.MaxLon-box.MinLon, box.MaxLat-box.MinLat)
+	})
+
+	t.Run("Wraps around antimeridian", func(t *testing.T) {
+		box := boundingBox(-17.7, 179.5, 200)
+		assert.True(t, box.crossesAntimeridian())
+		assert.Greater(t, box.MinLon, 177.0)
+		assert.Less(t, box.MaxLon, -178.0)
+	})
+
+	t.Run("Covers all longitudes near the pole", func(t *testing.T) {
+		box := boundingBox(89.5, 0, 100)
+		assert.Equal(t, 90.0, box.MaxLat)
+		assert.Equal(t, -180.0, box.MinLon)
+		assert.Equal(t, 180.0, box.MaxLon)
+	})
+
+	t.Run("Whole globe", func(t *testing.T) {
+		assert.Equal(t, geoBox{MinLat: -90, MaxLat: 90, MinLon: -180, MaxLon: 180}, boundingBox(0, 0, 25000))
+	})
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+// pgCopyMinRows is the smallest batch loaded with COPY. Smaller ones use
+// multi-row INSERTs, for which the temporary table costs more than it saves.
+const pgCopyMinRows = 1000
+
+// errNotPgx reports a connection whose driver is not pgx, which has no COPY
+var errNotPgx = errors.New("connection is not a pgx connection")
+
+// pgCopyUpsert loads rows of columns into table with COPY: they are copied
+// into a temporary table shaped like it and upserted from there by conflict,
+// an ON CONFLICT clause, in one transaction. It returns errNotPgx if db does
+// not use the pgx driver.
+func pgCopyUpsert(ctx context.Context, db *sqlx.DB, table string, columns []string, rows [][]any, conflict string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errNotPgx
+		}
+
+		tx, err := stdlibConn.Conn().Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		// LIKE resolves table on the search path, so a staging schema works too
+		temp := "copy_" + table
+		if _, err := tx.Exec(ctx, "CREATE TEMP TABLE "+temp+" (LIKE "+table+") ON COMMIT DROP"); err != nil {
+			return err
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{temp}, columns, pgx.CopyFromRows(rows)); err != nil {
+			return err
+		}
+		list := strings.Join(columns, ", ")
+		if _, err := tx.Exec(ctx, "INSERT INTO "+table+" ("+list+") SELECT "+list+" FROM "+temp+" "+conflict); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+}
+
+// cityCopyRows renders cities as rows of cityColumns
+func cityCopyRows(cities []model.City) [][]any {
+	rows := make([][]any, len(cities))
+	for i, c := range cities {
+		rows[i] = []any{
+			c.ID, c.CountryCode, c.NameDefault, c.Population, c.Lat, c.Lon, c.Elevation, c.Timezone,
+			c.Admin1Code, c.Admin2Code, c.Admin3Code, c.Admin4Code, c.FeatureClass, c.FeatureCode,
+		}
+	}
+	return rows
+}
+
+// cityTranslationColumns are the city_translations columns of model.CityTranslation
+var cityTranslationColumns = []string{"city_id", "lang", "name"}
+
+// cityTranslationCopyRows renders translations as rows of cityTranslationColumns
+func cityTranslationCopyRows(translations []model.CityTranslation) [][]any {
+	rows := make([][]any, len(translations))
+	for i, t := range translations {
+		rows[i] = []any{t.CityID, t.Lang, t.Name}
+	}
+	return rows
+}
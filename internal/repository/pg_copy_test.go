@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/config"
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fieldsByColumn maps the db tags of a struct to its field values
+func fieldsByColumn(v any) map[string]any {
+	value := reflect.ValueOf(v)
+	fields := make(map[string]any)
+	for i := 0; i < value.NumField(); i++ {
+		fields[value.Type().Field(i).Tag.Get("db")] = value.Field(i).Interface()
+	}
+	return fields
+}
+
+func TestCopyRows_MatchColumns(t *testing.T) {
+	elevation, timezone := 34, "Europe/Berlin"
+	city := model.City{
+		ID: 2950159, CountryCode: "DE", NameDefault: "Berlin", Population: 3700000, Lat: 52.52, Lon: 13.405,
+		Elevation: &elevation, Timezone: &timezone, Admin1Code: "16", Admin2Code: "00", Admin3Code: "11000",
+		Admin4Code: "11000000", FeatureClass: "P", FeatureCode: "PPLC",
+	}
+	translation := model.CityTranslation{CityID: 2950159, Lang: "de", Name: "Berlin"}
+
+	for name, tc := range map[string]struct {
+		columns []string
+		row     []any
+		record  any
+	}{
+		"cities":            {cityColumns, cityCopyRows([]model.City{city})[0], city},
+		"city_translations": {cityTranslationColumns, cityTranslationCopyRows([]model.CityTranslation{translation})[0], translation},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Len(t, tc.row, len(tc.columns))
+			fields := fieldsByColumn(tc.record)
+			for i, column := range tc.columns {
+				assert.Equal(t, fields[column], tc.row[i], column)
+			}
+		})
+	}
+}
+
+func TestPgBulkInsert_FallsBackWithoutPgx(t *testing.T) {
+	// The Postgres repositories on a SQLite connection: COPY is unavailable,
+	// and the multi-row INSERTs, which SQLite understands too, load the rows
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+	db := repos.City.(*sqliteCityRepository).db
+
+	err := pgCopyUpsert(ctx, db, "cities", cityColumns, nil, "")
+	assert.ErrorIs(t, err, errNotPgx)
+
+	cities := make([]model.City, pgCopyMinRows)
+	translations := make([]model.CityTranslation, pgCopyMinRows)
+	for i := range cities {
+		id := 1000 + i
+		cities[i] = model.City{ID: id, CountryCode: "DE", NameDefault: fmt.Sprintf("Town %d", i), FeatureClass: "P", FeatureCode: "PPL"}
+		translations[i] = model.CityTranslation{CityID: id, Lang: "de", Name: fmt.Sprintf("Stadt %d", i)}
+	}
+	cityRepo, translationRepo := &pgCityRepository{db: db}, &pgTranslationRepository{db: db}
+	require.NoError(t, cityRepo.BulkInsertCities(ctx, cities))
+	require.NoError(t, translationRepo.BulkInsertCityTranslations(ctx, translations))
+
+	// Loading again upserts
+	cities[0].NameDefault, translations[0].Name = "Renamed", "Umbenannt"
+	require.NoError(t, cityRepo.BulkInsertCities(ctx, cities))
+	require.NoError(t, translationRepo.BulkInsertCityTranslations(ctx, translations))
+
+	var count int
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM city_translations WHERE city_id >= 1000"))
+	assert.Equal(t, pgCopyMinRows, count)
+
+	city, err := repos.City.GetCityByID(ctx, 1000)
+	require.NoError(t, err)
+	require.NotNil(t, city)
+	assert.Equal(t, "Renamed", city.NameDefault)
+	name, err := repos.City.GetCityName(ctx, 1000, "de")
+	require.NoError(t, err)
+	assert.Equal(t, "Umbenannt", name)
+}
+
+func TestPgUsePostGIS_CachesOnlySuccessfulProbes(t *testing.T) {
+	// SQLite has no information_schema, so the probe always fails there
+	sqliteRepos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+	db := sqliteRepos.City.(*sqliteCityRepository).db
+
+	repos := NewRepositories(db, config.DBTypePostgreSQL)
+	cityRepo := repos.City.(*pgCityRepository)
+
+	assert.False(t, cityRepo.usePostGIS(ctx))
+	assert.Nil(t, cityRepo.postgis, "a failed probe is retried on the next query")
+
+	probed := true
+	cityRepo.postgis = &probed
+	assert.True(t, cityRepo.usePostGIS(ctx))
+
+	repos.dataChanged()
+	assert.Nil(t, cityRepo.postgis, "changed data forgets the probe")
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/alexivanou/geocity-api/internal/model"
 	"github.com/jmoiron/sqlx"
@@ -11,17 +15,32 @@ import (
 
 // --- PostgreSQL Implementation ---
 
+// pgDistanceSQL is the great-circle distance in km from ($1, $2) to a row's lat/lon
+const pgDistanceSQL = `(
+	6371 * acos(
+		least(1.0, greatest(-1.0,
+			cos(radians($1)) * cos(radians(lat)) * cos(radians(lon) - radians($2)) +
+			sin(radians($1)) * sin(radians(lat))
+		))
+	)
+)`
+
 type pgCityRepository struct {
 	db *sqlx.DB
+
+	// postgis caches the last successful PostGIS probe; nil until one
+	// succeeds or after the data changed
+	postgisMu sync.Mutex
+	postgis   *bool
 }
 
 func (r *pgCityRepository) SearchCities(ctx context.Context, query string, limit int) ([]model.City, error) {
 	q := `
-		SELECT DISTINCT c.*
+		SELECT DISTINCT ` + cityColumnList("c") + `
 		FROM cities c
 		WHERE unaccent(LOWER(c.name_default)) LIKE '%' || unaccent(LOWER($1)) || '%'
 		UNION
-		SELECT DISTINCT c.*
+		SELECT DISTINCT ` + cityColumnList("c") + `
 		FROM cities c
 		INNER JOIN city_translations ct ON c.id = ct.city_id
 		WHERE unaccent(LOWER(ct.name)) LIKE '%' || unaccent(LOWER($1)) || '%'
@@ -35,75 +54,233 @@ func (r *pgCityRepository) SearchCities(ctx context.Context, query string, limit
 	return cities, nil
 }
 
-func (r *pgCityRepository) SearchCitiesWithLang(ctx context.Context, query string, lang string, limit int) ([]model.CityResult, error) {
+func (r *pgCityRepository) SearchCitiesWithLang(ctx context.Context, req model.SuggestRequest) ([]model.CityResult, error) {
+	q, args := buildSuggestQuery(pgSearchDialect, req)
+
+	var results []model.CityResult
+	if !req.Fuzzy {
+		if err := r.db.SelectContext(ctx, &results, q, args...); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	// The % prefilter uses pg_trgm.similarity_threshold, so lower it to the
+	// requested threshold for this query only; otherwise thresholds below
+	// the 0.3 default would silently behave as 0.3
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	threshold := strconv.FormatFloat(req.FuzzyThreshold, 'f', -1, 64)
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('pg_trgm.similarity_threshold', $1, true)", threshold); err != nil {
+		return nil, fmt.Errorf("failed to set similarity threshold: %w", err)
+	}
+	if err := tx.SelectContext(ctx, &results, q, args...); err != nil {
+		return nil, err
+	}
+	return results, tx.Commit()
+}
+
+func (r *pgCityRepository) FindNearestCity(ctx context.Context, lat, lon float64) (*model.City, float64, error) {
+	cities, err := r.FindNearestCities(ctx, model.NearestRequest{Lat: lat, Lon: lon, Count: 1})
+	if err != nil || len(cities) == 0 {
+		return nil, 0, err
+	}
+	return &cities[0].City, cities[0].Distance, nil
+}
+
+func (r *pgCityRepository) FindNearestCities(ctx context.Context, req model.NearestRequest) ([]model.CityWithDistance, error) {
+	limit := req.Count
+	if limit <= 0 {
+		limit = 1
+	}
+
+	args := []interface{}{req.Lat, req.Lon, req.MaxKm, limit, req.Lang}
+	featureCond := "TRUE"
+	if len(req.FeatureCodes) > 0 {
+		placeholders := make([]string, len(req.FeatureCodes))
+		for i, code := range req.FeatureCodes {
+			args = append(args, code)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		featureCond = "feature_code IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	// Without PostGIS the distance to every city is computed and sorted
+	nearest := `
+		SELECT * FROM (
+			SELECT 
+				` + cityColumnList("") + `,
+				` + pgDistanceSQL + ` AS distance
+			FROM cities
+			WHERE ` + featureCond + `
+		) AS candidates
+		WHERE ($3::float8 <= 0 OR distance <= $3::float8)
+		ORDER BY distance ASC
+		LIMIT $4
+	`
+	if r.usePostGIS(ctx) {
+		// KNN: the GiST index on geom yields rows in distance order, so only
+		// the returned rows are visited. Distances use the sphere, like the
+		// formula above.
+		nearest = `
+			SELECT 
+				` + cityColumnList("") + `,
+				ST_Distance(geom, ST_MakePoint($2, $1)::geography, false) / 1000 AS distance
+			FROM cities
+			WHERE ($3::float8 <= 0 OR ST_DWithin(geom, ST_MakePoint($2, $1)::geography, $3::float8 * 1000, false))
+				AND ` + featureCond + `
+			ORDER BY geom <-> ST_MakePoint($2, $1)::geography
+			LIMIT $4
+		`
+	}
+
+	// Only the nearest cities get their names joined
 	q := `
-		SELECT DISTINCT 
-			c.id,
-			COALESCE(ct.name, ct_en.name, c.name_default) as name,
-			COALESCE(cnt_t.name, cnt_en.name, cnt.name_default) as country,
-			c.country_code,
-			c.population
-		FROM cities c
-		JOIN countries cnt ON c.country_code = cnt.code
-		LEFT JOIN city_translations ct ON c.id = ct.city_id AND ct.lang = $2
-		LEFT JOIN city_translations ct_en ON c.id = ct_en.city_id AND ct_en.lang = 'en'
-		LEFT JOIN country_translations cnt_t ON cnt.code = cnt_t.country_code AND cnt_t.lang = $2
-		LEFT JOIN country_translations cnt_en ON cnt.code = cnt_en.country_code AND cnt_en.lang = 'en'
-		WHERE 
-			unaccent(LOWER(c.name_default)) LIKE '%' || unaccent(LOWER($1)) || '%'
-			OR 
-			EXISTS (
-				SELECT 1 FROM city_translations search_ct 
-				WHERE search_ct.city_id = c.id 
-				AND unaccent(LOWER(search_ct.name)) LIKE '%' || unaccent(LOWER($1)) || '%'
-			)
-		ORDER BY c.population DESC
-		LIMIT $3
+		SELECT ` + cityColumnList("c") + `, c.distance, ` + localizedCityColumns + `
+		FROM (` + nearest + `) AS c
+		` + localizedCityJoins("$5") + `
+		ORDER BY c.distance ASC, c.id ASC
+	`
+
+	var cities []model.CityWithDistance
+	if err := r.db.SelectContext(ctx, &cities, q, args...); err != nil {
+		return nil, err
+	}
+	return cities, nil
+}
+
+// usePostGIS reports whether the optional PostGIS geom column (migration
+// 000003) exists. Only a successful probe is cached; if the probe fails the
+// portable formula is used and the next query probes again.
+func (r *pgCityRepository) usePostGIS(ctx context.Context) bool {
+	r.postgisMu.Lock()
+	defer r.postgisMu.Unlock()
+	if r.postgis != nil {
+		return *r.postgis
+	}
+
+	q := `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'cities' AND column_name = 'geom'
+		)
+	`
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, q); err != nil {
+		return false
+	}
+	r.postgis = &exists
+	return exists
+}
+
+// resetPostGIS forgets the probe after a reload swapped in tables that may
+// have been migrated differently
+func (r *pgCityRepository) resetPostGIS() {
+	r.postgisMu.Lock()
+	r.postgis = nil
+	r.postgisMu.Unlock()
+}
+
+func (r *pgCityRepository) warmUp(ctx context.Context) error {
+	r.usePostGIS(ctx)
+	return nil
+}
+
+func (r *pgCityRepository) FindCitiesWithin(ctx context.Context, req model.WithinRequest) ([]model.CityResult, error) {
+	orderBy := "distance ASC, id ASC"
+	if req.SortBy == model.SortByPopulation {
+		orderBy = "population DESC, distance ASC, id ASC"
+	}
+
+	box := boundingBox(req.Lat, req.Lon, req.RadiusKm)
+	boxCond, boxArgs := pgBoxCondition("c", box, 6)
+	args := append([]interface{}{req.Lat, req.Lon, req.RadiusKm, req.Lang, req.Limit}, boxArgs...)
+
+	after := ""
+	if req.After != nil {
+		// Keyset pagination: resume strictly after the last row of the previous
+		// page in ORDER BY order
+		args = append(args, req.After.Distance, req.After.ID)
+		distance, id := len(args)-1, len(args)
+		after = fmt.Sprintf(" AND (distance > $%[1]d OR (distance = $%[1]d AND id > $%[2]d))", distance, id)
+		if req.SortBy == model.SortByPopulation {
+			args = append(args, req.After.Population)
+			after = fmt.Sprintf(" AND (population < $%[1]d OR (population = $%[1]d%[2]s))", len(args), after)
+		}
+	}
+
+	q := `
+		SELECT * FROM (
+			SELECT 
+				c.id,
+				` + localizedCityColumns + `,
+				c.country_code,
+				c.population,
+				c.lat AS "coordinates.lat",
+				c.lon AS "coordinates.lon",
+				` + pgDistanceSQL + ` AS distance
+			FROM cities c
+			` + localizedCityJoins("$4") + `
+			WHERE ` + boxCond + `
+		) AS candidates
+		WHERE distance <= $3` + after + `
+		ORDER BY ` + orderBy + `
+		LIMIT $5
 	`
+
 	var results []model.CityResult
-	if err := r.db.SelectContext(ctx, &results, q, query, lang, limit); err != nil {
+	if err := r.db.SelectContext(ctx, &results, q, args...); err != nil {
 		return nil, err
 	}
 	return results, nil
 }
 
-func (r *pgCityRepository) FindNearestCity(ctx context.Context, lat, lon float64) (*model.City, float64, error) {
-	// Haversine via SQL
+func (r *pgCityRepository) FindCitiesInBBox(ctx context.Context, req model.BBoxRequest) ([]model.CityResult, error) {
+	box := geoBox{MinLat: req.MinLat, MaxLat: req.MaxLat, MinLon: req.MinLon, MaxLon: req.MaxLon}
+	boxCond, boxArgs := pgBoxCondition("c", box, 4)
+
 	q := `
 		SELECT 
-			*,
-			(
-				6371 * acos(
-					least(1.0, greatest(-1.0,
-						cos(radians($1)) * cos(radians(lat)) * cos(radians(lon) - radians($2)) +
-						sin(radians($1)) * sin(radians(lat))
-					))
-				)
-			) AS distance
-		FROM cities
-		ORDER BY distance ASC
-		LIMIT 1
+			c.id,
+			` + localizedCityColumns + `,
+			c.country_code,
+			c.population,
+			c.lat AS "coordinates.lat",
+			c.lon AS "coordinates.lon"
+		FROM cities c
+		` + localizedCityJoins("$1") + `
+		WHERE c.population >= $2 AND ` + boxCond + `
+		ORDER BY c.population DESC, c.id ASC
+		LIMIT $3
 	`
-	// We need a struct that includes Distance to scan into, or scan manually.
-	// model.City doesn't have Distance. Let's use a temporary struct.
-	type cityWithDist struct {
-		model.City
-		Distance float64 `db:"distance"`
+	args := append([]interface{}{req.Lang, req.MinPopulation, req.Limit}, boxArgs...)
+
+	var results []model.CityResult
+	if err := r.db.SelectContext(ctx, &results, q, args...); err != nil {
+		return nil, err
 	}
+	return results, nil
+}
 
-	var res cityWithDist
-	if err := r.db.GetContext(ctx, &res, q, lat, lon); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, 0, nil
-		}
-		return nil, 0, err
+// pgBoxCondition renders a WHERE clause matching rows of the table aliased
+// as alias inside the box, numbering its placeholders from firstArg
+func pgBoxCondition(alias string, box geoBox, firstArg int) (string, []interface{}) {
+	op := "AND"
+	if box.crossesAntimeridian() {
+		op = "OR"
 	}
-	return &res.City, res.Distance, nil
+	cond := fmt.Sprintf("%[1]s.lat BETWEEN $%[2]d AND $%[3]d AND (%[1]s.lon >= $%[4]d %[5]s %[1]s.lon <= $%[6]d)",
+		alias, firstArg, firstArg+1, firstArg+2, op, firstArg+3)
+	return cond, []interface{}{box.MinLat, box.MaxLat, box.MinLon, box.MaxLon}
 }
 
 func (r *pgCityRepository) GetCityByID(ctx context.Context, id int) (*model.City, error) {
 	var city model.City
-	if err := r.db.GetContext(ctx, &city, "SELECT * FROM cities WHERE id = $1", id); err != nil {
+	if err := r.db.GetContext(ctx, &city, "SELECT "+cityColumnList("")+" FROM cities WHERE id = $1", id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -127,8 +304,24 @@ func (r *pgCityRepository) GetCityName(ctx context.Context, cityID int, lang str
 	return name, nil
 }
 
+func (r *pgCityRepository) GetCityAlternateNames(ctx context.Context, cityID int) ([]model.CityAlternateName, error) {
+	var names []model.CityAlternateName
+	if err := r.db.SelectContext(ctx, &names, r.db.Rebind(cityAlternateNameSelectSQL), cityID); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
 func (r *pgCityRepository) BulkInsertCities(ctx context.Context, cities []model.City) error {
-	// Chunking to avoid parameter limit issues even in PG (max 65535 parameters)
+	if len(cities) >= pgCopyMinRows {
+		err := pgCopyUpsert(ctx, r.db, "cities", cityColumns, cityCopyRows(cities),
+			onConflictUpdateSQL(cityColumns[:1], cityColumns[1:]))
+		if !errors.Is(err, errNotPgx) {
+			return err
+		}
+	}
+
+	// Chunking to avoid parameter limit issues even in PG (max 65535 parameters, 14 per city)
 	chunkSize := 2000
 	for i := 0; i < len(cities); i += chunkSize {
 		end := i + chunkSize
@@ -137,10 +330,7 @@ func (r *pgCityRepository) BulkInsertCities(ctx context.Context, cities []model.
 		}
 		batch := cities[i:end]
 
-		_, err := r.db.NamedExecContext(ctx, `
-		INSERT INTO cities (id, country_code, name_default, population, lat, lon, elevation, timezone)
-		VALUES (:id, :country_code, :name_default, :population, :lat, :lon, :elevation, :timezone)`,
-			batch)
+		_, err := r.db.NamedExecContext(ctx, cityUpsertSQL, batch)
 		if err != nil {
 			return err
 		}
@@ -168,18 +358,118 @@ func (r *pgCountryRepository) GetCountryName(ctx context.Context, countryCode st
 }
 
 func (r *pgCountryRepository) BulkInsertCountries(ctx context.Context, countries []model.Country) error {
-	_, err := r.db.NamedExecContext(ctx, `
-		INSERT INTO countries (code, name_default)
-		VALUES (:code, :name_default)`,
-		countries)
+	if len(countries) == 0 {
+		return nil
+	}
+	_, err := r.db.NamedExecContext(ctx, countryUpsertSQL, countries)
+	return err
+}
+
+func (r *pgCountryRepository) ListCountries(ctx context.Context, lang string) ([]model.CountryWithName, error) {
+	var countries []model.CountryWithName
+	if err := r.db.SelectContext(ctx, &countries, countrySelectSQL("$1")+" ORDER BY cnt.code", lang); err != nil {
+		return nil, err
+	}
+	return countries, nil
+}
+
+func (r *pgCountryRepository) GetCountry(ctx context.Context, code string, lang string) (*model.CountryWithName, error) {
+	var country model.CountryWithName
+	err := r.db.GetContext(ctx, &country, countrySelectSQL("$1")+" WHERE cnt.code = $2", lang, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &country, nil
+}
+
+func (r *pgCountryRepository) GetAdminDivisionName(ctx context.Context, code string, lang string) (string, error) {
+	q := `
+		SELECT COALESCE(
+			(SELECT name FROM admin_division_translations WHERE division_code = $1 AND lang = $2),
+			(SELECT name FROM admin_division_translations WHERE division_code = $1 AND lang = 'en'),
+			(SELECT name_default FROM admin_divisions WHERE code = $1),
+			''
+		)
+	`
+	var name string
+	if err := r.db.GetContext(ctx, &name, q, code, lang); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (r *pgCountryRepository) BulkInsertAdminDivisions(ctx context.Context, divisions []model.AdminDivision) error {
+	chunkSize := 1000
+	for i := 0; i < len(divisions); i += chunkSize {
+		end := i + chunkSize
+		if end > len(divisions) {
+			end = len(divisions)
+		}
+		batch := divisions[i:end]
+
+		q := `INSERT INTO admin_divisions (code, country_code, level, name_default, geoname_id)
+			  VALUES (:code, :country_code, :level, :name_default, :geoname_id)
+			  ON CONFLICT (code) DO UPDATE SET name_default = EXCLUDED.name_default, level = EXCLUDED.level,
+				geoname_id = EXCLUDED.geoname_id`
+
+		if _, err := r.db.NamedExecContext(ctx, q, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *pgCountryRepository) BulkInsertContinents(ctx context.Context, continents []model.Continent) error {
+	if len(continents) == 0 {
+		return nil
+	}
+	q := `INSERT INTO continents (code, geoname_id, name_default)
+		  VALUES (:code, :geoname_id, :name_default)
+		  ON CONFLICT (code) DO UPDATE SET geoname_id = EXCLUDED.geoname_id, name_default = EXCLUDED.name_default`
+	_, err := r.db.NamedExecContext(ctx, q, continents)
 	return err
 }
 
+func (r *pgCountryRepository) GetPlaceAncestors(ctx context.Context, geonameID int, lang string) ([]model.Place, error) {
+	return placeAncestors(ctx, r.db, geonameID, lang)
+}
+
+func (r *pgCountryRepository) BulkInsertHierarchy(ctx context.Context, edges []model.HierarchyEdge) error {
+	chunkSize := 5000
+	for i := 0; i < len(edges); i += chunkSize {
+		end := i + chunkSize
+		if end > len(edges) {
+			end = len(edges)
+		}
+		batch := edges[i:end]
+
+		q := `INSERT INTO hierarchy (parent_id, child_id, type)
+			  VALUES (:parent_id, :child_id, :type)
+			  ON CONFLICT (child_id, parent_id) DO NOTHING`
+
+		if _, err := r.db.NamedExecContext(ctx, q, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type pgTranslationRepository struct {
 	db *sqlx.DB
 }
 
 func (r *pgTranslationRepository) BulkInsertCityTranslations(ctx context.Context, translations []model.CityTranslation) error {
+	if len(translations) >= pgCopyMinRows {
+		err := pgCopyUpsert(ctx, r.db, "city_translations", cityTranslationColumns,
+			cityTranslationCopyRows(translations), onConflictUpdateSQL(cityTranslationColumns[:2], cityTranslationColumns[2:]))
+		if !errors.Is(err, errNotPgx) {
+			return err
+		}
+	}
+
 	// Chunking to avoid parameter limit issues
 	chunkSize := 1000
 	for i := 0; i < len(translations); i += chunkSize {
@@ -220,6 +510,66 @@ func (r *pgTranslationRepository) BulkInsertCountryTranslations(ctx context.Cont
 	return nil
 }
 
+func (r *pgTranslationRepository) BulkInsertAdminDivisionTranslations(ctx context.Context, translations []model.AdminDivisionTranslation) error {
+	chunkSize := 1000
+	for i := 0; i < len(translations); i += chunkSize {
+		end := i + chunkSize
+		if end > len(translations) {
+			end = len(translations)
+		}
+		batch := translations[i:end]
+
+		q := `INSERT INTO admin_division_translations (division_code, lang, name)
+			  VALUES (:division_code, :lang, :name)
+			  ON CONFLICT (division_code, lang) DO UPDATE SET name = EXCLUDED.name`
+
+		if _, err := r.db.NamedExecContext(ctx, q, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *pgTranslationRepository) BulkInsertContinentTranslations(ctx context.Context, translations []model.ContinentTranslation) error {
+	chunkSize := 1000
+	for i := 0; i < len(translations); i += chunkSize {
+		end := i + chunkSize
+		if end > len(translations) {
+			end = len(translations)
+		}
+		batch := translations[i:end]
+
+		q := `INSERT INTO continent_translations (continent_code, lang, name)
+			  VALUES (:continent_code, :lang, :name)
+			  ON CONFLICT (continent_code, lang) DO UPDATE SET name = EXCLUDED.name`
+
+		if _, err := r.db.NamedExecContext(ctx, q, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *pgTranslationRepository) BulkInsertCityAlternateNames(ctx context.Context, names []model.CityAlternateName) error {
+	// 8 params per name
+	chunkSize := 5000
+	q := cityAlternateNameInsertSQL + `
+		ON CONFLICT (id) DO UPDATE SET city_id = EXCLUDED.city_id, lang = EXCLUDED.lang, name = EXCLUDED.name,
+			is_preferred = EXCLUDED.is_preferred, is_short = EXCLUDED.is_short,
+			is_colloquial = EXCLUDED.is_colloquial, is_historic = EXCLUDED.is_historic`
+	for i := 0; i < len(names); i += chunkSize {
+		end := i + chunkSize
+		if end > len(names) {
+			end = len(names)
+		}
+
+		if _, err := r.db.NamedExecContext(ctx, q, names[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *pgTranslationRepository) GetAvailableLanguages(ctx context.Context) ([]string, error) {
 	q := `SELECT DISTINCT lang FROM (
 			SELECT lang FROM city_translations
@@ -232,3 +582,57 @@ func (r *pgTranslationRepository) GetAvailableLanguages(ctx context.Context) ([]
 	}
 	return langs, nil
 }
+
+type pgPostalCodeRepository struct {
+	db *sqlx.DB
+}
+
+func (r *pgPostalCodeRepository) FindPostalCodes(ctx context.Context, countryCode, postalCode string) ([]model.PostalCode, error) {
+	q := postalCodeSelectSQL + " WHERE country_code = $1 AND postal_code = $2 ORDER BY place_name, id"
+
+	var codes []model.PostalCode
+	if err := r.db.SelectContext(ctx, &codes, q, countryCode, postalCode); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (r *pgPostalCodeRepository) FindPostalCodesNear(ctx context.Context, req model.PostalNearestRequest) ([]model.PostalCodeWithDistance, error) {
+	box := boundingBox(req.Lat, req.Lon, req.RadiusKm)
+	boxCond, boxArgs := pgBoxCondition("p", box, 5)
+
+	q := `
+		SELECT * FROM (
+			SELECT
+				p.id, p.` + strings.Join(postalCodeColumns, ", p.") + `,
+				` + pgDistanceSQL + ` AS distance
+			FROM postal_codes p
+			WHERE ` + boxCond + `
+		) AS candidates
+		WHERE distance <= $3
+		ORDER BY distance ASC, id ASC
+		LIMIT $4
+	`
+	args := append([]interface{}{req.Lat, req.Lon, req.RadiusKm, req.Limit}, boxArgs...)
+
+	var results []model.PostalCodeWithDistance
+	if err := r.db.SelectContext(ctx, &results, q, args...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *pgPostalCodeRepository) BulkInsertPostalCodes(ctx context.Context, codes []model.PostalCode) error {
+	// Chunking to stay below the parameter limit (11 per postal code)
+	chunkSize := 2000
+	for i := 0; i < len(codes); i += chunkSize {
+		end := i + chunkSize
+		if end > len(codes) {
+			end = len(codes)
+		}
+		if _, err := r.db.NamedExecContext(ctx, postalCodeInsertSQL, codes[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexivanou/geocity-api/internal/config"
+	"github.com/jmoiron/sqlx"
+)
+
+// DataTables are the tables a seed writes, referenced tables first. A reload
+// swaps all of them, so the data date and seed state travel with the data.
+var DataTables = []string{
+	"countries", "continents", "admin_divisions", "cities", "hierarchy", "postal_codes",
+	"country_translations", "continent_translations", "admin_division_translations",
+	"city_translations", "city_alternate_names", "data_updates", "seed_state",
+}
+
+// CountRows returns the number of rows of each data table of db
+func CountRows(ctx context.Context, db *sqlx.DB) (map[string]int64, error) {
+	counts := make(map[string]int64, len(DataTables))
+	for _, table := range DataTables {
+		var count int64
+		if err := db.GetContext(ctx, &count, "SELECT COUNT(*) FROM "+table); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
+
+// NewReloadRepository creates the ReloadRepository of the live database db,
+// connected with cfg. Swaps drop the in-memory indexes of repos.
+func NewReloadRepository(db *sqlx.DB, cfg config.DBConfig, repos *Container) ReloadRepository {
+	if cfg.Type == config.DBTypePostgreSQL {
+		return &pgReloadRepository{db: db, dataChanged: repos.dataChanged}
+	}
+	return &sqliteReloadRepository{db: db, stagingDSN: cfg.Staging().DSN(), dataChanged: repos.dataChanged}
+}
+
+// pgRetiredSchema holds the replaced live tables during a swap
+const pgRetiredSchema = "geocity_retired"
+
+// pgReloadRepository swaps the tables of config.StagingSchema into public
+type pgReloadRepository struct {
+	db *sqlx.DB
+	// dataChanged is called after a swap, e.g. to drop caches
+	dataChanged func()
+}
+
+func (r *pgReloadRepository) PrepareStaging(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+config.StagingSchema)
+	return err
+}
+
+// SwapStaging moves the live tables out of public and the staged ones in.
+// The functions the migrations created in the staging schema, which the
+// staged indexes and triggers use, move along with them. References between
+// tables survive the moves, so only the renames lock the live tables.
+func (r *pgReloadRepository) SwapStaging(ctx context.Context) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Fail rather than queue requests behind a long-running query
+	statements := []string{
+		"SET LOCAL lock_timeout = '10s'",
+		"DROP SCHEMA IF EXISTS " + pgRetiredSchema + " CASCADE",
+		"CREATE SCHEMA " + pgRetiredSchema,
+	}
+	for _, table := range DataTables {
+		statements = append(statements,
+			"ALTER TABLE public."+table+" SET SCHEMA "+pgRetiredSchema,
+			"ALTER TABLE "+config.StagingSchema+"."+table+" SET SCHEMA public")
+	}
+	for _, q := range statements {
+		if _, err := tx.ExecContext(ctx, q); err != nil {
+			return fmt.Errorf("failed to swap tables: %w", err)
+		}
+	}
+
+	var functions []struct {
+		Name      string `db:"name"`
+		Signature string `db:"signature"`
+	}
+	err = tx.SelectContext(ctx, &functions, `
+		SELECT p.proname AS name, pg_get_function_identity_arguments(p.oid) AS signature
+		FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname = $1`, config.StagingSchema)
+	if err != nil {
+		return fmt.Errorf("failed to list staging functions: %w", err)
+	}
+	for _, fn := range functions {
+		var live bool
+		if err := tx.GetContext(ctx, &live, `
+			SELECT EXISTS (SELECT 1 FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace
+				WHERE n.nspname = 'public' AND p.proname = $1 AND pg_get_function_identity_arguments(p.oid) = $2)`,
+			fn.Name, fn.Signature); err != nil {
+			return fmt.Errorf("failed to look up function %s: %w", fn.Name, err)
+		}
+		if live {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER FUNCTION public.%s(%s) SET SCHEMA %s",
+				fn.Name, fn.Signature, pgRetiredSchema)); err != nil {
+				return fmt.Errorf("failed to retire function %s: %w", fn.Name, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER FUNCTION %s.%s(%s) SET SCHEMA public",
+			config.StagingSchema, fn.Name, fn.Signature)); err != nil {
+			return fmt.Errorf("failed to move function %s: %w", fn.Name, err)
+		}
+	}
+
+	for _, schema := range []string{pgRetiredSchema, config.StagingSchema} {
+		if _, err := tx.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			return fmt.Errorf("failed to drop %s: %w", schema, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.dataChanged()
+	return nil
+}
+
+func (r *pgReloadRepository) DropStaging(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DROP SCHEMA IF EXISTS "+config.StagingSchema+" CASCADE")
+	return err
+}
+
+// sqliteReloadRepository copies the tables of a separate staging database
+// over the live ones
+type sqliteReloadRepository struct {
+	db *sqlx.DB
+	// stagingDSN locates the staging database for ATTACH
+	stagingDSN string
+	// dataChanged is called after a swap, e.g. to drop in-memory indexes
+	dataChanged func()
+}
+
+// PrepareStaging does nothing: the staging database is created by
+// connecting to it
+func (r *sqliteReloadRepository) PrepareStaging(ctx context.Context) error {
+	return nil
+}
+
+// SwapStaging replaces the rows of the live tables with the staged ones in
+// one transaction. Readers keep seeing the old rows until it commits.
+func (r *sqliteReloadRepository) SwapStaging(ctx context.Context) error {
+	// ATTACH is not allowed inside a transaction and only applies to the
+	// connection it runs on
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS staging", r.stagingDSN); err != nil {
+		return fmt.Errorf("failed to attach staging database: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE staging")
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := len(DataTables) - 1; i >= 0; i-- {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM main."+DataTables[i]); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", DataTables[i], err)
+		}
+	}
+	// Both databases ran the same migrations, so the columns line up
+	for _, table := range DataTables {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO main."+table+" SELECT * FROM staging."+table); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.dataChanged()
+	return nil
+}
+
+// DropStaging does nothing: the in-memory staging database is dropped with
+// its last connection
+func (r *sqliteReloadRepository) DropStaging(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/config"
+	"github.com/alexivanou/geocity-api/internal/database"
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectMigrated connects to a new in-memory database and migrates it
+func connectMigrated(t *testing.T, cfg config.DBConfig) *sqlx.DB {
+	t.Helper()
+	db, err := database.Connect(context.Background(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate(db, cfg, "../../migrations"))
+	return db
+}
+
+func TestReloadRepository_SwapStaging(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DBConfig{Type: config.DBTypeMemory, Name: "reload_test"}
+	live := connectMigrated(t, cfg)
+	defer live.Close()
+	staging := connectMigrated(t, cfg.Staging())
+	defer staging.Close()
+
+	repos := NewRepositories(live, cfg.Type)
+	require.NoError(t, repos.Country.BulkInsertCountries(ctx, []model.Country{{Code: "DE", NameDefault: "Germany"}}))
+	require.NoError(t, repos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 1, CountryCode: "DE", NameDefault: "Berlin", Population: 3600000, Lat: 52.52, Lon: 13.405, FeatureClass: "P", FeatureCode: "PPLC"},
+		{ID: 2, CountryCode: "DE", NameDefault: "Potsdam", Population: 180000, Lat: 52.3967, Lon: 13.0583, FeatureClass: "P", FeatureCode: "PPLA"},
+	}))
+	require.NoError(t, repos.Translation.BulkInsertCityTranslations(ctx, []model.CityTranslation{{CityID: 1, Lang: "fr", Name: "Berlin"}}))
+
+	// Builds the spatial index over the live cities
+	nearest, _, err := repos.City.FindNearestCity(ctx, 48.14, 11.58)
+	require.NoError(t, err)
+	require.NotNil(t, nearest)
+
+	stagingRepos := NewRepositories(staging, cfg.Type)
+	require.NoError(t, stagingRepos.Country.BulkInsertCountries(ctx, []model.Country{{Code: "DE", NameDefault: "Deutschland"}}))
+	require.NoError(t, stagingRepos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 3, CountryCode: "DE", NameDefault: "München", Population: 1500000, Lat: 48.137, Lon: 11.575, FeatureClass: "P", FeatureCode: "PPLA"},
+	}))
+	require.NoError(t, stagingRepos.Translation.BulkInsertCityTranslations(ctx, []model.CityTranslation{{CityID: 3, Lang: "en", Name: "Munich"}}))
+	require.NoError(t, stagingRepos.SeedState.SaveCheckpoint(ctx, model.SeedCheckpoint{Phase: "cities", Offset: 1, Completed: true}))
+
+	counts, err := CountRows(ctx, staging)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), counts["cities"])
+	assert.Equal(t, int64(1), counts["city_translations"])
+	assert.Equal(t, int64(0), counts["postal_codes"])
+
+	var changed int
+	repos.changed.add(func() { changed++ })
+	reload := NewReloadRepository(live, cfg, repos)
+	require.NoError(t, reload.PrepareStaging(ctx))
+	require.NoError(t, reload.SwapStaging(ctx))
+	assert.Equal(t, 1, changed, "callbacks run after the swap")
+
+	counts, err = CountRows(ctx, live)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), counts["cities"])
+	assert.Equal(t, int64(1), counts["seed_state"], "the seed state travels with the data")
+
+	city, err := repos.City.GetCityByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, city, "the live cities are replaced")
+
+	name, err := repos.City.GetCityName(ctx, 3, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Munich", name)
+
+	nearest, _, err = repos.City.FindNearestCity(ctx, 48.14, 11.58)
+	require.NoError(t, err)
+	require.NotNil(t, nearest)
+	assert.Equal(t, 3, nearest.ID, "the spatial index is rebuilt")
+}
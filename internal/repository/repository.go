@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"sync"
 
 	"github.com/alexivanou/geocity-api/internal/config"
 	"github.com/alexivanou/geocity-api/internal/model"
@@ -11,10 +13,16 @@ import (
 // CityRepository defines operations for cities
 type CityRepository interface {
 	SearchCities(ctx context.Context, query string, limit int) ([]model.City, error)
-	SearchCitiesWithLang(ctx context.Context, query string, lang string, limit int) ([]model.CityResult, error)
+	SearchCitiesWithLang(ctx context.Context, req model.SuggestRequest) ([]model.CityResult, error)
 	FindNearestCity(ctx context.Context, lat, lon float64) (*model.City, float64, error)
+	FindNearestCities(ctx context.Context, req model.NearestRequest) ([]model.CityWithDistance, error)
+	FindCitiesWithin(ctx context.Context, req model.WithinRequest) ([]model.CityResult, error)
+	FindCitiesInBBox(ctx context.Context, req model.BBoxRequest) ([]model.CityResult, error)
 	GetCityByID(ctx context.Context, id int) (*model.City, error)
 	GetCityName(ctx context.Context, cityID int, lang string) (string, error)
+	// GetCityAlternateNames returns every stored name of a city, ordered by
+	// language with preferred and short names first
+	GetCityAlternateNames(ctx context.Context, cityID int) ([]model.CityAlternateName, error)
 	BulkInsertCities(ctx context.Context, cities []model.City) error
 }
 
@@ -22,38 +30,262 @@ type CityRepository interface {
 type CountryRepository interface {
 	GetCountryName(ctx context.Context, countryCode string, lang string) (string, error)
 	BulkInsertCountries(ctx context.Context, countries []model.Country) error
+	// ListCountries returns all countries with names in lang, ordered by code
+	ListCountries(ctx context.Context, lang string) ([]model.CountryWithName, error)
+	// GetCountry returns a country with its name in lang, or nil if unknown
+	GetCountry(ctx context.Context, code string, lang string) (*model.CountryWithName, error)
+	// GetAdminDivisionName returns the localized name of an admin1/admin2
+	// division ("US.IL"), or "" if the division is unknown
+	GetAdminDivisionName(ctx context.Context, code string, lang string) (string, error)
+	BulkInsertAdminDivisions(ctx context.Context, divisions []model.AdminDivision) error
+	BulkInsertContinents(ctx context.Context, continents []model.Continent) error
+	// GetPlaceAncestors walks hierarchy.txt up from a geonameid and returns
+	// the known ancestors with names in lang, nearest first
+	GetPlaceAncestors(ctx context.Context, geonameID int, lang string) ([]model.Place, error)
+	BulkInsertHierarchy(ctx context.Context, edges []model.HierarchyEdge) error
 }
 
 // TranslationRepository defines operations for translations
 type TranslationRepository interface {
 	BulkInsertCityTranslations(ctx context.Context, translations []model.CityTranslation) error
 	BulkInsertCountryTranslations(ctx context.Context, translations []model.CountryTranslation) error
+	BulkInsertAdminDivisionTranslations(ctx context.Context, translations []model.AdminDivisionTranslation) error
+	BulkInsertContinentTranslations(ctx context.Context, translations []model.ContinentTranslation) error
+	BulkInsertCityAlternateNames(ctx context.Context, names []model.CityAlternateName) error
 	GetAvailableLanguages(ctx context.Context) ([]string, error)
 }
 
+// PostalCodeRepository defines operations for postal codes
+type PostalCodeRepository interface {
+	// FindPostalCodes returns the places covered by a postal code, ordered by name
+	FindPostalCodes(ctx context.Context, countryCode, postalCode string) ([]model.PostalCode, error)
+	// FindPostalCodesNear returns up to req.Limit postal codes within
+	// req.RadiusKm of a point, nearest first
+	FindPostalCodesNear(ctx context.Context, req model.PostalNearestRequest) ([]model.PostalCodeWithDistance, error)
+	BulkInsertPostalCodes(ctx context.Context, codes []model.PostalCode) error
+}
+
+// UpdateRepository applies GeoNames daily updates and records the date the
+// data is current as of
+type UpdateRepository interface {
+	// LastUpdate returns the date (YYYY-MM-DD) of the latest recorded dump
+	// import or daily update, or "" if none was recorded
+	LastUpdate(ctx context.Context) (string, error)
+	// RecordUpdate records a dump import or update, replacing a record of
+	// the same date
+	RecordUpdate(ctx context.Context, update model.DataUpdate) error
+	// ApplyDailyUpdate upserts and deletes the cities and city names of a
+	// day in a single transaction, refreshes the affected display names and
+	// records the update. Changes to places that are not stored cities are
+	// ignored.
+	ApplyDailyUpdate(ctx context.Context, update model.DailyUpdate) (*model.DataUpdate, error)
+	// DataVersion returns a token that changes whenever a seed, reload or
+	// daily update changed the data, including one run by another process
+	DataVersion(ctx context.Context) (string, error)
+}
+
+// SeedStateRepository stores the checkpoints of an ongoing or finished seed
+type SeedStateRepository interface {
+	// GetCheckpoints returns the checkpoints of the phases that were
+	// started, by phase
+	GetCheckpoints(ctx context.Context) (map[string]model.SeedCheckpoint, error)
+	// SaveCheckpoint records a phase's checkpoint, replacing the previous one
+	SaveCheckpoint(ctx context.Context, checkpoint model.SeedCheckpoint) error
+	// ClearCheckpoints forgets all checkpoints, so that the next seed starts over
+	ClearCheckpoints(ctx context.Context) error
+}
+
+// ReloadRepository swaps a reload, seeded into a staging copy of the data
+// tables (see config.DBConfig.Staging), in for the live tables
+type ReloadRepository interface {
+	// PrepareStaging creates what the staging database needs before its
+	// migrations run
+	PrepareStaging(ctx context.Context) error
+	// SwapStaging replaces the live data tables with the staged ones in a
+	// single transaction and drops the staging copy
+	SwapStaging(ctx context.Context) error
+	// DropStaging drops the staging copy, e.g. after it failed validation
+	DropStaging(ctx context.Context) error
+}
+
 // Container holds all repositories
 type Container struct {
 	City        CityRepository
 	Country     CountryRepository
 	Translation TranslationRepository
+	PostalCode  PostalCodeRepository
+	Update      UpdateRepository
+	SeedState   SeedStateRepository
+
+	// changed holds the callbacks that drop the in-memory state of the
+	// repositories after this process reloaded or updated the data
+	changed *changeHooks
+}
+
+// dataChanged runs the callbacks of changed
+func (c *Container) dataChanged() {
+	if c.changed != nil {
+		c.changed.notify()
+	}
+}
+
+// changeHooks is a list of callbacks safe for concurrent use
+type changeHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+func (h *changeHooks) add(fn func()) {
+	h.mu.Lock()
+	h.fns = append(h.fns, fn)
+	h.mu.Unlock()
+}
+
+func (h *changeHooks) notify() {
+	h.mu.Lock()
+	fns := append([]func(){}, h.fns...)
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// cityColumns are the cities columns scanned into model.City. Queries list
+// them explicitly because the table may carry columns the model has no field
+// for, such as the optional PostGIS geom column.
+var cityColumns = []string{
+	"id", "country_code", "name_default", "population", "lat", "lon", "elevation", "timezone",
+	"admin1_code", "admin2_code", "admin3_code", "admin4_code", "feature_class", "feature_code",
+}
+
+// countryColumns are the countries columns scanned into model.Country
+var countryColumns = []string{
+	"code", "name_default", "geoname_id", "iso3", "iso_numeric", "capital", "area_sq_km", "population",
+	"continent", "tld", "currency_code", "currency_name", "phone", "postal_code_format", "postal_code_regex",
+	"languages", "neighbours",
+}
+
+// postalCodeColumns are the postal_codes columns of model.PostalCode besides id
+var postalCodeColumns = []string{
+	"country_code", "postal_code", "place_name", "admin1_name", "admin1_code", "admin2_name", "admin2_code",
+	"lat", "lon", "accuracy",
+}
+
+// cityAlternateNameColumns are the city_alternate_names columns of model.CityAlternateName
+var cityAlternateNameColumns = []string{
+	"id", "city_id", "lang", "name", "is_preferred", "is_short", "is_colloquial", "is_historic",
+}
+
+// cityColumnList renders cityColumns for a SELECT, qualified with alias if set
+func cityColumnList(alias string) string {
+	if alias == "" {
+		return strings.Join(cityColumns, ", ")
+	}
+	return alias + "." + strings.Join(cityColumns, ", "+alias+".")
+}
+
+// upsertSQL inserts into table by columns as named parameters, overwriting
+// the rows whose first column, the key, exists. The ON CONFLICT clause is
+// understood by SQLite and Postgres alike; unlike INSERT OR REPLACE it keeps
+// the rows referencing the overwritten ones. Seeding relies on it to import
+// a batch again after an interrupted run.
+func upsertSQL(table string, columns []string) string {
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (:" +
+		strings.Join(columns, ", :") + ") " + onConflictUpdateSQL(columns[:1], columns[1:])
+}
+
+// onConflictUpdateSQL is the ON CONFLICT clause overwriting the update
+// columns of the row with the same key columns
+func onConflictUpdateSQL(key, update []string) string {
+	set := make([]string, 0, len(update))
+	for _, column := range update {
+		set = append(set, column+" = excluded."+column)
+	}
+	return "ON CONFLICT (" + strings.Join(key, ", ") + ") DO UPDATE SET " + strings.Join(set, ", ")
+}
+
+// cityUpsertSQL inserts cities by cityColumns, overwriting existing ones
+var cityUpsertSQL = upsertSQL("cities", cityColumns)
+
+// countryUpsertSQL inserts countries by countryColumns, overwriting existing ones
+var countryUpsertSQL = upsertSQL("countries", countryColumns)
+
+// postalCodeInsertSQL inserts postal codes by id and postalCodeColumns as
+// named parameters. The id is the line of the code in the dump, so a line
+// imported again is skipped.
+var postalCodeInsertSQL = "INSERT INTO postal_codes (id, " + strings.Join(postalCodeColumns, ", ") +
+	") VALUES (:id, :" + strings.Join(postalCodeColumns, ", :") + ") ON CONFLICT (id) DO NOTHING"
+
+// postalCodeSelectSQL selects postal codes with their id
+var postalCodeSelectSQL = "SELECT id, " + strings.Join(postalCodeColumns, ", ") + " FROM postal_codes"
+
+// cityAlternateNameInsertSQL inserts alternate names by cityAlternateNameColumns as named parameters
+var cityAlternateNameInsertSQL = "INSERT INTO city_alternate_names (" + strings.Join(cityAlternateNameColumns, ", ") +
+	") VALUES (:" + strings.Join(cityAlternateNameColumns, ", :") + ")"
+
+// cityAlternateNameSelectSQL selects the names of the city bound to the only
+// placeholder, in the order GetCityAlternateNames documents
+var cityAlternateNameSelectSQL = "SELECT " + strings.Join(cityAlternateNameColumns, ", ") +
+	" FROM city_alternate_names WHERE city_id = ? ORDER BY lang, is_preferred DESC, is_short DESC, name, id"
+
+// countrySelectSQL selects countries with the name in the language bound to
+// lang, falling back to English and the default name like city names do
+func countrySelectSQL(lang string) string {
+	return `
+		SELECT cnt.` + strings.Join(countryColumns, ", cnt.") + `,
+			COALESCE(cnt_t.name, cnt_en.name, cnt.name_default) AS name
+		FROM countries cnt
+		LEFT JOIN country_translations cnt_t ON cnt.code = cnt_t.country_code AND cnt_t.lang = ` + lang + `
+		LEFT JOIN country_translations cnt_en ON cnt.code = cnt_en.country_code AND cnt_en.lang = 'en'`
 }
 
 // NewRepositories creates repository implementations based on DB type
 func NewRepositories(db *sqlx.DB, dbType config.DBType) *Container {
+	changed := &changeHooks{}
 	if dbType == config.DBTypePostgreSQL {
+		cityRepo := &pgCityRepository{db: db}
+		// A reload may swap in tables with or without the geom column
+		changed.add(cityRepo.resetPostGIS)
 		return &Container{
-			City:        &pgCityRepository{db: db},
+			City:        cityRepo,
 			Country:     &pgCountryRepository{db: db},
 			Translation: &pgTranslationRepository{db: db},
+			PostalCode:  &pgPostalCodeRepository{db: db},
+			Update:      &updateRepository{db: db, citiesChanged: changed.notify},
+			SeedState:   &seedStateRepository{db: db},
+			changed:     changed,
 		}
 	}
 
 	// Default to SQLite
+	cityRepo := &sqliteCityRepository{db: db}
+	// Updates and reloads move cities, so the spatial index must be rebuilt
+	changed.add(cityRepo.invalidateIndex)
 	return &Container{
-		City:        &sqliteCityRepository{db: db},
+		City:        cityRepo,
 		Country:     &sqliteCountryRepository{db: db},
 		Translation: &sqliteTranslationRepository{db: db},
+		PostalCode:  &sqlitePostalCodeRepository{db: db},
+		Update:      &updateRepository{db: db, citiesChanged: changed.notify},
+		SeedState:   &seedStateRepository{db: db},
+		changed:     changed,
+	}
+}
+
+// warmer is implemented by repositories that build in-memory indexes or probe
+// optional database features
+type warmer interface {
+	warmUp(ctx context.Context) error
+}
+
+// WarmUp builds in-memory indexes and detects optional database features
+// ahead of the first request (used by main once the database is seeded).
+// Both otherwise happen on first use.
+func WarmUp(ctx context.Context, repos *Container) error {
+	if w, ok := repos.City.(warmer); ok {
+		return w.warmUp(ctx)
 	}
+	return nil
 }
 
 // Helper to check if DB is empty (used by main)
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+)
+
+// Relevance tiers for autocomplete. A city is scored by its best matching
+// name: exact beats prefix, prefix beats word-prefix, word-prefix beats
+// substring, and substring beats a fuzzy (typo-tolerant) match. Tiers are
+// spaced widely so that boosts only reorder matches within a tier;
+// population is the tiebreaker after the score.
+const (
+	scoreExact      = 4000
+	scorePrefix     = 3000
+	scoreWordPrefix = 2000
+	scoreSubstring  = 1000
+
+	// scoreFuzzyMax is the score of a perfect fuzzy match; fuzzy matches are
+	// scaled by similarity and always rank below substring matches
+	scoreFuzzyMax = 500
+
+	// scoreLangBoost rewards a match on a name in the requested language
+	scoreLangBoost = 50
+
+	// scoreCapitalBoost lifts national capitals (feature code PPLC) above
+	// other matches of the same tier
+	scoreCapitalBoost = 100
+)
+
+// searchDialect captures the SQL differences between backends so that both
+// run exactly the same ranking query
+type searchDialect struct {
+	// placeholder renders the n-th (1-based) bind parameter
+	placeholder func(n int) string
+	// normalize wraps an SQL expression in case (and accent) folding
+	normalize func(expr string) string
+	// fuzzyMatch renders a predicate that is true when the normalized column
+	// is at least threshold similar to the normalized query
+	fuzzyMatch func(column, query, threshold string) string
+}
+
+// The trigram GIN indexes are built on immutable_unaccent(LOWER(name)), so
+// queries must normalize the same way to use them. The % operator lets the
+// index prefilter candidates at pg_trgm.similarity_threshold before the exact
+// threshold is applied; SearchCitiesWithLang sets that to the requested
+// threshold for the query's transaction.
+var pgSearchDialect = searchDialect{
+	placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	normalize:   func(expr string) string { return "immutable_unaccent(LOWER(" + expr + "))" },
+	fuzzyMatch: func(column, query, threshold string) string {
+		return "(" + column + " % " + query + " AND similarity(" + column + ", " + query + ") >= " + threshold + ")"
+	},
+}
+
+// SQLite numbered parameters (?NNN) can be referenced more than once, like $N
+// in Postgres. unaccent(), the Unicode-aware LOWER() and similarity() are Go
+// functions registered by database.Connect, so names fold exactly as they do
+// on Postgres.
+var sqliteSearchDialect = searchDialect{
+	placeholder: func(n int) string { return fmt.Sprintf("?%d", n) },
+	normalize:   func(expr string) string { return "unaccent(LOWER(" + expr + "))" },
+	fuzzyMatch: func(column, query, threshold string) string {
+		return "similarity(" + column + ", " + query + ") >= " + threshold
+	},
+}
+
+// buildSuggestQuery renders the ranked autocomplete query and its arguments
+func buildSuggestQuery(d searchDialect, req model.SuggestRequest) (string, []interface{}) {
+	n := d.normalize
+	var args []interface{}
+	// bind adds an argument and returns its placeholder
+	bind := func(value interface{}) string {
+		args = append(args, value)
+		return d.placeholder(len(args))
+	}
+
+	q := n(bind(req.Query))
+	lang := bind(req.Lang)
+
+	// Without fuzzy matching the extra predicates collapse to nothing and the
+	// fuzzy score is never reached, since every candidate contains the query
+	fuzzyDefault, fuzzyTranslation := "", ""
+	fuzzyScore := "0"
+	if req.Fuzzy {
+		threshold := bind(req.FuzzyThreshold)
+		fuzzyDefault = " OR " + d.fuzzyMatch(n("c.name_default"), q, threshold)
+		fuzzyTranslation = " OR " + d.fuzzyMatch(n("ct.name"), q, threshold)
+		fuzzyScore = fmt.Sprintf("CAST(similarity(%s, %s) * %d AS INTEGER)", n("matched_name"), q, scoreFuzzyMax)
+	}
+
+	// Historic and colloquial names only match on request, as aliases
+	aliases := ""
+	if req.IncludeAliases {
+		fuzzyAlias := ""
+		if req.Fuzzy {
+			fuzzyAlias = " OR " + d.fuzzyMatch(n("an.name"), q, bind(req.FuzzyThreshold))
+		}
+		aliases = strings.NewReplacer("{alias}", n("an.name"), "{q}", q, "{fuzzy_alias}", fuzzyAlias).Replace(`
+			UNION ALL
+			SELECT an.city_id, an.name, 0, 1, CASE WHEN an.is_historic THEN 1 ELSE 0 END
+			FROM city_alternate_names an
+			WHERE (an.is_historic OR an.is_colloquial) AND ({alias} LIKE '%' || {q} || '%'{fuzzy_alias})`)
+	}
+
+	filters := buildSuggestFilters(req, bind)
+	if req.After != nil {
+		// Keyset pagination: resume strictly after the last row of the previous
+		// page in ORDER BY order
+		score, population, id := bind(req.After.Score), bind(req.After.Population), bind(req.After.ID)
+		filters += fmt.Sprintf(
+			" AND (best.score < %[1]s OR (best.score = %[1]s AND (c.population < %[2]s OR (c.population = %[2]s AND c.id > %[3]s))))",
+			score, population, id,
+		)
+	}
+	limit := bind(req.Limit)
+
+	r := strings.NewReplacer(
+		"{q}", q,
+		"{lang}", lang,
+		"{limit}", limit,
+		"{filters}", filters,
+		"{region_columns}", regionColumns,
+		"{region_joins}", regionJoins(lang),
+		"{name_default}", n("c.name_default"),
+		"{translation}", n("ct.name"),
+		"{matched}", n("matched_name"),
+		"{fuzzy_default}", fuzzyDefault,
+		"{fuzzy_translation}", fuzzyTranslation,
+		"{fuzzy_score}", fuzzyScore,
+		"{aliases}", aliases,
+		"{exact}", fmt.Sprint(scoreExact),
+		"{prefix}", fmt.Sprint(scorePrefix),
+		"{word_prefix}", fmt.Sprint(scoreWordPrefix),
+		"{substring}", fmt.Sprint(scoreSubstring),
+		"{lang_boost}", fmt.Sprint(scoreLangBoost),
+		"{capital_boost}", fmt.Sprint(scoreCapitalBoost),
+	)
+
+	sql := r.Replace(`
+		WITH candidates AS (
+			SELECT c.id AS city_id, c.name_default AS matched_name, 0 AS lang_match, 0 AS is_alias, 0 AS is_historic
+			FROM cities c
+			WHERE {name_default} LIKE '%' || {q} || '%'{fuzzy_default}
+			UNION ALL
+			SELECT ct.city_id, ct.name, CASE WHEN ct.lang = {lang} THEN 1 ELSE 0 END, 0, 0
+			FROM city_translations ct
+			WHERE {translation} LIKE '%' || {q} || '%'{fuzzy_translation}{aliases}
+		),
+		scored AS (
+			SELECT
+				city_id,
+				matched_name,
+				is_alias,
+				is_historic,
+				CASE
+					WHEN {matched} = {q} THEN {exact}
+					WHEN {matched} LIKE {q} || '%' THEN {prefix}
+					WHEN {matched} LIKE '% ' || {q} || '%' OR {matched} LIKE '%-' || {q} || '%' THEN {word_prefix}
+					WHEN {matched} LIKE '%' || {q} || '%' THEN {substring}
+					ELSE {fuzzy_score}
+				END + lang_match * {lang_boost}
+					+ CASE WHEN cc.feature_code = 'PPLC' THEN {capital_boost} ELSE 0 END AS score
+			FROM candidates
+			JOIN cities cc ON cc.id = candidates.city_id
+		),
+		best AS (
+			SELECT
+				city_id,
+				matched_name,
+				is_alias,
+				is_historic,
+				score,
+				ROW_NUMBER() OVER (PARTITION BY city_id ORDER BY score DESC, is_alias, matched_name) AS rn
+			FROM scored
+		)
+		SELECT
+			c.id,
+			COALESCE(ct.name, ct_en.name, c.name_default) as name,
+			COALESCE(cnt_t.name, cnt_en.name, cnt.name_default) as country,
+			c.country_code,
+			{region_columns},
+			c.population,
+			best.matched_name,
+			CASE WHEN best.is_alias = 1 THEN best.matched_name ELSE '' END AS matched_alias,
+			CASE WHEN best.is_alias = 1 THEN best.is_historic END AS alias_historic,
+			best.score
+		FROM best
+		JOIN cities c ON c.id = best.city_id
+		JOIN countries cnt ON c.country_code = cnt.code
+		LEFT JOIN city_translations ct ON c.id = ct.city_id AND ct.lang = {lang}
+		LEFT JOIN city_translations ct_en ON c.id = ct_en.city_id AND ct_en.lang = 'en'
+		LEFT JOIN country_translations cnt_t ON cnt.code = cnt_t.country_code AND cnt_t.lang = {lang}
+		LEFT JOIN country_translations cnt_en ON cnt.code = cnt_en.country_code AND cnt_en.lang = 'en'
+		{region_joins}
+		WHERE best.rn = 1{filters}
+		ORDER BY best.score DESC, c.population DESC, c.id ASC
+		LIMIT {limit}
+	`)
+
+	return sql, args
+}
+
+// buildSuggestFilters renders the optional city filters of a suggest request
+// as AND-prefixed conditions on the cities table (alias c)
+func buildSuggestFilters(req model.SuggestRequest, bind func(value interface{}) string) string {
+	var sb strings.Builder
+
+	if len(req.CountryCodes) > 0 {
+		placeholders := make([]string, len(req.CountryCodes))
+		for i, code := range req.CountryCodes {
+			placeholders[i] = bind(code)
+		}
+		sb.WriteString(" AND c.country_code IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+	if req.MinPopulation > 0 {
+		sb.WriteString(" AND c.population >= " + bind(req.MinPopulation))
+	}
+	if req.MaxPopulation > 0 {
+		sb.WriteString(" AND c.population <= " + bind(req.MaxPopulation))
+	}
+	if req.Timezone != "" {
+		sb.WriteString(" AND c.timezone = " + bind(req.Timezone))
+	}
+	if len(req.FeatureCodes) > 0 {
+		placeholders := make([]string, len(req.FeatureCodes))
+		for i, code := range req.FeatureCodes {
+			placeholders[i] = bind(code)
+		}
+		sb.WriteString(" AND c.feature_code IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+
+	return sb.String()
+}
+
+// localizedCityColumns selects the name of the city aliased c and of its
+// country and divisions, joined by localizedCityJoins
+const localizedCityColumns = `COALESCE(ct.name, ct_en.name, c.name_default) AS name,
+			COALESCE(cnt_t.name, cnt_en.name, cnt.name_default) AS country,
+			` + regionColumns
+
+// localizedCityJoins joins the country of the city aliased c and the
+// translations of both in the language bound to the lang placeholder, which
+// it uses four times, falling back to English, then the divisions
+func localizedCityJoins(lang string) string {
+	return strings.NewReplacer("{lang}", lang).Replace(`
+		JOIN countries cnt ON c.country_code = cnt.code
+		LEFT JOIN city_translations ct ON c.id = ct.city_id AND ct.lang = {lang}
+		LEFT JOIN city_translations ct_en ON c.id = ct_en.city_id AND ct_en.lang = 'en'
+		LEFT JOIN country_translations cnt_t ON cnt.code = cnt_t.country_code AND cnt_t.lang = {lang}
+		LEFT JOIN country_translations cnt_en ON cnt.code = cnt_en.country_code AND cnt_en.lang = 'en'`) +
+		regionJoins(lang)
+}
+
+// regionColumns selects the localized admin1 (region) and admin2 (subregion)
+// names joined by regionJoins
+const regionColumns = `COALESCE(a1_t.name, a1_en.name, a1.name_default, '') AS region,
+			COALESCE(a2_t.name, a2_en.name, a2.name_default, '') AS subregion`
+
+// regionJoins joins the admin divisions of the city aliased c and their
+// translations in the language bound to the lang placeholder, falling back to
+// English like the city and country names do
+func regionJoins(lang string) string {
+	return strings.NewReplacer("{lang}", lang).Replace(`
+		LEFT JOIN admin_divisions a1 ON a1.code = c.country_code || '.' || c.admin1_code
+		LEFT JOIN admin_division_translations a1_t ON a1.code = a1_t.division_code AND a1_t.lang = {lang}
+		LEFT JOIN admin_division_translations a1_en ON a1.code = a1_en.division_code AND a1_en.lang = 'en'
+		LEFT JOIN admin_divisions a2 ON a2.code = c.country_code || '.' || c.admin1_code || '.' || c.admin2_code
+		LEFT JOIN admin_division_translations a2_t ON a2.code = a2_t.division_code AND a2_t.lang = {lang}
+		LEFT JOIN admin_division_translations a2_en ON a2.code = a2_en.division_code AND a2_en.lang = 'en'`)
+}
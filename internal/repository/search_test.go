@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parityCountryCode keeps the parity fixtures apart from any other data, so
+// the suite can also run against a populated Postgres database
+const parityCountryCode = "ZZ"
+
+// runSearchParitySuite checks that case and accent folding behave the same on
+// every backend. It seeds its own cities under parityCountryCode; callers
+// working on a shared database are responsible for removing them.
+func runSearchParitySuite(t *testing.T, repos *Container) {
+	ctx := context.Background()
+
+	err := repos.Country.BulkInsertCountries(ctx, []model.Country{{Code: parityCountryCode, NameDefault: "Parityland"}})
+	require.NoError(t, err)
+	err = repos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 9000001, CountryCode: parityCountryCode, NameDefault: "Zürich", Population: 400000},
+		{ID: 9000002, CountryCode: parityCountryCode, NameDefault: "São Paulo", Population: 12000000},
+		{ID: 9000003, CountryCode: parityCountryCode, NameDefault: "Kraków", Population: 800000},
+		{ID: 9000004, CountryCode: parityCountryCode, NameDefault: "Łódź", Population: 670000},
+		{ID: 9000005, CountryCode: parityCountryCode, NameDefault: "Tromsø", Population: 77000},
+		{ID: 9000006, CountryCode: parityCountryCode, NameDefault: "Moscow", Population: 12600000},
+		{ID: 9000007, CountryCode: parityCountryCode, NameDefault: "Zurich Heights", Population: 1000},
+	})
+	require.NoError(t, err)
+	err = repos.Translation.BulkInsertCityTranslations(ctx, []model.CityTranslation{
+		{CityID: 9000006, Lang: "ru", Name: "Москва"},
+		{CityID: 9000001, Lang: "de", Name: "Zürich"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"zurich", []int{9000001, 9000007}},
+		{"ZÜRICH", []int{9000001, 9000007}},
+		{"Zür", []int{9000001, 9000007}},
+		{"sao paulo", []int{9000002}},
+		{"SÃO", []int{9000002}},
+		{"krakow", []int{9000003}},
+		{"KRAKÓW", []int{9000003}},
+		{"lodz", []int{9000004}},
+		{"ŁÓDŹ", []int{9000004}},
+		{"tromso", []int{9000005}},
+		{"москва", []int{9000006}},
+		{"МОСК", []int{9000006}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+				Query: tt.query, Lang: "en", Limit: 10, CountryCodes: []string{parityCountryCode},
+			})
+			require.NoError(t, err)
+
+			ids := []int{}
+			for _, r := range results {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	t.Run("folded exact match ranks first", func(t *testing.T) {
+		results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+			Query: "ZURICH", Lang: "en", Limit: 10, CountryCodes: []string{parityCountryCode},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, scoreExact, results[0].Score)
+	})
+
+	// "trzzzzz" is about 0.18 (trigrams) or 0.29 (edit distance) similar to
+	// "tromso", so it only matches when thresholds below the pg_trgm default
+	// of 0.3 are honoured
+	t.Run("fuzzy threshold below the trigram default", func(t *testing.T) {
+		search := func(threshold float64) []int {
+			results, err := repos.City.SearchCitiesWithLang(ctx, model.SuggestRequest{
+				Query: "trzzzzz", Lang: "en", Limit: 10, CountryCodes: []string{parityCountryCode},
+				Fuzzy: true, FuzzyThreshold: threshold,
+			})
+			require.NoError(t, err)
+
+			ids := []int{}
+			for _, r := range results {
+				ids = append(ids, r.ID)
+			}
+			return ids
+		}
+
+		assert.Equal(t, []int{9000005}, search(0.15))
+		assert.Empty(t, search(0.3))
+	})
+}
+
+func TestSearchParity_SQLite(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+
+	runSearchParitySuite(t, repos)
+}
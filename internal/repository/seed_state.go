@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// seedStateRepository implements SeedStateRepository for both backends,
+// which share its SQL
+type seedStateRepository struct {
+	db *sqlx.DB
+}
+
+func (r *seedStateRepository) GetCheckpoints(ctx context.Context) (map[string]model.SeedCheckpoint, error) {
+	var checkpoints []model.SeedCheckpoint
+	if err := r.db.SelectContext(ctx, &checkpoints, "SELECT phase, line_offset, completed FROM seed_state"); err != nil {
+		return nil, err
+	}
+	byPhase := make(map[string]model.SeedCheckpoint, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		byPhase[checkpoint.Phase] = checkpoint
+	}
+	return byPhase, nil
+}
+
+func (r *seedStateRepository) SaveCheckpoint(ctx context.Context, checkpoint model.SeedCheckpoint) error {
+	_, err := r.db.NamedExecContext(ctx, `
+		INSERT INTO seed_state (phase, line_offset, completed)
+		VALUES (:phase, :line_offset, :completed)
+		ON CONFLICT (phase) DO UPDATE SET line_offset = excluded.line_offset,
+			completed = excluded.completed, updated_at = CURRENT_TIMESTAMP`, checkpoint)
+	return err
+}
+
+func (r *seedStateRepository) ClearCheckpoints(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM seed_state")
+	return err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedStateRepository(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	checkpoints, err := repos.SeedState.GetCheckpoints(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+
+	require.NoError(t, repos.SeedState.SaveCheckpoint(ctx, model.SeedCheckpoint{Phase: "cities", Offset: 100}))
+	require.NoError(t, repos.SeedState.SaveCheckpoint(ctx, model.SeedCheckpoint{Phase: "cities", Offset: 250, Completed: true}))
+	require.NoError(t, repos.SeedState.SaveCheckpoint(ctx, model.SeedCheckpoint{Phase: "alternate_names", Offset: 7}))
+
+	checkpoints, err = repos.SeedState.GetCheckpoints(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.SeedCheckpoint{
+		"cities":          {Phase: "cities", Offset: 250, Completed: true},
+		"alternate_names": {Phase: "alternate_names", Offset: 7},
+	}, checkpoints)
+
+	require.NoError(t, repos.SeedState.ClearCheckpoints(ctx))
+	checkpoints, err = repos.SeedState.GetCheckpoints(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+}
+
+func TestBulkInsert_ImportAgain(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// A resumed seed writes the batches after its last checkpoint again
+	require.NoError(t, repos.Country.BulkInsertCountries(ctx, []model.Country{{Code: "DE", NameDefault: "Deutschland"}}))
+	require.NoError(t, repos.City.BulkInsertCities(ctx, []model.City{
+		{ID: 1, CountryCode: "DE", NameDefault: "Berlin", Population: 3700000, Lat: 52.52, Lon: 13.405, FeatureClass: "P", FeatureCode: "PPLC"},
+	}))
+
+	country, err := repos.Country.GetCountry(ctx, "DE", "xx")
+	require.NoError(t, err)
+	require.NotNil(t, country)
+	assert.Equal(t, "Deutschland", country.Name)
+
+	city, err := repos.City.GetCityByID(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, city)
+	assert.Equal(t, 3700000, city.Population)
+
+	name, err := repos.City.GetCityName(ctx, 1, "de")
+	require.NoError(t, err)
+	assert.Equal(t, "Berlin", name, "translations survive")
+}
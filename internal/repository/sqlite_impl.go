@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"math"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/alexivanou/geocity-api/internal/model"
 	"github.com/jmoiron/sqlx"
@@ -12,18 +15,21 @@ import (
 
 type sqliteCityRepository struct {
 	db *sqlx.DB
+
+	indexMu sync.Mutex
+	index   *kdTree
 }
 
 func (r *sqliteCityRepository) SearchCities(ctx context.Context, query string, limit int) ([]model.City, error) {
 	q := `
-		SELECT DISTINCT c.*
+		SELECT DISTINCT ` + cityColumnList("c") + `
 		FROM cities c
-		WHERE LOWER(c.name_default) LIKE '%' || LOWER(?) || '%'
+		WHERE unaccent(LOWER(c.name_default)) LIKE '%' || unaccent(LOWER(?)) || '%'
 		UNION
-		SELECT DISTINCT c.*
+		SELECT DISTINCT ` + cityColumnList("c") + `
 		FROM cities c
 		INNER JOIN city_translations ct ON c.id = ct.city_id
-		WHERE LOWER(ct.name) LIKE '%' || LOWER(?) || '%'
+		WHERE unaccent(LOWER(ct.name)) LIKE '%' || unaccent(LOWER(?)) || '%'
 		ORDER BY population DESC
 		LIMIT ?
 	`
@@ -34,89 +40,235 @@ func (r *sqliteCityRepository) SearchCities(ctx context.Context, query string, l
 	return cities, nil
 }
 
-func (r *sqliteCityRepository) SearchCitiesWithLang(ctx context.Context, query string, lang string, limit int) ([]model.CityResult, error) {
-	q := `
-		SELECT DISTINCT 
-			c.id,
-			COALESCE(ct.name, ct_en.name, c.name_default) as name,
-			COALESCE(cnt_t.name, cnt_en.name, cnt.name_default) as country,
-			c.country_code,
-			c.population
-		FROM cities c
-		JOIN countries cnt ON c.country_code = cnt.code
-		LEFT JOIN city_translations ct ON c.id = ct.city_id AND ct.lang = ?
-		LEFT JOIN city_translations ct_en ON c.id = ct_en.city_id AND ct_en.lang = 'en'
-		LEFT JOIN country_translations cnt_t ON cnt.code = cnt_t.country_code AND cnt_t.lang = ?
-		LEFT JOIN country_translations cnt_en ON cnt.code = cnt_en.country_code AND cnt_en.lang = 'en'
-		WHERE 
-			LOWER(c.name_default) LIKE '%' || LOWER(?) || '%'
-			OR 
-			EXISTS (
-				SELECT 1 FROM city_translations search_ct 
-				WHERE search_ct.city_id = c.id 
-				AND LOWER(search_ct.name) LIKE '%' || LOWER(?) || '%'
-			)
-		ORDER BY c.population DESC
-		LIMIT ?
-	`
+func (r *sqliteCityRepository) SearchCitiesWithLang(ctx context.Context, req model.SuggestRequest) ([]model.CityResult, error) {
+	q, args := buildSuggestQuery(sqliteSearchDialect, req)
+
 	var results []model.CityResult
-	if err := r.db.SelectContext(ctx, &results, q, lang, lang, query, query, limit); err != nil {
+	if err := r.db.SelectContext(ctx, &results, q, args...); err != nil {
 		return nil, err
 	}
 	return results, nil
 }
 
 func (r *sqliteCityRepository) FindNearestCity(ctx context.Context, lat, lon float64) (*model.City, float64, error) {
-	delta := 2.0
-	q := `
-		SELECT * FROM cities
-		WHERE lat BETWEEN ? AND ? AND lon BETWEEN ? AND ?
-	`
-	var candidates []model.City
-	err := r.db.SelectContext(ctx, &candidates, q, lat-delta, lat+delta, lon-delta, lon+delta)
+	index, err := r.spatialIndex(ctx)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	if len(candidates) == 0 {
-		if err := r.db.SelectContext(ctx, &candidates, "SELECT * FROM cities"); err != nil {
-			return nil, 0, err
+	hits := index.Nearest(lat, lon, 1, 0, nil)
+	if len(hits) == 0 {
+		return nil, 0, nil
+	}
+
+	city, err := r.GetCityByID(ctx, hits[0].ID)
+	if err != nil || city == nil {
+		return nil, 0, err
+	}
+	return city, hits[0].Distance, nil
+}
+
+func (r *sqliteCityRepository) FindNearestCities(ctx context.Context, req model.NearestRequest) ([]model.CityWithDistance, error) {
+	limit := req.Count
+	if limit <= 0 {
+		limit = 1
+	}
+
+	index, err := r.spatialIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var keep func(p *kdPoint) bool
+	if len(req.FeatureCodes) > 0 {
+		codes := make(map[string]bool, len(req.FeatureCodes))
+		for _, code := range req.FeatureCodes {
+			codes[code] = true
 		}
+		keep = func(p *kdPoint) bool { return codes[p.FeatureCode] }
+	}
+
+	hits := index.Nearest(req.Lat, req.Lon, limit, req.MaxKm, keep)
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	q, args, err := sqlx.In("SELECT "+cityColumnList("c")+", "+localizedCityColumns+
+		" FROM cities c "+localizedCityJoins("?")+" WHERE c.id IN (?)",
+		req.Lang, req.Lang, req.Lang, req.Lang, ids)
+	if err != nil {
+		return nil, err
+	}
+	var cities []model.CityWithDistance
+	if err := r.db.SelectContext(ctx, &cities, r.db.Rebind(q), args...); err != nil {
+		return nil, err
 	}
 
-	var nearest *model.City
-	minDist := math.MaxFloat64
+	byID := make(map[int]model.CityWithDistance, len(cities))
+	for _, city := range cities {
+		byID[city.ID] = city
+	}
 
-	for i := range candidates {
-		city := candidates[i]
-		dist := calculateDistance(lat, lon, city.Lat, city.Lon)
-		if dist < minDist {
-			minDist = dist
-			nearest = &city
+	// Keep the index order, which is nearest first
+	results := make([]model.CityWithDistance, 0, len(hits))
+	for _, hit := range hits {
+		if city, ok := byID[hit.ID]; ok {
+			city.Distance = hit.Distance
+			results = append(results, city)
 		}
 	}
+	return results, nil
+}
+
+// spatialIndex returns the in-memory index over all city coordinates,
+// building it on first use. SQLite has no spatial index of its own, and the
+// index turns nearest-city lookups from table scans into tree searches.
+func (r *sqliteCityRepository) spatialIndex(ctx context.Context) (*kdTree, error) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	if r.index != nil {
+		return r.index, nil
+	}
 
-	if nearest == nil {
-		return nil, 0, nil
+	var points []kdPoint
+	if err := r.db.SelectContext(ctx, &points, "SELECT id, lat, lon, feature_code, population FROM cities"); err != nil {
+		return nil, err
+	}
+	r.index = newKDTree(points)
+	return r.index, nil
+}
+
+func (r *sqliteCityRepository) warmUp(ctx context.Context) error {
+	_, err := r.spatialIndex(ctx)
+	return err
+}
+
+// invalidateIndex drops the spatial index after cities were written; the
+// next nearest-city query rebuilds it
+func (r *sqliteCityRepository) invalidateIndex() {
+	r.indexMu.Lock()
+	r.index = nil
+	r.indexMu.Unlock()
+}
+
+func (r *sqliteCityRepository) FindCitiesWithin(ctx context.Context, req model.WithinRequest) ([]model.CityResult, error) {
+	index, err := r.spatialIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite has no trigonometry, so the spatial index finds the circle and
+	// orders and pages it; only the cities of the page are read from SQL
+	hits := index.Within(req.Lat, req.Lon, req.RadiusKm)
+	positions := make([]model.WithinCursor, len(hits))
+	for i, hit := range hits {
+		positions[i] = model.WithinCursor{Distance: hit.Distance, Population: hit.Population, ID: hit.ID}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return withinLess(req.SortBy, positions[i], positions[j])
+	})
+
+	if req.After != nil {
+		// Skip up to and including the last row of the previous page
+		start := sort.Search(len(positions), func(i int) bool {
+			return withinLess(req.SortBy, *req.After, positions[i])
+		})
+		positions = positions[start:]
 	}
-	return nearest, minDist, nil
+	if req.Limit > 0 && len(positions) > req.Limit {
+		positions = positions[:req.Limit]
+	}
+	if len(positions) == 0 {
+		return []model.CityResult{}, nil
+	}
+
+	ids := make([]int, len(positions))
+	for i, position := range positions {
+		ids[i] = position.ID
+	}
+	q, args, err := sqlx.In(`
+		SELECT 
+			c.id,
+			`+localizedCityColumns+`,
+			c.country_code,
+			c.population,
+			c.lat AS "coordinates.lat",
+			c.lon AS "coordinates.lon"
+		FROM cities c
+		`+localizedCityJoins("?")+`
+		WHERE c.id IN (?)`, req.Lang, req.Lang, req.Lang, req.Lang, ids)
+	if err != nil {
+		return nil, err
+	}
+	var cities []model.CityResult
+	if err := r.db.SelectContext(ctx, &cities, r.db.Rebind(q), args...); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]model.CityResult, len(cities))
+	for _, city := range cities {
+		byID[city.ID] = city
+	}
+
+	// Keep the order of the page
+	results := make([]model.CityResult, 0, len(positions))
+	for _, position := range positions {
+		if city, ok := byID[position.ID]; ok {
+			dist := position.Distance
+			city.DistanceKm = &dist
+			results = append(results, city)
+		}
+	}
+	return results, nil
+}
+
+func (r *sqliteCityRepository) FindCitiesInBBox(ctx context.Context, req model.BBoxRequest) ([]model.CityResult, error) {
+	box := geoBox{MinLat: req.MinLat, MaxLat: req.MaxLat, MinLon: req.MinLon, MaxLon: req.MaxLon}
+	boxCond, boxArgs := sqliteBoxCondition("c", box)
+
+	q := `
+		SELECT 
+			c.id,
+			` + localizedCityColumns + `,
+			c.country_code,
+			c.population,
+			c.lat AS "coordinates.lat",
+			c.lon AS "coordinates.lon"
+		FROM cities c
+		` + localizedCityJoins("?") + `
+		WHERE c.population >= ? AND ` + boxCond + `
+		ORDER BY c.population DESC, c.id ASC
+		LIMIT ?
+	`
+	args := []interface{}{req.Lang, req.Lang, req.Lang, req.Lang, req.MinPopulation}
+	args = append(args, boxArgs...)
+	args = append(args, req.Limit)
+
+	var results []model.CityResult
+	if err := r.db.SelectContext(ctx, &results, q, args...); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
-	const R = 6371
-	dLat := (lat2 - lat1) * (math.Pi / 180.0)
-	dLon := (lon2 - lon1) * (math.Pi / 180.0)
-	lat1Rad := lat1 * (math.Pi / 180.0)
-	lat2Rad := lat2 * (math.Pi / 180.0)
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Sin(dLon/2)*math.Sin(dLon/2)*math.Cos(lat1Rad)*math.Cos(lat2Rad)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return R * c
+// sqliteBoxCondition renders a WHERE clause matching rows of the table
+// aliased as alias inside the box
+func sqliteBoxCondition(alias string, box geoBox) (string, []interface{}) {
+	args := []interface{}{box.MinLat, box.MaxLat, box.MinLon, box.MaxLon}
+	if box.crossesAntimeridian() {
+		return fmt.Sprintf("%[1]s.lat BETWEEN ? AND ? AND (%[1]s.lon >= ? OR %[1]s.lon <= ?)", alias), args
+	}
+	return fmt.Sprintf("%[1]s.lat BETWEEN ? AND ? AND %[1]s.lon BETWEEN ? AND ?", alias), args
 }
 
 func (r *sqliteCityRepository) GetCityByID(ctx context.Context, id int) (*model.City, error) {
 	var city model.City
-	if err := r.db.GetContext(ctx, &city, "SELECT * FROM cities WHERE id = ?", id); err != nil {
+	if err := r.db.GetContext(ctx, &city, "SELECT "+cityColumnList("")+" FROM cities WHERE id = ?", id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -140,9 +292,19 @@ func (r *sqliteCityRepository) GetCityName(ctx context.Context, cityID int, lang
 	return name, nil
 }
 
+func (r *sqliteCityRepository) GetCityAlternateNames(ctx context.Context, cityID int) ([]model.CityAlternateName, error) {
+	var names []model.CityAlternateName
+	if err := r.db.SelectContext(ctx, &names, cityAlternateNameSelectSQL, cityID); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
 func (r *sqliteCityRepository) BulkInsertCities(ctx context.Context, cities []model.City) error {
-	// SQLite variable limit workaround (batch size of 100 * 8 params = 800 variables, well within standard limits)
-	chunkSize := 100
+	defer r.invalidateIndex()
+
+	// SQLite variable limit workaround (batch size of 64 * 14 params = 896 variables, well within standard limits)
+	chunkSize := 64
 	for i := 0; i < len(cities); i += chunkSize {
 		end := i + chunkSize
 		if end > len(cities) {
@@ -150,10 +312,7 @@ func (r *sqliteCityRepository) BulkInsertCities(ctx context.Context, cities []mo
 		}
 		batch := cities[i:end]
 
-		_, err := r.db.NamedExecContext(ctx, `
-		INSERT INTO cities (id, country_code, name_default, population, lat, lon, elevation, timezone)
-		VALUES (:id, :country_code, :name_default, :population, :lat, :lon, :elevation, :timezone)`,
-			batch)
+		_, err := r.db.NamedExecContext(ctx, cityUpsertSQL, batch)
 		if err != nil {
 			return err
 		}
@@ -181,13 +340,108 @@ func (r *sqliteCountryRepository) GetCountryName(ctx context.Context, countryCod
 }
 
 func (r *sqliteCountryRepository) BulkInsertCountries(ctx context.Context, countries []model.Country) error {
-	_, err := r.db.NamedExecContext(ctx, `
-		INSERT INTO countries (code, name_default)
-		VALUES (:code, :name_default)`,
-		countries)
+	// 50 * 17 params = 850 variables, within the conservative SQLite limit
+	chunkSize := 50
+	for i := 0; i < len(countries); i += chunkSize {
+		end := i + chunkSize
+		if end > len(countries) {
+			end = len(countries)
+		}
+		if _, err := r.db.NamedExecContext(ctx, countryUpsertSQL, countries[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqliteCountryRepository) ListCountries(ctx context.Context, lang string) ([]model.CountryWithName, error) {
+	var countries []model.CountryWithName
+	if err := r.db.SelectContext(ctx, &countries, countrySelectSQL("?")+" ORDER BY cnt.code", lang); err != nil {
+		return nil, err
+	}
+	return countries, nil
+}
+
+func (r *sqliteCountryRepository) GetCountry(ctx context.Context, code string, lang string) (*model.CountryWithName, error) {
+	var country model.CountryWithName
+	err := r.db.GetContext(ctx, &country, countrySelectSQL("?")+" WHERE cnt.code = ?", lang, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &country, nil
+}
+
+func (r *sqliteCountryRepository) GetAdminDivisionName(ctx context.Context, code string, lang string) (string, error) {
+	q := `
+		SELECT COALESCE(
+			(SELECT name FROM admin_division_translations WHERE division_code = ? AND lang = ?),
+			(SELECT name FROM admin_division_translations WHERE division_code = ? AND lang = 'en'),
+			(SELECT name_default FROM admin_divisions WHERE code = ?),
+			''
+		)
+	`
+	var name string
+	if err := r.db.GetContext(ctx, &name, q, code, lang, code, code); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (r *sqliteCountryRepository) BulkInsertAdminDivisions(ctx context.Context, divisions []model.AdminDivision) error {
+	chunkSize := 200
+	for i := 0; i < len(divisions); i += chunkSize {
+		end := i + chunkSize
+		if end > len(divisions) {
+			end = len(divisions)
+		}
+		batch := divisions[i:end]
+
+		q := `INSERT OR REPLACE INTO admin_divisions (code, country_code, level, name_default, geoname_id)
+			  VALUES (:code, :country_code, :level, :name_default, :geoname_id)`
+
+		if _, err := r.db.NamedExecContext(ctx, q, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqliteCountryRepository) BulkInsertContinents(ctx context.Context, continents []model.Continent) error {
+	if len(continents) == 0 {
+		return nil
+	}
+	q := `INSERT OR REPLACE INTO continents (code, geoname_id, name_default)
+		  VALUES (:code, :geoname_id, :name_default)`
+	_, err := r.db.NamedExecContext(ctx, q, continents)
 	return err
 }
 
+func (r *sqliteCountryRepository) GetPlaceAncestors(ctx context.Context, geonameID int, lang string) ([]model.Place, error) {
+	return placeAncestors(ctx, r.db, geonameID, lang)
+}
+
+func (r *sqliteCountryRepository) BulkInsertHierarchy(ctx context.Context, edges []model.HierarchyEdge) error {
+	chunkSize := 300
+	for i := 0; i < len(edges); i += chunkSize {
+		end := i + chunkSize
+		if end > len(edges) {
+			end = len(edges)
+		}
+		batch := edges[i:end]
+
+		q := `INSERT OR IGNORE INTO hierarchy (parent_id, child_id, type)
+			  VALUES (:parent_id, :child_id, :type)`
+
+		if _, err := r.db.NamedExecContext(ctx, q, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type sqliteTranslationRepository struct {
 	db *sqlx.DB
 }
@@ -230,6 +484,61 @@ func (r *sqliteTranslationRepository) BulkInsertCountryTranslations(ctx context.
 	return nil
 }
 
+func (r *sqliteTranslationRepository) BulkInsertAdminDivisionTranslations(ctx context.Context, translations []model.AdminDivisionTranslation) error {
+	chunkSize := 500
+	for i := 0; i < len(translations); i += chunkSize {
+		end := i + chunkSize
+		if end > len(translations) {
+			end = len(translations)
+		}
+		batch := translations[i:end]
+
+		q := `INSERT OR REPLACE INTO admin_division_translations (division_code, lang, name)
+			  VALUES (:division_code, :lang, :name)`
+
+		if _, err := r.db.NamedExecContext(ctx, q, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqliteTranslationRepository) BulkInsertContinentTranslations(ctx context.Context, translations []model.ContinentTranslation) error {
+	chunkSize := 300
+	for i := 0; i < len(translations); i += chunkSize {
+		end := i + chunkSize
+		if end > len(translations) {
+			end = len(translations)
+		}
+		batch := translations[i:end]
+
+		q := `INSERT OR REPLACE INTO continent_translations (continent_code, lang, name)
+			  VALUES (:continent_code, :lang, :name)`
+
+		if _, err := r.db.NamedExecContext(ctx, q, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqliteTranslationRepository) BulkInsertCityAlternateNames(ctx context.Context, names []model.CityAlternateName) error {
+	// 8 params per name
+	chunkSize := 100
+	q := strings.Replace(cityAlternateNameInsertSQL, "INSERT INTO", "INSERT OR REPLACE INTO", 1)
+	for i := 0; i < len(names); i += chunkSize {
+		end := i + chunkSize
+		if end > len(names) {
+			end = len(names)
+		}
+
+		if _, err := r.db.NamedExecContext(ctx, q, names[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *sqliteTranslationRepository) GetAvailableLanguages(ctx context.Context) ([]string, error) {
 	q := `SELECT DISTINCT lang FROM (
 			SELECT lang FROM city_translations
@@ -242,3 +551,62 @@ func (r *sqliteTranslationRepository) GetAvailableLanguages(ctx context.Context)
 	}
 	return langs, nil
 }
+
+type sqlitePostalCodeRepository struct {
+	db *sqlx.DB
+}
+
+func (r *sqlitePostalCodeRepository) FindPostalCodes(ctx context.Context, countryCode, postalCode string) ([]model.PostalCode, error) {
+	q := postalCodeSelectSQL + " WHERE country_code = ? AND postal_code = ? ORDER BY place_name, id"
+
+	var codes []model.PostalCode
+	if err := r.db.SelectContext(ctx, &codes, q, countryCode, postalCode); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (r *sqlitePostalCodeRepository) FindPostalCodesNear(ctx context.Context, req model.PostalNearestRequest) ([]model.PostalCodeWithDistance, error) {
+	box := boundingBox(req.Lat, req.Lon, req.RadiusKm)
+	boxCond, boxArgs := sqliteBoxCondition("p", box)
+
+	// As for cities, the box is filtered in SQL and the circle in Go
+	var candidates []model.PostalCode
+	if err := r.db.SelectContext(ctx, &candidates, postalCodeSelectSQL+" p WHERE "+boxCond, boxArgs...); err != nil {
+		return nil, err
+	}
+
+	results := make([]model.PostalCodeWithDistance, 0, len(candidates))
+	for _, code := range candidates {
+		dist := calculateDistance(req.Lat, req.Lon, code.Lat, code.Lon)
+		if dist <= req.RadiusKm {
+			results = append(results, model.PostalCodeWithDistance{PostalCode: code, Distance: dist})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		return results[i].ID < results[j].ID
+	})
+	if req.Limit > 0 && len(results) > req.Limit {
+		results = results[:req.Limit]
+	}
+	return results, nil
+}
+
+func (r *sqlitePostalCodeRepository) BulkInsertPostalCodes(ctx context.Context, codes []model.PostalCode) error {
+	// Each row binds its id and postalCodeColumns
+	chunkSize := updateChunkSize / (len(postalCodeColumns) + 1)
+	for i := 0; i < len(codes); i += chunkSize {
+		end := i + chunkSize
+		if end > len(codes) {
+			end = len(codes)
+		}
+		if _, err := r.db.NamedExecContext(ctx, postalCodeInsertSQL, codes[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
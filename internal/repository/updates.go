@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// updateChunkSize bounds the parameters of the IN lists and multi-row
+// inserts of an update, keeping them within SQLite's variable limit
+const updateChunkSize = 500
+
+// cityAlternateNameUpsertSQL inserts alternate names, overwriting existing ones
+var cityAlternateNameUpsertSQL = upsertSQL("city_alternate_names", cityAlternateNameColumns)
+
+// refreshTranslationSQL picks the display name of a city in a language from
+// its alternate names the way the seeder does: the preferred name wins,
+// colloquial and historic names never do. Languages are stored truncated to
+// two letters, so "zh-CN" names count for "zh".
+const refreshTranslationSQL = `
+	INSERT INTO city_translations (city_id, lang, name)
+	SELECT city_id, SUBSTR(lang, 1, 2), name
+	FROM city_alternate_names
+	WHERE city_id = ? AND lang <> '' AND SUBSTR(lang, 1, 2) = ? AND NOT is_colloquial AND NOT is_historic
+	ORDER BY is_preferred DESC, id
+	LIMIT 1`
+
+// updateRepository implements UpdateRepository for both backends, which
+// share its SQL
+type updateRepository struct {
+	db *sqlx.DB
+	// citiesChanged is called after an update wrote cities, e.g. to drop
+	// in-memory indexes over them; may be nil
+	citiesChanged func()
+}
+
+func (r *updateRepository) LastUpdate(ctx context.Context) (string, error) {
+	var date string
+	if err := r.db.GetContext(ctx, &date, "SELECT COALESCE(MAX(update_date), '') FROM data_updates"); err != nil {
+		return "", err
+	}
+	return date, nil
+}
+
+func (r *updateRepository) RecordUpdate(ctx context.Context, update model.DataUpdate) error {
+	_, err := r.db.NamedExecContext(ctx, dataUpdateUpsertSQL, update)
+	return err
+}
+
+// DataVersion combines the number and latest time of the recorded updates
+// with the latest seed checkpoint. A daily update adds or rewrites a
+// data_updates row, and a seed or reload brings new checkpoints.
+func (r *updateRepository) DataVersion(ctx context.Context) (string, error) {
+	var version struct {
+		Updates   int            `db:"updates"`
+		AppliedAt sql.NullString `db:"applied_at"`
+		SeededAt  sql.NullString `db:"seeded_at"`
+	}
+	err := r.db.GetContext(ctx, &version, `
+		SELECT (SELECT COUNT(*) FROM data_updates) AS updates,
+			(SELECT MAX(applied_at) FROM data_updates) AS applied_at,
+			(SELECT MAX(updated_at) FROM seed_state) AS seeded_at`)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%s/%s", version.Updates, version.AppliedAt.String, version.SeededAt.String), nil
+}
+
+// dataUpdateUpsertSQL records a model.DataUpdate, replacing a record of the same date
+const dataUpdateUpsertSQL = `
+	INSERT INTO data_updates (update_date, source, cities_modified, cities_deleted, names_modified, names_deleted)
+	VALUES (:update_date, :source, :cities_modified, :cities_deleted, :names_modified, :names_deleted)
+	ON CONFLICT (update_date) DO UPDATE SET source = excluded.source,
+		cities_modified = excluded.cities_modified, cities_deleted = excluded.cities_deleted,
+		names_modified = excluded.names_modified, names_deleted = excluded.names_deleted,
+		applied_at = CURRENT_TIMESTAMP`
+
+func (r *updateRepository) ApplyDailyUpdate(ctx context.Context, update model.DailyUpdate) (*model.DataUpdate, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	record := &model.DataUpdate{Date: update.Date, Source: model.UpdateSourceDaily}
+
+	// Cities of countries that were not imported would violate the foreign key
+	countries, err := selectCodes(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load countries: %w", err)
+	}
+	var cities []model.City
+	for _, city := range update.Cities {
+		if countries[city.CountryCode] {
+			cities = append(cities, city)
+		}
+	}
+	perChunk := updateChunkSize / len(cityColumns)
+	for i := 0; i < len(cities); i += perChunk {
+		end := min(i+perChunk, len(cities))
+		if _, err := tx.NamedExecContext(ctx, cityUpsertSQL, cities[i:end]); err != nil {
+			return nil, fmt.Errorf("failed to upsert cities: %w", err)
+		}
+	}
+	record.CitiesModified = len(cities)
+
+	if record.CitiesDeleted, err = deleteCities(ctx, tx, update.DeletedCityIDs); err != nil {
+		return nil, fmt.Errorf("failed to delete cities: %w", err)
+	}
+
+	if err := applyNameChanges(ctx, tx, update, record); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.NamedExecContext(ctx, dataUpdateUpsertSQL, record); err != nil {
+		return nil, fmt.Errorf("failed to record update: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if r.citiesChanged != nil && record.CitiesModified+record.CitiesDeleted > 0 {
+		r.citiesChanged()
+	}
+	return record, nil
+}
+
+// selectCodes returns the codes of all countries
+func selectCodes(ctx context.Context, tx *sqlx.Tx) (map[string]bool, error) {
+	var codes []string
+	if err := tx.SelectContext(ctx, &codes, "SELECT code FROM countries"); err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		known[code] = true
+	}
+	return known, nil
+}
+
+// deleteCities deletes cities with their names and returns how many existed.
+// Child rows are deleted explicitly because SQLite only enforces ON DELETE
+// CASCADE on connections that enabled foreign keys.
+func deleteCities(ctx context.Context, tx *sqlx.Tx, ids []int) (int, error) {
+	deleted := 0
+	for i := 0; i < len(ids); i += updateChunkSize {
+		chunk := ids[i:min(i+updateChunkSize, len(ids))]
+		for _, q := range []string{
+			"DELETE FROM city_translations WHERE city_id IN (?)",
+			"DELETE FROM city_alternate_names WHERE city_id IN (?)",
+		} {
+			if _, err := execIn(ctx, tx, q, chunk); err != nil {
+				return 0, err
+			}
+		}
+		n, err := execIn(ctx, tx, "DELETE FROM cities WHERE id IN (?)", chunk)
+		if err != nil {
+			return 0, err
+		}
+		deleted += int(n)
+	}
+	return deleted, nil
+}
+
+// nameKey identifies a display name in city_translations
+type nameKey struct {
+	CityID int    `db:"city_id"`
+	Lang   string `db:"lang"`
+}
+
+// applyNameChanges upserts and deletes alternate names of known cities and
+// refreshes the display names they affect
+func applyNameChanges(ctx context.Context, tx *sqlx.Tx, update model.DailyUpdate, record *model.DataUpdate) error {
+	// Display names that may change: those in the languages of the new names
+	// and of the names being replaced or deleted
+	affected := make(map[nameKey]bool)
+	changedIDs := append([]int64(nil), update.DeletedNameIDs...)
+	for _, name := range update.Names {
+		changedIDs = append(changedIDs, name.ID)
+	}
+	for i := 0; i < len(changedIDs); i += updateChunkSize {
+		var keys []nameKey
+		if err := selectIn(ctx, tx, &keys, "SELECT city_id, lang FROM city_alternate_names WHERE id IN (?)",
+			changedIDs[i:min(i+updateChunkSize, len(changedIDs))]); err != nil {
+			return fmt.Errorf("failed to load changed names: %w", err)
+		}
+		for _, key := range keys {
+			affected[key] = true
+		}
+	}
+
+	// Names of places other than the stored cities are dropped
+	var cityIDs []int
+	for _, name := range update.Names {
+		cityIDs = append(cityIDs, name.CityID)
+	}
+	cities := make(map[int]bool)
+	for i := 0; i < len(cityIDs); i += updateChunkSize {
+		var ids []int
+		if err := selectIn(ctx, tx, &ids, "SELECT id FROM cities WHERE id IN (?)",
+			cityIDs[i:min(i+updateChunkSize, len(cityIDs))]); err != nil {
+			return fmt.Errorf("failed to load cities: %w", err)
+		}
+		for _, id := range ids {
+			cities[id] = true
+		}
+	}
+	var names []model.CityAlternateName
+	for _, name := range update.Names {
+		if cities[name.CityID] {
+			names = append(names, name)
+			affected[nameKey{CityID: name.CityID, Lang: name.Lang}] = true
+		}
+	}
+
+	perChunk := updateChunkSize / len(cityAlternateNameColumns)
+	for i := 0; i < len(names); i += perChunk {
+		end := min(i+perChunk, len(names))
+		if _, err := tx.NamedExecContext(ctx, cityAlternateNameUpsertSQL, names[i:end]); err != nil {
+			return fmt.Errorf("failed to upsert names: %w", err)
+		}
+	}
+	record.NamesModified = len(names)
+
+	for i := 0; i < len(update.DeletedNameIDs); i += updateChunkSize {
+		n, err := execIn(ctx, tx, "DELETE FROM city_alternate_names WHERE id IN (?)",
+			update.DeletedNameIDs[i:min(i+updateChunkSize, len(update.DeletedNameIDs))])
+		if err != nil {
+			return fmt.Errorf("failed to delete names: %w", err)
+		}
+		record.NamesDeleted += int(n)
+	}
+
+	refreshed := make(map[nameKey]bool)
+	for key := range affected {
+		if key.Lang == "" {
+			continue
+		}
+		key.Lang = key.Lang[:min(2, len(key.Lang))]
+		if refreshed[key] {
+			continue
+		}
+		refreshed[key] = true
+
+		if _, err := tx.ExecContext(ctx, tx.Rebind("DELETE FROM city_translations WHERE city_id = ? AND lang = ?"),
+			key.CityID, key.Lang); err != nil {
+			return fmt.Errorf("failed to refresh translation: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(refreshTranslationSQL), key.CityID, key.Lang); err != nil {
+			return fmt.Errorf("failed to refresh translation: %w", err)
+		}
+	}
+	return nil
+}
+
+// execIn runs a statement with a single IN (?) list and returns the number of affected rows
+func execIn(ctx context.Context, tx *sqlx.Tx, query string, values interface{}) (int64, error) {
+	q, args, err := sqlx.In(query, values)
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, tx.Rebind(q), args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// selectIn runs a query with a single IN (?) list into dest
+func selectIn(ctx context.Context, tx *sqlx.Tx, dest interface{}, query string, values interface{}) error {
+	q, args, err := sqlx.In(query, values)
+	if err != nil {
+		return err
+	}
+	return tx.SelectContext(ctx, dest, tx.Rebind(q), args...)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateRepository_ApplyDailyUpdate(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	last, err := repos.Update.LastUpdate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, last)
+
+	require.NoError(t, repos.Update.RecordUpdate(ctx, model.DataUpdate{Date: "2024-04-30", Source: model.UpdateSourceDump}))
+	require.NoError(t, repos.Translation.BulkInsertCityAlternateNames(ctx, []model.CityAlternateName{
+		{ID: 10, CityID: 1, Lang: "de", Name: "Berlin"},
+		{ID: 11, CityID: 1, Lang: "en", Name: "Berlin"},
+	}))
+
+	// Build the spatial index before the update moves a city
+	nearest, _, err := repos.City.FindNearestCity(ctx, 48.14, 11.58)
+	require.NoError(t, err)
+	assert.Equal(t, 2, nearest.ID)
+
+	record, err := repos.Update.ApplyDailyUpdate(ctx, model.DailyUpdate{
+		Date: "2024-05-01",
+		Cities: []model.City{
+			{ID: 1, CountryCode: "DE", NameDefault: "Berlin", Population: 3700000, Lat: 52.52, Lon: 13.405, FeatureClass: "P", FeatureCode: "PPLC"},
+			{ID: 3, CountryCode: "DE", NameDefault: "München", Population: 1500000, Lat: 48.137, Lon: 11.575, FeatureClass: "P", FeatureCode: "PPLA"},
+			{ID: 4, CountryCode: "FR", NameDefault: "Paris", Population: 2100000, Lat: 48.85, Lon: 2.35, FeatureClass: "P", FeatureCode: "PPLC"},
+		},
+		DeletedCityIDs: []int{2, 999},
+		Names: []model.CityAlternateName{
+			{ID: 11, CityID: 1, Lang: "en", Name: "Berlin City", IsPreferred: true},
+			{ID: 12, CityID: 1, Lang: "de", Name: "Bärlin", IsColloquial: true},
+			{ID: 13, CityID: 3, Lang: "en", Name: "Munich", IsPreferred: true},
+			{ID: 14, CityID: 3, Lang: "en", Name: "Muenchen"},
+			{ID: 15, CityID: 4, Lang: "en", Name: "Paris"},
+		},
+		DeletedNameIDs: []int64{10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &model.DataUpdate{
+		Date: "2024-05-01", Source: model.UpdateSourceDaily,
+		CitiesModified: 2, CitiesDeleted: 1, NamesModified: 4, NamesDeleted: 1,
+	}, record, "Paris is skipped: FR was not imported")
+
+	berlin, err := repos.City.GetCityByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3700000, berlin.Population)
+
+	potsdam, err := repos.City.GetCityByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Nil(t, potsdam)
+
+	nearest, _, err = repos.City.FindNearestCity(ctx, 48.14, 11.58)
+	require.NoError(t, err)
+	assert.Equal(t, 3, nearest.ID, "the spatial index sees new cities")
+
+	name, err := repos.City.GetCityName(ctx, 3, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Munich", name, "the preferred name wins")
+
+	name, err = repos.City.GetCityName(ctx, 1, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Berlin City", name)
+
+	// The only German name left is colloquial, so German falls back to English
+	name, err = repos.City.GetCityName(ctx, 1, "de")
+	require.NoError(t, err)
+	assert.Equal(t, "Berlin City", name)
+
+	last, err = repos.Update.LastUpdate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01", last)
+
+	t.Run("Reapplying is harmless", func(t *testing.T) {
+		record, err := repos.Update.ApplyDailyUpdate(ctx, model.DailyUpdate{
+			Date:           "2024-05-01",
+			DeletedCityIDs: []int{2},
+			DeletedNameIDs: []int64{10},
+		})
+		require.NoError(t, err)
+		assert.Zero(t, record.CitiesDeleted)
+		assert.Zero(t, record.NamesDeleted)
+	})
+}
+
+func TestUpdateRepository_DataVersion(t *testing.T) {
+	repos, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	versions := map[string]bool{}
+	version := func() string {
+		v, err := repos.Update.DataVersion(ctx)
+		require.NoError(t, err)
+		return v
+	}
+	versions[version()] = true
+
+	// A seed checkpoint, a dump record and a daily update each change it
+	require.NoError(t, repos.SeedState.SaveCheckpoint(ctx, model.SeedCheckpoint{Phase: "cities", Offset: 1}))
+	versions[version()] = true
+	require.NoError(t, repos.Update.RecordUpdate(ctx, model.DataUpdate{Date: "2024-04-30", Source: model.UpdateSourceDump}))
+	versions[version()] = true
+	_, err := repos.Update.ApplyDailyUpdate(ctx, model.DailyUpdate{Date: "2024-05-01"})
+	require.NoError(t, err)
+	versions[version()] = true
+	assert.Len(t, versions, 4)
+
+	assert.Equal(t, version(), version(), "unchanged data keeps its version")
+}
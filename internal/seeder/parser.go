@@ -3,6 +3,8 @@ package seeder
 import (
 	"archive/zip"
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -22,8 +24,10 @@ const (
 type Parser struct {
 	dataDir          string
 	batchSize        int
+	workers          int
 	minPopulation    int
 	allowedLanguages map[string]bool
+	dumpDate         string
 }
 
 // NewParser creates a new parser instance with config
@@ -36,8 +40,10 @@ func NewParser(dataDir string, seederCfg config.SeederConfig) *Parser {
 	return &Parser{
 		dataDir:          dataDir,
 		batchSize:        seederCfg.BatchSize,
+		workers:          seederCfg.Workers,
 		minPopulation:    seederCfg.MinPopulation,
 		allowedLanguages: allowedLangs,
+		dumpDate:         seederCfg.DumpDate,
 	}
 }
 
@@ -71,12 +77,34 @@ func (p *Parser) ParseCountries() ([]model.Country, error) {
 		code := parts[0]
 		name := parts[4]
 		geonameID, _ := strconv.Atoi(parts[16])
+		area, _ := strconv.ParseFloat(parts[6], 64)
+		population, _ := strconv.ParseInt(parts[7], 10, 64)
+
+		// neighbours is the last column read; trailing empty columns may be cut
+		var neighbours string
+		if len(parts) > 17 {
+			neighbours = parts[17]
+		}
 
 		if code != "" && name != "" {
 			countries = append(countries, model.Country{
-				Code:        code,
-				NameDefault: name,
-				GeonameID:   geonameID,
+				Code:             code,
+				NameDefault:      name,
+				GeonameID:        geonameID,
+				ISO3:             parts[1],
+				ISONumeric:       parts[2],
+				Capital:          parts[5],
+				AreaSqKm:         area,
+				Population:       population,
+				Continent:        parts[8],
+				TLD:              parts[9],
+				CurrencyCode:     parts[10],
+				CurrencyName:     parts[11],
+				Phone:            parts[12],
+				PostalCodeFormat: parts[13],
+				PostalCodeRegex:  parts[14],
+				Languages:        parts[15],
+				Neighbours:       neighbours,
 			})
 		}
 	}
@@ -88,111 +116,413 @@ func (p *Parser) ParseCountries() ([]model.Country, error) {
 	return countries, nil
 }
 
-// ParseCities parses cities1000.txt and filters by population
-func (p *Parser) ParseCities() ([]model.City, error) {
-	filePath := filepath.Join(p.dataDir, "cities1000.txt")
-
-	// Check if file is zipped
-	zipPath := filepath.Join(p.dataDir, "cities1000.zip")
-	if _, err := os.Stat(zipPath); err == nil {
-		return p.parseCitiesFromZip(zipPath)
-	}
+// ProcessCities streams cities1000.zip or cities1000.txt to callback in
+// batches, keeping cities of at least the configured population
+func (p *Parser) ProcessCities(callback func(batch []model.City) error) error {
+	return p.ProcessCitiesFrom(context.Background(), Resume{}, callback)
+}
 
-	file, err := os.Open(filePath)
+// ProcessCitiesFrom is ProcessCities resuming after resume.SkipLines
+func (p *Parser) ProcessCitiesFrom(ctx context.Context, resume Resume, callback func(batch []model.City) error) error {
+	file, err := p.openOptionalDataFile("cities1000")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open cities1000.txt: %w", err)
+		return err
+	}
+	if file == nil {
+		return fmt.Errorf("failed to open cities1000.txt: %w", os.ErrNotExist)
 	}
 	defer file.Close()
 
-	return p.parseCitiesFromReader(file)
+	return p.processCitiesFromReader(ctx, file, resume, callback)
 }
 
-func (p *Parser) parseCitiesFromZip(zipPath string) ([]model.City, error) {
-	r, err := zip.OpenReader(zipPath)
+// ParseCityIDs returns the geonameids of the cities ProcessCities delivers
+func (p *Parser) ParseCityIDs() (map[int]bool, error) {
+	ids := make(map[int]bool)
+	err := p.ProcessCities(func(batch []model.City) error {
+		for _, city := range batch {
+			ids[city.ID] = true
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open zip: %w", err)
+		return nil, err
 	}
-	defer r.Close()
+	return ids, nil
+}
 
-	for _, f := range r.File {
-		if strings.HasSuffix(f.Name, ".txt") {
-			rc, err := f.Open()
-			if err != nil {
-				return nil, fmt.Errorf("failed to open file in zip: %w", err)
-			}
-			defer rc.Close()
-			return p.parseCitiesFromReader(rc)
-		}
+func (p *Parser) processCitiesFromReader(ctx context.Context, reader io.Reader, resume Resume, callback func(batch []model.City) error) error {
+	batchSize := p.batchSize
+	if batchSize <= 0 {
+		batchSize = 10000
 	}
 
-	return nil, fmt.Errorf("no txt file found in zip")
+	parse := func(_ int64, line string) (model.City, bool) {
+		city, ok := parseGeoname(line)
+		// Use configured minPopulation
+		return city, ok && city.Population >= p.minPopulation
+	}
+
+	return streamLines(ctx, reader, "cities", p.workers, resume, parse,
+		&sliceBatcher[model.City]{size: batchSize},
+		func(batch []model.City) error {
+			if err := callback(batch); err != nil {
+				return fmt.Errorf("city callback error: %w", err)
+			}
+			return nil
+		})
 }
 
-func (p *Parser) parseCitiesFromReader(reader io.Reader) ([]model.City, error) {
-	scanner := bufio.NewScanner(reader)
-	var cities []model.City
+// parseGeoname parses a line in the geoname format shared by cities1000.txt
+// and the daily modifications files. It reports false for malformed lines.
+func parseGeoname(line string) (model.City, bool) {
+	parts := strings.Split(line, "\t")
+	if len(parts) < 19 {
+		return model.City{}, false
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, "\t")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return model.City{}, false
+	}
+
+	population, err := strconv.Atoi(parts[14])
+	if err != nil {
+		return model.City{}, false
+	}
+
+	lat, err := strconv.ParseFloat(parts[4], 64)
+	if err != nil {
+		return model.City{}, false
+	}
+
+	lon, err := strconv.ParseFloat(parts[5], 64)
+	if err != nil {
+		return model.City{}, false
+	}
+
+	var elevation *int
+	if parts[15] != "" {
+		elev, err := strconv.Atoi(parts[15])
+		if err == nil {
+			elevation = &elev
+		}
+	}
+
+	var timezone *string
+	if parts[17] != "" {
+		timezone = &parts[17]
+	}
+
+	return model.City{
+		ID:           id,
+		CountryCode:  parts[8],
+		NameDefault:  parts[1],
+		Population:   population,
+		Lat:          lat,
+		Lon:          lon,
+		Elevation:    elevation,
+		Timezone:     timezone,
+		Admin1Code:   parts[10],
+		Admin2Code:   parts[11],
+		Admin3Code:   parts[12],
+		Admin4Code:   parts[13],
+		FeatureClass: parts[6],
+		FeatureCode:  parts[7],
+	}, true
+}
 
-		if len(parts) < 19 {
+// ParseAdminDivisions parses admin1CodesASCII.txt and admin2Codes.txt.
+// Both files are optional; a missing file contributes no divisions.
+func (p *Parser) ParseAdminDivisions() ([]model.AdminDivision, error) {
+	var divisions []model.AdminDivision
+	for level, name := range []string{"admin1CodesASCII.txt", "admin2Codes.txt"} {
+		file, err := os.Open(filepath.Join(p.dataDir, name))
+		if errors.Is(err, os.ErrNotExist) {
 			continue
 		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
 
-		id, err := strconv.Atoi(parts[0])
+		parsed, err := parseAdminDivisionsFromReader(file, level+1)
+		file.Close()
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
 		}
+		divisions = append(divisions, parsed...)
+	}
+	return divisions, nil
+}
 
-		population, err := strconv.Atoi(parts[14])
-		// Use configured minPopulation
-		if err != nil || population < p.minPopulation {
+// parseAdminDivisionsFromReader reads "code, name, asciiname, geonameid"
+// rows, where code is "US.IL" (admin1) or "US.IL.031" (admin2)
+func parseAdminDivisionsFromReader(reader io.Reader, level int) ([]model.AdminDivision, error) {
+	scanner := bufio.NewScanner(reader)
+	var divisions []model.AdminDivision
+
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), "\t")
+		if len(parts) < 4 {
 			continue
 		}
 
-		lat, err := strconv.ParseFloat(parts[4], 64)
-		if err != nil {
+		code := parts[0]
+		countryCode, _, found := strings.Cut(code, ".")
+		if !found || parts[1] == "" {
 			continue
 		}
+		geonameID, _ := strconv.Atoi(parts[3])
+
+		divisions = append(divisions, model.AdminDivision{
+			Code:        code,
+			CountryCode: countryCode,
+			Level:       level,
+			NameDefault: parts[1],
+			GeonameID:   geonameID,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return divisions, nil
+}
+
+// Resume continues a streamed import where an earlier run stopped
+type Resume struct {
+	// SkipLines is the number of lines of the file already imported
+	SkipLines int64
+	// Checkpoint, if set, is called after every delivered batch with the
+	// number of lines imported so far
+	Checkpoint func(lines int64) error
+}
 
-		lon, err := strconv.ParseFloat(parts[5], 64)
+// checkpoint reports that lines lines have been imported
+func (r Resume) checkpoint(lines int64) error {
+	if r.Checkpoint == nil {
+		return nil
+	}
+	if err := r.Checkpoint(lines); err != nil {
+		return fmt.Errorf("checkpoint error: %w", err)
+	}
+	return nil
+}
+
+// ProcessPostalCodes streams the GeoNames postal code dump (postalCodes.zip,
+// which is export/zip/allCountries.zip, or the extracted postalCodes.txt) to
+// callback in batches. Only codes of the given countries are kept. The dump
+// is optional; without it no postal codes are imported.
+func (p *Parser) ProcessPostalCodes(countryCodes map[string]bool, callback func(batch []model.PostalCode) error) error {
+	return p.ProcessPostalCodesFrom(context.Background(), Resume{}, countryCodes, callback)
+}
+
+// ProcessPostalCodesFrom is ProcessPostalCodes resuming after
+// resume.SkipLines. Each code's ID is its line number in the dump, so
+// importing a line twice yields the same row.
+func (p *Parser) ProcessPostalCodesFrom(ctx context.Context, resume Resume, countryCodes map[string]bool, callback func(batch []model.PostalCode) error) error {
+	file, err := p.openOptionalDataFile("postalCodes")
+	if err != nil || file == nil {
+		return err
+	}
+	defer file.Close()
+
+	return p.processPostalCodesFromReader(ctx, file, resume, countryCodes, callback)
+}
+
+// ProcessHierarchy streams the parent/child pairs of hierarchy.zip or
+// hierarchy.txt to callback in batches. The file is optional; without it no
+// hierarchy is imported.
+func (p *Parser) ProcessHierarchy(callback func(batch []model.HierarchyEdge) error) error {
+	return p.ProcessHierarchyFrom(context.Background(), Resume{}, callback)
+}
+
+// ProcessHierarchyFrom is ProcessHierarchy resuming after resume.SkipLines
+func (p *Parser) ProcessHierarchyFrom(ctx context.Context, resume Resume, callback func(batch []model.HierarchyEdge) error) error {
+	file, err := p.openOptionalDataFile("hierarchy")
+	if err != nil || file == nil {
+		return err
+	}
+	defer file.Close()
+
+	return p.processHierarchyFromReader(ctx, file, resume, callback)
+}
+
+// openOptionalDataFile opens name.zip, reading its data file, or else
+// name.txt. It returns nil if neither exists.
+func (p *Parser) openOptionalDataFile(name string) (io.ReadCloser, error) {
+	zipPath := filepath.Join(p.dataDir, name+".zip")
+	if _, err := os.Stat(zipPath); err == nil {
+		archive, err := zip.OpenReader(zipPath)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to open zip: %w", err)
 		}
 
-		var elevation *int
-		if parts[15] != "" {
-			elev, err := strconv.Atoi(parts[15])
-			if err == nil {
-				elevation = &elev
+		for _, f := range archive.File {
+			// Archives from export/zip also hold readme.txt
+			if strings.HasSuffix(f.Name, ".txt") && !strings.EqualFold(filepath.Base(f.Name), "readme.txt") {
+				rc, err := f.Open()
+				if err != nil {
+					archive.Close()
+					return nil, fmt.Errorf("failed to open file in zip: %w", err)
+				}
+				return &zipEntry{ReadCloser: rc, archive: archive}, nil
 			}
 		}
+		archive.Close()
+		return nil, fmt.Errorf("no txt file found in %s.zip", name)
+	}
+
+	file, err := os.Open(filepath.Join(p.dataDir, name+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s.txt: %w", name, err)
+	}
+	return file, nil
+}
+
+// zipEntry is a file inside a zip archive; closing it closes both
+type zipEntry struct {
+	io.ReadCloser
+	archive *zip.ReadCloser
+}
+
+func (e *zipEntry) Close() error {
+	err := e.ReadCloser.Close()
+	if archiveErr := e.archive.Close(); err == nil {
+		err = archiveErr
+	}
+	return err
+}
+
+// processHierarchyFromReader reads "parentId, childId, type" rows
+func (p *Parser) processHierarchyFromReader(ctx context.Context, reader io.Reader, resume Resume, callback func(batch []model.HierarchyEdge) error) error {
+	batchSize := p.batchSize
+	if batchSize <= 0 {
+		batchSize = 10000
+	}
+
+	return streamLines(ctx, reader, "hierarchy", p.workers, resume, parseHierarchyEdge,
+		&sliceBatcher[model.HierarchyEdge]{size: batchSize},
+		func(batch []model.HierarchyEdge) error {
+			if err := callback(batch); err != nil {
+				return fmt.Errorf("hierarchy callback error: %w", err)
+			}
+			return nil
+		})
+}
+
+// parseHierarchyEdge parses a line of hierarchy.txt
+func parseHierarchyEdge(_ int64, line string) (model.HierarchyEdge, bool) {
+	parts := strings.Split(line, "\t")
+	if len(parts) < 2 {
+		return model.HierarchyEdge{}, false
+	}
+	parentID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return model.HierarchyEdge{}, false
+	}
+	childID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return model.HierarchyEdge{}, false
+	}
+
+	edge := model.HierarchyEdge{ParentID: parentID, ChildID: childID}
+	if len(parts) > 2 {
+		edge.Type = parts[2]
+	}
+	return edge, true
+}
+
+// processPostalCodesFromReader reads "country code, postal code, place name,
+// admin name1, admin code1, admin name2, admin code2, admin name3, admin
+// code3, latitude, longitude, accuracy" rows
+func (p *Parser) processPostalCodesFromReader(ctx context.Context, reader io.Reader, resume Resume, countryCodes map[string]bool, callback func(batch []model.PostalCode) error) error {
+	batchSize := p.batchSize
+	if batchSize <= 0 {
+		batchSize = 10000
+	}
 
-		var timezone *string
-		if parts[17] != "" {
-			timezone = &parts[17]
+	parse := func(line int64, text string) (model.PostalCode, bool) {
+		parts := strings.Split(text, "\t")
+		if len(parts) < 11 {
+			return model.PostalCode{}, false
+		}
+		if !countryCodes[parts[0]] || parts[1] == "" {
+			return model.PostalCode{}, false
 		}
 
-		city := model.City{
-			ID:          id,
-			CountryCode: parts[8],
-			NameDefault: parts[1],
-			Population:  population,
-			Lat:         lat,
-			Lon:         lon,
-			Elevation:   elevation,
-			Timezone:    timezone,
+		lat, err := strconv.ParseFloat(parts[9], 64)
+		if err != nil {
+			return model.PostalCode{}, false
+		}
+		lon, err := strconv.ParseFloat(parts[10], 64)
+		if err != nil {
+			return model.PostalCode{}, false
 		}
 
-		cities = append(cities, city)
-	}
+		var accuracy *int
+		if len(parts) > 11 && parts[11] != "" {
+			if value, err := strconv.Atoi(parts[11]); err == nil {
+				accuracy = &value
+			}
+		}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to scan cities: %w", err)
+		return model.PostalCode{
+			ID:          line,
+			CountryCode: parts[0],
+			PostalCode:  strings.ToUpper(parts[1]),
+			PlaceName:   parts[2],
+			Admin1Name:  parts[3],
+			Admin1Code:  parts[4],
+			Admin2Name:  parts[5],
+			Admin2Code:  parts[6],
+			Lat:         lat,
+			Lon:         lon,
+			Accuracy:    accuracy,
+		}, true
 	}
 
-	return cities, nil
+	return streamLines(ctx, reader, "postal codes", p.workers, resume, parse,
+		&sliceBatcher[model.PostalCode]{size: batchSize},
+		func(batch []model.PostalCode) error {
+			if err := callback(batch); err != nil {
+				return fmt.Errorf("postal code callback error: %w", err)
+			}
+			return nil
+		})
+}
+
+// AlternateNameTargets selects which places alternate names are collected
+// for and where each kind of translation is delivered. Places are matched by
+// geonameid; a nil callback disables that kind.
+type AlternateNameTargets struct {
+	CityIDs      map[int]bool
+	CityCallback func(batch []model.CityTranslation) error
+	// CityNameCallback receives every name of the cities in CityIDs, with
+	// its flags and untruncated language, unlike CityCallback which gets one
+	// display name per language
+	CityNameCallback func(batch []model.CityAlternateName) error
+
+	// CountryGeonameIDs maps a country's geonameid to its code; only codes
+	// present in CountryCodes are translated
+	CountryCodes      map[string]bool
+	CountryGeonameIDs map[int]string
+	CountryCallback   func(batch []model.CountryTranslation) error
+
+	// AdminGeonameIDs maps a division's geonameid to its code ("US.IL")
+	AdminGeonameIDs map[int]string
+	AdminCallback   func(batch []model.AdminDivisionTranslation) error
+
+	// ContinentGeonameIDs maps a continent's geonameid to its code ("EU")
+	ContinentGeonameIDs map[int]string
+	ContinentCallback   func(batch []model.ContinentTranslation) error
+
+	// Resume skips the lines imported by an earlier run; all batches are
+	// delivered before each checkpoint
+	Resume Resume
 }
 
 // ProcessAlternateNames processes alternateNames.txt using streaming approach to avoid OOM
@@ -212,13 +542,24 @@ func (p *Parser) ProcessAlternateNamesWithCountries(
 	cityCallback func(batch []model.CityTranslation) error,
 	countryCallback func(batch []model.CountryTranslation) error,
 ) error {
+	return p.ProcessAlternateNamesForTargets(context.Background(), AlternateNameTargets{
+		CityIDs:           cityIDs,
+		CityCallback:      cityCallback,
+		CountryCodes:      countryCodes,
+		CountryGeonameIDs: geonameIDToCountryCode,
+		CountryCallback:   countryCallback,
+	})
+}
+
+// ProcessAlternateNamesForTargets streams alternateNames and delivers the
+// translations of cities, countries and admin divisions in batches
+func (p *Parser) ProcessAlternateNamesForTargets(ctx context.Context, targets AlternateNameTargets) error {
 	filePath := filepath.Join(p.dataDir, "alternateNames.txt")
 
 	// Check if file is zipped
 	zipPath := filepath.Join(p.dataDir, "alternateNames.zip")
 	var reader io.Reader
 	if _, err := os.Stat(zipPath); err == nil {
-		fmt.Printf("DEBUG: Using alternateNames.zip\n")
 		r, err := zip.OpenReader(zipPath)
 		if err != nil {
 			return fmt.Errorf("failed to open zip: %w", err)
@@ -251,7 +592,6 @@ func (p *Parser) ProcessAlternateNamesWithCountries(
 			return fmt.Errorf("alternateNames file not found (checked %s and %s): %w", zipPath, filePath, err)
 		}
 
-		fmt.Printf("DEBUG: Using alternateNames.txt\n")
 		file, err := os.Open(filePath)
 		if err != nil {
 			return fmt.Errorf("failed to open alternateNames.txt: %w", err)
@@ -260,158 +600,326 @@ func (p *Parser) ProcessAlternateNamesWithCountries(
 		reader = file
 	}
 
-	return p.processAlternateNamesFromReaderWithCountryMapping(reader, cityIDs, countryCodes, geonameIDToCountryCode, cityCallback, countryCallback)
+	return p.processAlternateNamesFromReaderWithCountryMapping(ctx, reader, targets)
 }
 
-func (p *Parser) processAlternateNamesFromReaderWithCountryMapping(
-	reader io.Reader,
-	cityIDs map[int]bool,
-	countryCodes map[string]bool,
-	geonameIDToCountryCode map[int]string,
-	cityCallback func(batch []model.CityTranslation) error,
-	countryCallback func(batch []model.CountryTranslation) error,
-) error {
-	buf := make([]byte, 0, 64*1024)
-	scanner := bufio.NewScanner(reader)
-	scanner.Buffer(buf, 1024*1024)
-
+func (p *Parser) processAlternateNamesFromReaderWithCountryMapping(ctx context.Context, reader io.Reader, targets AlternateNameTargets) error {
 	batchSize := p.batchSize
 	if batchSize <= 0 {
 		batchSize = 10000
 	}
 
-	cityBatch := make([]model.CityTranslation, 0, batchSize)
-	countryBatch := make([]model.CountryTranslation, 0, batchSize)
-
-	// Maps to store index in batch for duplicate handling (prefer preferredName)
-	// Key: "id:lang", Value: index in batch
-	cityTransMap := make(map[string]int)
-	countryTransMap := make(map[string]int)
+	parse := func(_ int64, line string) (model.CityAlternateName, bool) {
+		return p.parseAlternateName(line)
+	}
+	return streamLines(ctx, reader, "alternateNames", p.workers, targets.Resume, parse,
+		newAlternateNameBatcher(targets, batchSize), targets.deliver)
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, "\t")
+// alternateNameBatch holds the translations and city names of a run of
+// alternateNames lines
+type alternateNameBatch struct {
+	cities     []model.CityTranslation
+	countries  []model.CountryTranslation
+	admins     []model.AdminDivisionTranslation
+	continents []model.ContinentTranslation
+	cityNames  []model.CityAlternateName
+}
 
-		if len(parts) < 4 {
-			continue
+// deliver passes all kinds of a batch to their callbacks, so that a
+// checkpoint taken after it covers every kind of translation
+func (targets AlternateNameTargets) deliver(batch alternateNameBatch) error {
+	if len(batch.admins) > 0 && targets.AdminCallback != nil {
+		if err := targets.AdminCallback(batch.admins); err != nil {
+			return fmt.Errorf("admin callback error: %w", err)
 		}
-
-		geonameID, err := strconv.Atoi(parts[1])
-		if err != nil {
-			continue
+	}
+	if len(batch.continents) > 0 && targets.ContinentCallback != nil {
+		if err := targets.ContinentCallback(batch.continents); err != nil {
+			return fmt.Errorf("continent callback error: %w", err)
 		}
-
-		lang := parts[2]
-		name := parts[3]
-
-		if name == "" || lang == "" {
-			continue
+	}
+	if len(batch.cities) > 0 && targets.CityCallback != nil {
+		if err := targets.CityCallback(batch.cities); err != nil {
+			return fmt.Errorf("city callback error: %w", err)
 		}
-
-		// 1. Skip historic and colloquial names
-		if len(parts) > 6 && parts[6] == "1" { // isColloquial
-			continue
+	}
+	if len(batch.countries) > 0 && targets.CountryCallback != nil {
+		if err := targets.CountryCallback(batch.countries); err != nil {
+			return fmt.Errorf("country callback error: %w", err)
 		}
-		if len(parts) > 7 && parts[7] == "1" { // isHistoric
-			continue
+	}
+	if len(batch.cityNames) > 0 && targets.CityNameCallback != nil {
+		if err := targets.CityNameCallback(batch.cityNames); err != nil {
+			return fmt.Errorf("city name callback error: %w", err)
 		}
+	}
+	return nil
+}
+
+// alternateNameBatcher sorts parsed alternate names into the translations
+// of targets. Each place keeps one translation per language: the first name
+// seen, unless a later one is preferred. A batch holds each translation
+// once; a preferred name found after its place's translation was delivered
+// goes into a later batch, whose upsert replaces it, while other names found
+// then are dropped. It runs on a single goroutine in file order, so the
+// outcome does not depend on the number of parser workers.
+type alternateNameBatcher struct {
+	targets   AlternateNameTargets
+	batchSize int
+	batch     alternateNameBatch
+
+	// seen holds every translation of the run, so that a name in a later
+	// batch replaces an earlier one only if it is preferred. It grows with
+	// the translations of the targeted places, not with the file. A resumed
+	// run replays the lines before its checkpoint into it.
+	seen map[translationKey]struct{}
+
+	// Index in batch of each translation, to prefer preferred names
+	cityIndex      map[translationKey]int
+	countryIndex   map[translationKey]int
+	adminIndex     map[translationKey]int
+	continentIndex map[translationKey]int
+}
+
+// translationKey is the language of a translation of the place with
+// geonameid geonameID
+type translationKey struct {
+	geonameID int
+	lang      string
+}
 
-		// 2. Check Preferred Name flag
-		isPreferred := false
-		if len(parts) > 4 && parts[4] == "1" {
-			isPreferred = true
+func newAlternateNameBatcher(targets AlternateNameTargets, batchSize int) *alternateNameBatcher {
+	b := &alternateNameBatcher{
+		targets:   targets,
+		batchSize: batchSize,
+		seen:      make(map[translationKey]struct{}),
+	}
+	b.take()
+	return b
+}
+
+func (b *alternateNameBatcher) add(record model.CityAlternateName) bool {
+	targets := b.targets
+	geonameID, name, isPreferred := record.CityID, record.Name, record.IsPreferred
+
+	// Keep every name of a city with its flags, as given
+	if targets.CityNameCallback != nil && targets.CityIDs[geonameID] {
+		b.batch.cityNames = append(b.batch.cityNames, record)
+	}
+
+	// Translations are display names: skip names without a language,
+	// historic and colloquial names
+	if record.Lang == "" || record.IsColloquial || record.IsHistoric {
+		return b.full()
+	}
+
+	t := b.placeTargets(geonameID)
+	if !t.any() {
+		return b.full()
+	}
+
+	key := translationKey{geonameID: geonameID, lang: translationLang(record.Lang)}
+	if _, seen := b.seen[key]; seen && !isPreferred {
+		return b.full()
+	}
+	b.seen[key] = struct{}{}
+
+	// Check if this is a city translation
+	if t.isCity {
+		if idx, exists := b.cityIndex[key]; exists {
+			b.batch.cities[idx].Name = name
+		} else {
+			b.batch.cities = append(b.batch.cities, model.CityTranslation{
+				CityID: geonameID,
+				Lang:   key.lang,
+				Name:   name,
+			})
+			b.cityIndex[key] = len(b.batch.cities) - 1
 		}
+	}
 
-		// 3. Skip technical codes
-		if lang == "link" || lang == "post" || lang == "iata" || lang == "icao" || lang == "faac" || lang == "fr_1793" || lang == "abbr" || lang == "wkdt" {
-			continue
+	// Check if this is a COUNTRY translation
+	if t.isCountry {
+		if idx, exists := b.countryIndex[key]; exists {
+			b.batch.countries[idx].Name = name
+		} else {
+			b.batch.countries = append(b.batch.countries, model.CountryTranslation{
+				CountryCode: t.countryCode,
+				Lang:        key.lang,
+				Name:        name,
+			})
+			b.countryIndex[key] = len(b.batch.countries) - 1
 		}
+	}
 
-		if len(lang) > 2 {
-			lang = lang[:2]
+	// Check if this is an ADMIN DIVISION translation
+	if t.isAdmin {
+		if idx, exists := b.adminIndex[key]; exists {
+			b.batch.admins[idx].Name = name
+		} else {
+			b.batch.admins = append(b.batch.admins, model.AdminDivisionTranslation{
+				DivisionCode: t.divisionCode,
+				Lang:         key.lang,
+				Name:         name,
+			})
+			b.adminIndex[key] = len(b.batch.admins) - 1
 		}
+	}
 
-		// 4. CHECK ALLOWED LANGUAGES
-		// If map is empty, allow all. If not empty, check existence.
-		if len(p.allowedLanguages) > 0 && !p.allowedLanguages[lang] {
-			continue
+	// Check if this is a CONTINENT translation
+	if t.isContinent {
+		if idx, exists := b.continentIndex[key]; exists {
+			b.batch.continents[idx].Name = name
+		} else {
+			b.batch.continents = append(b.batch.continents, model.ContinentTranslation{
+				ContinentCode: t.continentCode,
+				Lang:          key.lang,
+				Name:          name,
+			})
+			b.continentIndex[key] = len(b.batch.continents) - 1
 		}
+	}
 
-		// Check if this is a city translation
-		if cityIDs[geonameID] {
-			key := fmt.Sprintf("%d:%s", geonameID, lang)
-			if idx, exists := cityTransMap[key]; exists {
-				// If already exists in batch, but this one is preferred, overwrite it
-				if isPreferred {
-					cityBatch[idx].Name = name
-				}
-			} else {
-				// New entry
-				cityBatch = append(cityBatch, model.CityTranslation{
-					CityID: geonameID,
-					Lang:   lang,
-					Name:   name,
-				})
-				cityTransMap[key] = len(cityBatch) - 1
-			}
+	return b.full()
+}
 
-			if len(cityBatch) >= batchSize {
-				if cityCallback != nil {
-					if err := cityCallback(cityBatch); err != nil {
-						return fmt.Errorf("city callback error: %w", err)
-					}
-				}
-				cityBatch = cityBatch[:0]
-				cityTransMap = make(map[string]int)
-			}
-		}
+// replay rebuilds the translations seen from a record a resumed run skips,
+// so that the names after the checkpoint are picked as in an uninterrupted
+// run; the record itself was delivered before the checkpoint
+func (b *alternateNameBatcher) replay(record model.CityAlternateName) {
+	if record.Lang == "" || record.IsColloquial || record.IsHistoric {
+		return
+	}
+	if b.placeTargets(record.CityID).any() {
+		b.seen[translationKey{geonameID: record.CityID, lang: translationLang(record.Lang)}] = struct{}{}
+	}
+}
 
-		// Check if this is a COUNTRY translation
-		if countryCallback != nil && geonameIDToCountryCode != nil {
-			if countryCode, ok := geonameIDToCountryCode[geonameID]; ok && countryCodes[countryCode] {
-				key := fmt.Sprintf("%s:%s", countryCode, lang)
-				if idx, exists := countryTransMap[key]; exists {
-					// If already exists in batch, but this one is preferred, overwrite it
-					if isPreferred {
-						countryBatch[idx].Name = name
-					}
-				} else {
-					countryBatch = append(countryBatch, model.CountryTranslation{
-						CountryCode: countryCode,
-						Lang:        lang,
-						Name:        name,
-					})
-					countryTransMap[key] = len(countryBatch) - 1
-				}
+// placeTargets are the translations a geonameid is imported as
+type placeTargets struct {
+	isCity, isCountry, isAdmin, isContinent  bool
+	countryCode, divisionCode, continentCode string
+}
 
-				if len(countryBatch) >= batchSize {
-					if err := countryCallback(countryBatch); err != nil {
-						return fmt.Errorf("country callback error: %w", err)
-					}
-					countryBatch = countryBatch[:0]
-					countryTransMap = make(map[string]int)
-				}
-			}
-		}
+func (t placeTargets) any() bool {
+	return t.isCity || t.isCountry || t.isAdmin || t.isContinent
+}
+
+func (b *alternateNameBatcher) placeTargets(geonameID int) placeTargets {
+	targets := b.targets
+	t := placeTargets{isCity: targets.CityCallback != nil && targets.CityIDs[geonameID]}
+	if targets.CountryCallback != nil {
+		t.countryCode, t.isCountry = targets.CountryGeonameIDs[geonameID]
+		t.isCountry = t.isCountry && targets.CountryCodes[t.countryCode]
+	}
+	if targets.AdminCallback != nil {
+		t.divisionCode, t.isAdmin = targets.AdminGeonameIDs[geonameID]
 	}
+	if targets.ContinentCallback != nil {
+		t.continentCode, t.isContinent = targets.ContinentGeonameIDs[geonameID]
+	}
+	return t
+}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("failed to scan alternateNames: %w", err)
+// full reports whether any kind of the pending batch reached the batch size
+func (b *alternateNameBatcher) full() bool {
+	return max(len(b.batch.cities), len(b.batch.countries), len(b.batch.admins),
+		len(b.batch.continents), len(b.batch.cityNames)) >= b.batchSize
+}
+
+// take returns the pending batch; the next one starts empty, as the writer
+// may still hold this one
+func (b *alternateNameBatcher) take() (alternateNameBatch, bool) {
+	batch := b.batch
+	b.batch = alternateNameBatch{
+		cities:    make([]model.CityTranslation, 0, b.batchSize),
+		countries: make([]model.CountryTranslation, 0, b.batchSize),
 	}
+	b.cityIndex = make(map[translationKey]int)
+	b.countryIndex = make(map[translationKey]int)
+	b.adminIndex = make(map[translationKey]int)
+	b.continentIndex = make(map[translationKey]int)
 
-	if len(cityBatch) > 0 && cityCallback != nil {
-		if err := cityCallback(cityBatch); err != nil {
-			return fmt.Errorf("city callback error: %w", err)
-		}
+	return batch, len(batch.cities)+len(batch.countries)+len(batch.admins)+len(batch.continents)+len(batch.cityNames) > 0
+}
+
+// parseAlternateName parses a line of alternateNames.txt (or of a daily
+// alternateNamesModifications file) into a name of the place with geonameid
+// CityID. Lang is kept as given. It reports false for malformed lines,
+// technical codes (links, IATA codes…) and languages that are not allowed.
+func (p *Parser) parseAlternateName(line string) (model.CityAlternateName, bool) {
+	parts := strings.Split(line, "\t")
+	if len(parts) < 4 {
+		return model.CityAlternateName{}, false
 	}
 
-	if len(countryBatch) > 0 && countryCallback != nil {
-		if err := countryCallback(countryBatch); err != nil {
-			return fmt.Errorf("country callback error: %w", err)
-		}
+	alternateNameID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return model.CityAlternateName{}, false
+	}
+	geonameID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return model.CityAlternateName{}, false
 	}
 
-	return nil
+	lang := parts[2]
+	name := parts[3]
+	if name == "" {
+		return model.CityAlternateName{}, false
+	}
+
+	// Skip technical codes
+	switch lang {
+	case "link", "post", "iata", "icao", "faac", "fr_1793", "abbr", "wkdt":
+		return model.CityAlternateName{}, false
+	}
+
+	// If the allow list is empty, allow all (names without a language too)
+	if len(p.allowedLanguages) > 0 && !p.allowedLanguages[translationLang(lang)] {
+		return model.CityAlternateName{}, false
+	}
+
+	return model.CityAlternateName{
+		ID:           alternateNameID,
+		CityID:       geonameID,
+		Lang:         lang,
+		Name:         name,
+		IsPreferred:  len(parts) > 4 && parts[4] == "1",
+		IsShort:      len(parts) > 5 && parts[5] == "1",
+		IsColloquial: len(parts) > 6 && parts[6] == "1",
+		IsHistoric:   len(parts) > 7 && parts[7] == "1",
+	}, true
+}
+
+// translationLang returns the two-letter language translations are stored
+// under ("zh" for "zh-CN")
+func translationLang(lang string) string {
+	if len(lang) > 2 {
+		return lang[:2]
+	}
+	return lang
+}
+
+// Continents are the GeoNames continents. They are not part of any data
+// file other than hierarchy.txt, which refers to them by geonameid.
+var Continents = []model.Continent{
+	{Code: "AF", GeonameID: 6255146, NameDefault: "Africa"},
+	{Code: "AN", GeonameID: 6255152, NameDefault: "Antarctica"},
+	{Code: "AS", GeonameID: 6255147, NameDefault: "Asia"},
+	{Code: "EU", GeonameID: 6255148, NameDefault: "Europe"},
+	{Code: "NA", GeonameID: 6255149, NameDefault: "North America"},
+	{Code: "OC", GeonameID: 6255151, NameDefault: "Oceania"},
+	{Code: "SA", GeonameID: 6255150, NameDefault: "South America"},
+}
+
+// CreateContinentGeonameIDMap creates a mapping from continent GeonameID to continent code
+func CreateContinentGeonameIDMap(continents []model.Continent) map[int]string {
+	m := make(map[int]string)
+	for _, continent := range continents {
+		m[continent.GeonameID] = continent.Code
+	}
+	return m
 }
 
 // CreateCountryGeonameIDMap creates a mapping from Country GeonameID to Country Code
@@ -425,6 +933,29 @@ func CreateCountryGeonameIDMap(countries []model.Country) map[int]string {
 	return m
 }
 
+// FilterAdminDivisions drops divisions of countries that were not imported,
+// which the admin_divisions foreign key would reject
+func FilterAdminDivisions(divisions []model.AdminDivision, countryCodes map[string]bool) []model.AdminDivision {
+	filtered := divisions[:0:0]
+	for _, division := range divisions {
+		if countryCodes[division.CountryCode] {
+			filtered = append(filtered, division)
+		}
+	}
+	return filtered
+}
+
+// CreateAdminGeonameIDMap creates a mapping from division GeonameID to division code
+func CreateAdminGeonameIDMap(divisions []model.AdminDivision) map[int]string {
+	m := make(map[int]string)
+	for _, division := range divisions {
+		if division.GeonameID != 0 {
+			m[division.GeonameID] = division.Code
+		}
+	}
+	return m
+}
+
 // CreateCityIDMap creates a map of city IDs from cities slice
 func CreateCityIDMap(cities []model.City) map[int]bool {
 	m := make(map[int]bool)
@@ -1,6 +1,9 @@
 package seeder
 
 import (
+	"archive/zip"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -59,6 +62,53 @@ AE	ARE	784	AE	United Arab Emirates	Abu Dhabi	82880	4975593	AS	.ae	AED	Dirham	971
 	assert.Len(t, countries, 2)
 	assert.Equal(t, "Andorra", countries[0].NameDefault)
 	assert.Equal(t, 3041565, countries[0].GeonameID)
+
+	andorra := countries[0]
+	assert.Equal(t, "AND", andorra.ISO3)
+	assert.Equal(t, "020", andorra.ISONumeric)
+	assert.Equal(t, "Andorra la Vella", andorra.Capital)
+	assert.Equal(t, 468.0, andorra.AreaSqKm)
+	assert.Equal(t, int64(84000), andorra.Population)
+	assert.Equal(t, "EU", andorra.Continent)
+	assert.Equal(t, ".ad", andorra.TLD)
+	assert.Equal(t, "EUR", andorra.CurrencyCode)
+	assert.Equal(t, "Euro", andorra.CurrencyName)
+	assert.Equal(t, "376", andorra.Phone)
+	assert.Equal(t, "AD###", andorra.PostalCodeFormat)
+	assert.Equal(t, "ca", andorra.Languages)
+	assert.Equal(t, "ES,FR", andorra.Neighbours)
+	assert.Equal(t, "ar-AE,fa,en,hi,ur", countries[1].Languages)
+}
+
+func TestParser_ProcessCities(t *testing.T) {
+	tmpDir := t.TempDir()
+	testData := strings.Join([]string{
+		"2950159\tBerlin\tBerlin\tBerlin\t52.52437\t13.41053\tP\tPPLC\tDE\t\t16\t00\t11000\t11000000\t3426354\t74\t43\tEurope/Berlin\t2022-03-09",
+		"2878044\tLeipzig\tLeipzig\t\t51.33962\t12.37129\tP\tPPLA3\tDE\t\t13\t00\t14713\t14713000\t504971\t\t113\tEurope/Berlin\t2019-09-05",
+		"1\tHamlet\tHamlet\t\t51.0\t12.0\tP\tPPL\tDE\t\t13\t\t\t\t50\t\t113\tEurope/Berlin\t2019-09-05",
+	}, "\n")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "cities1000.txt"), []byte(testData), 0644))
+
+	parser := NewParser(tmpDir, config.SeederConfig{BatchSize: 100, MinPopulation: 1000})
+	var cities []model.City
+	err := parser.ProcessCities(func(batch []model.City) error {
+		cities = append(cities, batch...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, cities, 2, "cities below the minimum population are skipped")
+
+	berlin := cities[0]
+	assert.Equal(t, "P", berlin.FeatureClass)
+	assert.Equal(t, "PPLC", berlin.FeatureCode)
+	assert.Equal(t, "16", berlin.Admin1Code)
+	assert.Equal(t, "00", berlin.Admin2Code)
+	assert.Equal(t, "11000", berlin.Admin3Code)
+	assert.Equal(t, "11000000", berlin.Admin4Code)
+	require.NotNil(t, berlin.Elevation)
+	assert.Equal(t, 74, *berlin.Elevation)
+	assert.Nil(t, cities[1].Elevation)
+	assert.Equal(t, "PPLA3", cities[1].FeatureCode)
 }
 
 func TestParser_ProcessAlternateNames(t *testing.T) {
@@ -102,8 +152,8 @@ func TestParser_ProcessAlternateNames(t *testing.T) {
 		}
 
 		reader := strings.NewReader(inputData)
-		err := parser.processAlternateNamesFromReaderWithCountryMapping(
-			reader, cityIDs, nil, nil, callback, nil,
+		err := parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(),
+			reader, AlternateNameTargets{CityIDs: cityIDs, CityCallback: callback},
 		)
 		require.NoError(t, err)
 
@@ -141,8 +191,8 @@ func TestParser_ProcessAlternateNames(t *testing.T) {
 		}
 
 		reader := strings.NewReader(inputData)
-		err := parser.processAlternateNamesFromReaderWithCountryMapping(
-			reader, cityIDs, nil, nil, callback, nil,
+		err := parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(),
+			reader, AlternateNameTargets{CityIDs: cityIDs, CityCallback: callback},
 		)
 		require.NoError(t, err)
 
@@ -155,3 +205,353 @@ func TestParser_ProcessAlternateNames(t *testing.T) {
 		}
 	})
 }
+
+func TestParser_ParseAdminDivisions(t *testing.T) {
+	tmpDir := t.TempDir()
+	admin1 := "US.IL\tIllinois\tIllinois\t4896861\nDE.16\tBerlin\tBerlin\t2950157\nbroken line\n"
+	admin2 := "US.IL.031\tCook County\tCook County\t4888671\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "admin1CodesASCII.txt"), []byte(admin1), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "admin2Codes.txt"), []byte(admin2), 0644))
+
+	parser := NewParser(tmpDir, config.SeederConfig{BatchSize: 100})
+	divisions, err := parser.ParseAdminDivisions()
+	require.NoError(t, err)
+	require.Len(t, divisions, 3)
+
+	assert.Equal(t, model.AdminDivision{
+		Code: "US.IL", CountryCode: "US", Level: 1, NameDefault: "Illinois", GeonameID: 4896861,
+	}, divisions[0])
+	assert.Equal(t, "DE", divisions[1].CountryCode)
+	assert.Equal(t, 2, divisions[2].Level)
+	assert.Equal(t, "US", divisions[2].CountryCode)
+
+	filtered := FilterAdminDivisions(divisions, map[string]bool{"US": true})
+	assert.Len(t, filtered, 2)
+	assert.Len(t, divisions, 3, "filtering must not modify the input")
+
+	ids := CreateAdminGeonameIDMap(divisions)
+	assert.Equal(t, "US.IL.031", ids[4888671])
+}
+
+func TestParser_ParseAdminDivisions_MissingFiles(t *testing.T) {
+	parser := NewParser(t.TempDir(), config.SeederConfig{BatchSize: 100})
+	divisions, err := parser.ParseAdminDivisions()
+	require.NoError(t, err)
+	assert.Empty(t, divisions)
+}
+
+func TestParser_ProcessAlternateNames_AdminDivisions(t *testing.T) {
+	inputData := `
+1	4896861	de	Illinois	0	0	0	0
+2	4896861	ru	Иллинойс	0	0	0	0
+3	4896861	ru	Штат Иллинойс	1	0	0	0
+4	100	en	London	0	0	0	0
+`
+	parser := NewParser("", config.SeederConfig{BatchSize: 10})
+
+	var cities []model.CityTranslation
+	var admins []model.AdminDivisionTranslation
+	err := parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(), strings.NewReader(inputData), AlternateNameTargets{
+		CityIDs: map[int]bool{100: true},
+		CityCallback: func(batch []model.CityTranslation) error {
+			cities = append(cities, batch...)
+			return nil
+		},
+		AdminGeonameIDs: map[int]string{4896861: "US.IL"},
+		AdminCallback: func(batch []model.AdminDivisionTranslation) error {
+			admins = append(admins, batch...)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, cities, 1)
+	assert.ElementsMatch(t, []model.AdminDivisionTranslation{
+		{DivisionCode: "US.IL", Lang: "de", Name: "Illinois"},
+		{DivisionCode: "US.IL", Lang: "ru", Name: "Штат Иллинойс"},
+	}, admins)
+}
+
+func TestParser_ProcessPostalCodes(t *testing.T) {
+	tmpDir := t.TempDir()
+	testData := strings.Join([]string{
+		"DE\t10115\tBerlin\tBerlin\tBE\t\t00\tBerlin, Stadt\t11000\t52.5323\t13.3846\t4",
+		"DE\t14467\tPotsdam\tBrandenburg\tBB\t\t00\tPotsdam\t12054\t52.4\t13.0667\t",
+		"GB\tSW1A\tWestminster\tEngland\tENG\tGreater London\t11609024\t\t\t51.5\t-0.1333\t6",
+		"FR\t75001\tParis 01\tÎle-de-France\t11\tParis\t75\t\t\t48.8592\t2.3417\t5",
+		"DE\t99999\tBroken\t\t\t\t\t\t\tnorth\t13.0\t1",
+	}, "\n")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "postalCodes.txt"), []byte(testData), 0644))
+
+	parser := NewParser(tmpDir, config.SeederConfig{BatchSize: 2})
+	var batches [][]model.PostalCode
+	err := parser.ProcessPostalCodes(map[string]bool{"DE": true, "GB": true}, func(batch []model.PostalCode) error {
+		batches = append(batches, batch)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0], 2)
+	require.Len(t, batches[1], 1, "unknown countries and bad coordinates are skipped")
+
+	berlin := batches[0][0]
+	assert.Equal(t, "Berlin", berlin.Admin1Name)
+	assert.Equal(t, "BE", berlin.Admin1Code)
+	assert.Equal(t, 52.5323, berlin.Lat)
+	require.NotNil(t, berlin.Accuracy)
+	assert.Equal(t, 4, *berlin.Accuracy)
+	assert.Nil(t, batches[0][1].Accuracy)
+	assert.Equal(t, "Greater London", batches[1][0].Admin2Name)
+	assert.Equal(t, []int64{1, 2, 3}, []int64{berlin.ID, batches[0][1].ID, batches[1][0].ID}, "IDs are line numbers")
+
+	t.Run("Resume", func(t *testing.T) {
+		var codes []model.PostalCode
+		var checkpoints []int64
+		err := parser.ProcessPostalCodesFrom(context.Background(), Resume{
+			SkipLines:  2,
+			Checkpoint: func(lines int64) error { checkpoints = append(checkpoints, lines); return nil },
+		}, map[string]bool{"DE": true, "GB": true}, func(batch []model.PostalCode) error {
+			codes = append(codes, batch...)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, codes, 1)
+		assert.Equal(t, int64(3), codes[0].ID)
+		assert.Equal(t, []int64{5}, checkpoints)
+	})
+}
+
+func TestParser_ProcessPostalCodes_MissingFile(t *testing.T) {
+	parser := NewParser(t.TempDir(), config.SeederConfig{BatchSize: 100})
+	err := parser.ProcessPostalCodes(map[string]bool{"DE": true}, func(batch []model.PostalCode) error {
+		t.Fatal("no batches expected")
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestParser_ProcessHierarchy(t *testing.T) {
+	testData := "6295630\t6255148\t\n6255148\t2921044\nbroken\t1\n2921044\t2950157\tADM\n"
+	var edges []model.HierarchyEdge
+	collect := func(batch []model.HierarchyEdge) error {
+		edges = append(edges, batch...)
+		return nil
+	}
+
+	t.Run("Plain file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hierarchy.txt"), []byte(testData), 0644))
+
+		edges = nil
+		parser := NewParser(tmpDir, config.SeederConfig{BatchSize: 2})
+		require.NoError(t, parser.ProcessHierarchy(collect))
+		assert.Equal(t, []model.HierarchyEdge{
+			{ParentID: 6295630, ChildID: 6255148},
+			{ParentID: 6255148, ChildID: 2921044},
+			{ParentID: 2921044, ChildID: 2950157, Type: "ADM"},
+		}, edges)
+	})
+
+	t.Run("Zip archive with a readme", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		file, err := os.Create(filepath.Join(tmpDir, "hierarchy.zip"))
+		require.NoError(t, err)
+		archive := zip.NewWriter(file)
+		for name, content := range map[string]string{"readme.txt": "not data", "hierarchy.txt": testData} {
+			w, err := archive.Create(name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, archive.Close())
+		require.NoError(t, file.Close())
+
+		edges = nil
+		parser := NewParser(tmpDir, config.SeederConfig{BatchSize: 100})
+		require.NoError(t, parser.ProcessHierarchy(collect))
+		assert.Len(t, edges, 3)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		edges = nil
+		parser := NewParser(t.TempDir(), config.SeederConfig{BatchSize: 100})
+		require.NoError(t, parser.ProcessHierarchy(collect))
+		assert.Empty(t, edges)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hierarchy.txt"), []byte(testData), 0644))
+
+		edges = nil
+		var checkpoints []int64
+		parser := NewParser(tmpDir, config.SeederConfig{BatchSize: 1})
+		require.NoError(t, parser.ProcessHierarchyFrom(context.Background(), Resume{
+			SkipLines:  1,
+			Checkpoint: func(lines int64) error { checkpoints = append(checkpoints, lines); return nil },
+		}, collect))
+		assert.Equal(t, []model.HierarchyEdge{
+			{ParentID: 6255148, ChildID: 2921044},
+			{ParentID: 2921044, ChildID: 2950157, Type: "ADM"},
+		}, edges)
+		assert.Equal(t, []int64{2, 4, 4}, checkpoints, "a checkpoint follows each batch and the end of the file")
+	})
+}
+
+func TestParser_ProcessAlternateNames_Continents(t *testing.T) {
+	inputData := `
+1	6255148	de	Europa	0	0	0	0
+2	6255148	fr	Europe	0	0	0	0
+3	6255148	fr	Le continent européen	0	0	1	0
+4	6255146	de	Afrika	1	0	0	0
+`
+	parser := NewParser("", config.SeederConfig{BatchSize: 10})
+
+	var continents []model.ContinentTranslation
+	err := parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(), strings.NewReader(inputData), AlternateNameTargets{
+		ContinentGeonameIDs: CreateContinentGeonameIDMap(Continents),
+		ContinentCallback: func(batch []model.ContinentTranslation) error {
+			continents = append(continents, batch...)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []model.ContinentTranslation{
+		{ContinentCode: "EU", Lang: "de", Name: "Europa"},
+		{ContinentCode: "EU", Lang: "fr", Name: "Europe"},
+		{ContinentCode: "AF", Lang: "de", Name: "Afrika"},
+	}, continents)
+}
+
+func TestParser_ProcessAlternateNames_Resume(t *testing.T) {
+	inputData := "1\t100\ten\tLondon\n" +
+		"2\t3017382\tde\tFrankreich\n" +
+		"3\t100\tde\tLondon\n" +
+		"4\t100\tfr\tLondres\n"
+	parser := NewParser("", config.SeederConfig{BatchSize: 2})
+
+	var cities []model.CityTranslation
+	var countries []model.CountryTranslation
+	var checkpoints []int64
+	var delivered []int
+	targets := AlternateNameTargets{
+		CityIDs:           map[int]bool{100: true},
+		CountryCodes:      map[string]bool{"FR": true},
+		CountryGeonameIDs: map[int]string{3017382: "FR"},
+		CityCallback: func(batch []model.CityTranslation) error {
+			cities = append(cities, batch...)
+			return nil
+		},
+		CountryCallback: func(batch []model.CountryTranslation) error {
+			countries = append(countries, batch...)
+			return nil
+		},
+		Resume: Resume{Checkpoint: func(lines int64) error {
+			// Every batch is delivered before the checkpoint
+			assert.Len(t, countries, 1)
+			checkpoints = append(checkpoints, lines)
+			delivered = append(delivered, len(cities))
+			return nil
+		}},
+	}
+
+	t.Run("From the start", func(t *testing.T) {
+		require.NoError(t, parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(), strings.NewReader(inputData), targets))
+		assert.Equal(t, []int64{3, 4}, checkpoints)
+		assert.Equal(t, []int{2, 3}, delivered)
+	})
+
+	t.Run("After a checkpoint", func(t *testing.T) {
+		cities, countries, checkpoints = nil, nil, nil
+		targets.Resume.SkipLines = 3
+		targets.Resume.Checkpoint = func(lines int64) error {
+			checkpoints = append(checkpoints, lines)
+			return nil
+		}
+		require.NoError(t, parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(), strings.NewReader(inputData), targets))
+		assert.Equal(t, []model.CityTranslation{{CityID: 100, Lang: "fr", Name: "Londres"}}, cities)
+		assert.Empty(t, countries)
+		assert.Equal(t, []int64{4}, checkpoints)
+	})
+}
+
+func TestParser_ProcessAlternateNames_CityNames(t *testing.T) {
+	inputData := "1\t2950159\tde\tBerlin\t1\t0\t0\t0\n" +
+		"2\t2950159\tde\tSpree-Athen\t0\t0\t1\t0\n" +
+		"3\t2950159\tla\tBerolinum\t0\t0\t0\t1\n" +
+		"4\t2950159\tzh-CN\t柏林\t0\t1\t0\t0\n" +
+		"5\t2950159\t\tBerlin\n" +
+		"6\t2950159\tlink\thttps://en.wikipedia.org/wiki/Berlin\n" +
+		"7\t2867714\tde\tMünchen\n"
+	parser := NewParser("", config.SeederConfig{BatchSize: 2})
+
+	var names []model.CityAlternateName
+	var translations []model.CityTranslation
+	err := parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(), strings.NewReader(inputData), AlternateNameTargets{
+		CityIDs: map[int]bool{2950159: true},
+		CityCallback: func(batch []model.CityTranslation) error {
+			translations = append(translations, batch...)
+			return nil
+		},
+		CityNameCallback: func(batch []model.CityAlternateName) error {
+			names = append(names, batch...)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []model.CityAlternateName{
+		{ID: 1, CityID: 2950159, Lang: "de", Name: "Berlin", IsPreferred: true},
+		{ID: 2, CityID: 2950159, Lang: "de", Name: "Spree-Athen", IsColloquial: true},
+		{ID: 3, CityID: 2950159, Lang: "la", Name: "Berolinum", IsHistoric: true},
+		{ID: 4, CityID: 2950159, Lang: "zh-CN", Name: "柏林", IsShort: true},
+		{ID: 5, CityID: 2950159, Lang: "", Name: "Berlin"},
+	}, names)
+
+	// Translations still hold one display name per language
+	assert.ElementsMatch(t, []model.CityTranslation{
+		{CityID: 2950159, Lang: "de", Name: "Berlin"},
+		{CityID: 2950159, Lang: "zh", Name: "柏林"},
+	}, translations)
+}
+
+func TestParser_ProcessAlternateNames_PreferredAcrossBatches(t *testing.T) {
+	// The city names of 100 fill the first batch before its preferred
+	// English name shows up; those of 200 end it after the preferred one
+	inputData := "1\t100\ten\tLondon Town\n" +
+		"2\t100\tde\tLondon\n" +
+		"3\t100\ten\tLondon\t1\n" +
+		"4\t100\ten\tLondres\n" +
+		"5\t200\ten\tParis\t1\n" +
+		"6\t200\tfr\tParis\n" +
+		"7\t200\ten\tParigi\n" +
+		"8\t200\ten\tLutetia\n"
+	parser := NewParser("", config.SeederConfig{BatchSize: 2})
+
+	var batches [][]model.CityTranslation
+	err := parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(), strings.NewReader(inputData), AlternateNameTargets{
+		CityIDs: map[int]bool{100: true, 200: true},
+		CityCallback: func(batch []model.CityTranslation) error {
+			batches = append(batches, batch)
+			return nil
+		},
+		CityNameCallback: func(batch []model.CityAlternateName) error { return nil },
+	})
+	require.NoError(t, err)
+
+	// Translations are upserted, so the last delivered name of each wins
+	final := map[string]string{}
+	for _, batch := range batches {
+		for _, translation := range batch {
+			final[fmt.Sprintf("%d:%s", translation.CityID, translation.Lang)] = translation.Name
+		}
+	}
+	assert.Greater(t, len(batches), 1)
+	assert.Equal(t, map[string]string{
+		"100:en": "London",
+		"100:de": "London",
+		"200:en": "Paris",
+		"200:fr": "Paris",
+	}, final)
+}
@@ -0,0 +1,234 @@
+package seeder
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// pipelineChunkLines is the number of lines handed to a parser worker at
+// once, so that channel operations are not paid per line
+const pipelineChunkLines = 1024
+
+// numbered is a parsed record with the number of its line
+type numbered[R any] struct {
+	line   int64
+	record R
+}
+
+// lineChunk is a run of consecutive lines starting at line first. Its
+// records are sent on result, which has room for them, so workers never
+// wait for the batcher.
+type lineChunk[R any] struct {
+	first int64
+	lines []string
+	// last is the number of lines read up to the end of the chunk,
+	// including skipped ones
+	last   int64
+	result chan []numbered[R]
+}
+
+// batcher groups records into batches in file order. add reports whether
+// the pending batch is full; take returns it, false if it is empty, and
+// starts a new one.
+type batcher[R, B any] interface {
+	add(record R) bool
+	take() (B, bool)
+}
+
+// replayer is a batcher whose batches depend on earlier records. A resumed
+// run parses the lines it skips too and replays their records, which were
+// delivered before the checkpoint, so that it batches the rest as an
+// uninterrupted run would.
+type replayer[R any] interface {
+	replay(record R)
+}
+
+// pendingBatch is a batch for the writer and the lines it completes
+type pendingBatch[B any] struct {
+	batch B
+	ok    bool
+	lines int64
+}
+
+// streamLines runs the lines of reader after resume.SkipLines through a
+// pipeline: a reader hands chunks of lines to workers, which parse them
+// concurrently; a batcher collects their records in file order into b;
+// and a writer delivers each batch to write and then checkpoints the lines
+// it covers. Parsing, batching and writing overlap, while the bounded
+// channels keep only a few chunks and batches in memory. The first error,
+// or cancelling ctx, stops every stage.
+func streamLines[R, B any](
+	ctx context.Context,
+	reader io.Reader,
+	name string,
+	workers int,
+	resume Resume,
+	parse func(line int64, text string) (R, bool),
+	b batcher[R, B],
+	write func(batch B) error,
+) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	g, ctx := errgroup.WithContext(ctx)
+
+	chunks := make(chan lineChunk[R], workers)
+	// ordered holds the chunks in file order until the batcher gets to
+	// them; its capacity bounds how far parsing runs ahead
+	ordered := make(chan lineChunk[R], 2*workers)
+	batches := make(chan pendingBatch[B], 1)
+	rb, replays := b.(replayer[R])
+
+	g.Go(func() error {
+		defer close(chunks)
+		defer close(ordered)
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		var line int64
+		chunk := lineChunk[R]{}
+		send := func() error {
+			chunk.last = line
+			chunk.result = make(chan []numbered[R], 1)
+			select {
+			case ordered <- chunk:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if len(chunk.lines) == 0 {
+				chunk.result <- nil
+			} else {
+				select {
+				case chunks <- chunk:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			chunk = lineChunk[R]{}
+			return nil
+		}
+
+		for scanner.Scan() {
+			if line++; line <= resume.SkipLines && !replays {
+				continue
+			}
+			if len(chunk.lines) == 0 {
+				chunk.first = line
+				chunk.lines = make([]string, 0, pipelineChunkLines)
+			}
+			chunk.lines = append(chunk.lines, scanner.Text())
+			if len(chunk.lines) == pipelineChunkLines {
+				if err := send(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", name, err)
+		}
+		// The last chunk may be empty; it still carries the line count
+		return send()
+	})
+
+	for range workers {
+		g.Go(func() error {
+			for chunk := range chunks {
+				records := make([]numbered[R], 0, len(chunk.lines))
+				for i, text := range chunk.lines {
+					if record, ok := parse(chunk.first+int64(i), text); ok {
+						records = append(records, numbered[R]{line: chunk.first + int64(i), record: record})
+					}
+				}
+				chunk.result <- records
+			}
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		defer close(batches)
+
+		send := func(pending pendingBatch[B]) error {
+			select {
+			case batches <- pending:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		var lines int64
+		for chunk := range ordered {
+			var records []numbered[R]
+			select {
+			case records = <-chunk.result:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			for _, r := range records {
+				if r.line <= resume.SkipLines {
+					rb.replay(r.record)
+					continue
+				}
+				if b.add(r.record) {
+					batch, ok := b.take()
+					if err := send(pendingBatch[B]{batch: batch, ok: ok, lines: r.line}); err != nil {
+						return err
+					}
+				}
+			}
+			lines = chunk.last
+		}
+		// The reader stops early only on errors
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch, ok := b.take()
+		return send(pendingBatch[B]{batch: batch, ok: ok, lines: lines})
+	})
+
+	g.Go(func() error {
+		for pending := range batches {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if pending.ok {
+				if err := write(pending.batch); err != nil {
+					return err
+				}
+			}
+			if err := resume.checkpoint(pending.lines); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return g.Wait()
+}
+
+// sliceBatcher collects records into batches of size
+type sliceBatcher[R any] struct {
+	size  int
+	batch []R
+}
+
+func (b *sliceBatcher[R]) add(record R) bool {
+	if b.batch == nil {
+		b.batch = make([]R, 0, b.size)
+	}
+	b.batch = append(b.batch, record)
+	return len(b.batch) >= b.size
+}
+
+func (b *sliceBatcher[R]) take() ([]R, bool) {
+	batch := b.batch
+	b.batch = nil
+	return batch, len(batch) > 0
+}
@@ -0,0 +1,143 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/config"
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// alternateNamesInput spans several chunks. Each city has a plain name, a
+// preferred one in the same language a few chunks later, and a second
+// preferred one that wins over it.
+func alternateNamesInput(cities int) string {
+	var sb strings.Builder
+	id := 0
+	line := func(cityID int, name, preferred string) {
+		id++
+		fmt.Fprintf(&sb, "%d\t%d\ten\t%s\t%s\n", id, cityID, name, preferred)
+	}
+	for pass, name := range []string{"Plain", "Preferred", "Latest"} {
+		for city := 1; city <= cities; city++ {
+			preferred := ""
+			if pass > 0 {
+				preferred = "1"
+			}
+			line(city, fmt.Sprintf("%s %d", name, city), preferred)
+			// Names of other places are parsed but dropped
+			line(1000000+city, "Elsewhere", "")
+		}
+	}
+	return sb.String()
+}
+
+func TestStreamLines_AlternateNamesMatchAcrossWorkers(t *testing.T) {
+	const cities = pipelineChunkLines
+	input := alternateNamesInput(cities)
+	cityIDs := make(map[int]bool, cities)
+	for city := 1; city <= cities; city++ {
+		cityIDs[city] = true
+	}
+
+	run := func(workers int) ([]model.CityTranslation, []int64) {
+		parser := NewParser("", config.SeederConfig{BatchSize: 3 * cities, Workers: workers})
+		var translations []model.CityTranslation
+		var checkpoints []int64
+		err := parser.processAlternateNamesFromReaderWithCountryMapping(context.Background(), strings.NewReader(input), AlternateNameTargets{
+			CityIDs: cityIDs,
+			CityCallback: func(batch []model.CityTranslation) error {
+				translations = append(translations, batch...)
+				return nil
+			},
+			Resume: Resume{Checkpoint: func(lines int64) error {
+				checkpoints = append(checkpoints, lines)
+				return nil
+			}},
+		})
+		require.NoError(t, err)
+		return translations, checkpoints
+	}
+
+	translations, checkpoints := run(1)
+	require.Len(t, translations, cities, "one translation per city and language")
+	for i, translation := range translations {
+		assert.Equal(t, fmt.Sprintf("Latest %d", i+1), translation.Name, "the last preferred name wins")
+	}
+	assert.Equal(t, []int64{int64(6 * cities)}, checkpoints)
+
+	for _, workers := range []int{2, 8} {
+		parallel, parallelCheckpoints := run(workers)
+		assert.Equal(t, translations, parallel, "workers: %d", workers)
+		assert.Equal(t, checkpoints, parallelCheckpoints, "workers: %d", workers)
+	}
+}
+
+func TestStreamLines_Checkpoints(t *testing.T) {
+	var sb strings.Builder
+	for i := 1; i <= 2*pipelineChunkLines+10; i++ {
+		fmt.Fprintf(&sb, "%d\n", i)
+	}
+	// Every tenth line is kept
+	parse := func(line int64, text string) (int64, bool) { return line, line%10 == 0 }
+
+	var batches [][]int64
+	var checkpoints []int64
+	err := streamLines(context.Background(), strings.NewReader(sb.String()), "numbers", 4,
+		Resume{SkipLines: 5, Checkpoint: func(lines int64) error {
+			checkpoints = append(checkpoints, lines)
+			return nil
+		}},
+		parse, &sliceBatcher[int64]{size: 100},
+		func(batch []int64) error {
+			batches = append(batches, batch)
+			return nil
+		})
+	require.NoError(t, err)
+
+	require.Len(t, batches, 3)
+	assert.Equal(t, int64(10), batches[0][0])
+	assert.Equal(t, int64(1000), batches[0][99])
+	assert.Equal(t, int64(2000), batches[1][99])
+	assert.Equal(t, []int64{2010, 2020, 2030, 2040, 2050}, batches[2])
+	assert.Equal(t, []int64{1000, 2000, 2058}, checkpoints, "batches are checkpointed at their last line, the end at the line count")
+}
+
+func TestStreamLines_Errors(t *testing.T) {
+	input := strings.Repeat("line\n", 10*pipelineChunkLines)
+	parse := func(line int64, text string) (int64, bool) { return line, true }
+
+	t.Run("Writer error", func(t *testing.T) {
+		var writes int
+		err := streamLines(context.Background(), strings.NewReader(input), "lines", 4, Resume{}, parse,
+			&sliceBatcher[int64]{size: 10},
+			func(batch []int64) error {
+				if writes++; writes == 3 {
+					return assert.AnError
+				}
+				return nil
+			})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 3, writes, "nothing is written after an error")
+	})
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var checkpoints int
+		err := streamLines(ctx, strings.NewReader(input), "lines", 4,
+			Resume{Checkpoint: func(lines int64) error {
+				if checkpoints++; checkpoints == 2 {
+					cancel()
+				}
+				return nil
+			}},
+			parse, &sliceBatcher[int64]{size: 10},
+			func(batch []int64) error { return nil })
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, checkpoints, len(input)/5/10, "the file is not read to the end")
+	})
+}
@@ -0,0 +1,36 @@
+package seeder
+
+import "fmt"
+
+// reloadRequiredTables must not be empty in a reload
+var reloadRequiredTables = []string{"countries", "cities", "city_translations"}
+
+// reloadCheckedTables may not shrink by more than MinReloadRatio in a reload
+var reloadCheckedTables = []string{"cities", "city_translations", "city_alternate_names"}
+
+// MinReloadRatio is the share of the live rows of a checked table a reload
+// must at least hold; fewer usually mean a truncated or wrong dump
+const MinReloadRatio = 0.9
+
+// ValidateReload checks the row counts of a staged reload against the live
+// ones before it is swapped in. Unless force is set, the staged cities and
+// their names must number at least MinReloadRatio of the live ones, so that
+// a bad download does not replace good data; force allows deliberate cuts
+// such as a higher minimum population.
+func ValidateReload(staged, live map[string]int64, force bool) error {
+	for _, table := range reloadRequiredTables {
+		if staged[table] == 0 {
+			return fmt.Errorf("staged %s are empty", table)
+		}
+	}
+	if force {
+		return nil
+	}
+	for _, table := range reloadCheckedTables {
+		if float64(staged[table]) < MinReloadRatio*float64(live[table]) {
+			return fmt.Errorf("staged %s hold %d rows, fewer than %.0f%% of the %d live ones",
+				table, staged[table], MinReloadRatio*100, live[table])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package seeder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReload(t *testing.T) {
+	live := map[string]int64{"countries": 250, "cities": 1000, "city_translations": 5000, "city_alternate_names": 8000}
+	tests := []struct {
+		name    string
+		staged  map[string]int64
+		force   bool
+		wantErr string
+	}{
+		{name: "Same size", staged: live},
+		{name: "Slightly smaller", staged: map[string]int64{"countries": 250, "cities": 950, "city_translations": 4600, "city_alternate_names": 7300}},
+		{name: "Empty table", staged: map[string]int64{"countries": 250, "cities": 1000}, wantErr: "staged city_translations are empty"},
+		{name: "Empty table with force", staged: map[string]int64{"countries": 250, "cities": 1000}, force: true, wantErr: "staged city_translations are empty"},
+		{name: "Shrunk", staged: map[string]int64{"countries": 250, "cities": 500, "city_translations": 5000, "city_alternate_names": 8000},
+			wantErr: "staged cities hold 500 rows, fewer than 90% of the 1000 live ones"},
+		{name: "Shrunk with force", staged: map[string]int64{"countries": 250, "cities": 500, "city_translations": 2500, "city_alternate_names": 4000}, force: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReload(tt.staged, live, tt.force)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
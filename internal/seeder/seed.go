@@ -0,0 +1,312 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/alexivanou/geocity-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Seed phases in import order. The checkpoint offset of a streamed phase
+// counts the lines of its file.
+const (
+	PhaseCountries      = "countries"
+	PhaseContinents     = "continents"
+	PhaseAdminDivisions = "admin_divisions"
+	PhaseHierarchy      = "hierarchy"
+	PhasePostalCodes    = "postal_codes"
+	PhaseCities         = "cities"
+	// PhaseAlternateNames imports the city, country, admin division and
+	// continent translations and the city names, which share one file
+	PhaseAlternateNames = "alternate_names"
+)
+
+// Phases lists the seed phases in import order
+var Phases = []string{
+	PhaseCountries, PhaseContinents, PhaseAdminDivisions, PhaseHierarchy,
+	PhasePostalCodes, PhaseCities, PhaseAlternateNames,
+}
+
+// SeedNeeded reports whether Seed has work to do: the database is empty or
+// an earlier seed did not finish. The checkpoints of a finished seed are
+// cleared if the database is empty again, so that it is imported anew.
+func SeedNeeded(ctx context.Context, repo repository.SeedStateRepository, empty bool) (bool, error) {
+	checkpoints, err := repo.GetCheckpoints(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to read seed state: %w", err)
+	}
+
+	switch {
+	case seedComplete(checkpoints) && empty:
+		return true, repo.ClearCheckpoints(ctx)
+	case seedComplete(checkpoints):
+		return false, nil
+	case len(checkpoints) > 0:
+		return true, nil
+	default:
+		return empty, nil
+	}
+}
+
+// seedComplete reports whether every phase completed
+func seedComplete(checkpoints map[string]model.SeedCheckpoint) bool {
+	for _, phase := range Phases {
+		if !checkpoints[phase].Completed {
+			return false
+		}
+	}
+	return true
+}
+
+// Seed imports the GeoNames dumps phase by phase and records the dump date.
+// Progress is checkpointed after every batch, and an interrupted seed
+// resumes where it stopped: completed phases are skipped and a started one
+// continues after its last checkpoint. Batches are written idempotently, as
+// those delivered after the last checkpoint are written again.
+func Seed(ctx context.Context, p *Parser, repos *repository.Container, logger *zap.Logger) error {
+	checkpoints, err := repos.SeedState.GetCheckpoints(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read seed state: %w", err)
+	}
+	run := &seedRun{ctx: ctx, repo: repos.SeedState, checkpoints: checkpoints, logger: logger}
+
+	// Read before the import, so that a bad header file fails it early
+	dumpDate, err := p.DumpDate()
+	if err != nil {
+		return err
+	}
+	if dumpDate == "" {
+		// Daily updates start after the recorded dump date, so one is needed
+		dumpDate = p.FileDumpDate()
+		logger.Warn("Dump date unknown, assuming the day before the cities file was written; pass -dump-date to set it",
+			zap.String("dump_date", dumpDate))
+	}
+
+	// Later phases select by the parsed files, so they are parsed even when
+	// their own phases are done
+	logger.Info("Parsing countries...")
+	countries, err := p.ParseCountries()
+	if err != nil {
+		return fmt.Errorf("failed to parse countries: %w", err)
+	}
+
+	logger.Info("Parsing admin divisions...")
+	divisions, err := p.ParseAdminDivisions()
+	if err != nil {
+		return fmt.Errorf("failed to parse admin divisions: %w", err)
+	}
+
+	countryCodeMap := CreateCountryCodeMap(countries)
+	divisions = FilterAdminDivisions(divisions, countryCodeMap)
+
+	err = run.phase(PhaseCountries, func(Resume) error {
+		return repos.Country.BulkInsertCountries(ctx, countries)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = run.phase(PhaseContinents, func(Resume) error {
+		return repos.Country.BulkInsertContinents(ctx, Continents)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = run.phase(PhaseAdminDivisions, func(Resume) error {
+		logger.Info("Inserting admin divisions...", zap.Int("count", len(divisions)))
+		return repos.Country.BulkInsertAdminDivisions(ctx, divisions)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = run.phase(PhaseHierarchy, func(resume Resume) error {
+		var total int
+		err := p.ProcessHierarchyFrom(ctx, resume, func(batch []model.HierarchyEdge) error {
+			if err := repos.Country.BulkInsertHierarchy(ctx, batch); err != nil {
+				return fmt.Errorf("failed to insert hierarchy batch: %w", err)
+			}
+			total += len(batch)
+			return nil
+		})
+		logger.Info("Imported hierarchy", zap.Int("edges", total))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = run.phase(PhasePostalCodes, func(resume Resume) error {
+		var total int
+		err := p.ProcessPostalCodesFrom(ctx, resume, countryCodeMap, func(batch []model.PostalCode) error {
+			if err := repos.PostalCode.BulkInsertPostalCodes(ctx, batch); err != nil {
+				return fmt.Errorf("failed to insert postal codes batch: %w", err)
+			}
+			total += len(batch)
+			return nil
+		})
+		logger.Info("Imported postal codes", zap.Int("count", total))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// cityIDs selects the translations to import. A cities phase run from
+	// the start collects them; otherwise they are parsed anew.
+	var cityIDs map[int]bool
+	err = run.phase(PhaseCities, func(resume Resume) error {
+		ids := make(map[int]bool)
+		var total int
+		start := time.Now()
+		err := p.ProcessCitiesFrom(ctx, resume, func(batch []model.City) error {
+			if err := repos.City.BulkInsertCities(ctx, batch); err != nil {
+				return fmt.Errorf("failed to insert cities batch: %w", err)
+			}
+			for _, city := range batch {
+				ids[city.ID] = true
+			}
+			total += len(batch)
+			return nil
+		})
+		logger.Info("Inserted cities", zap.Int("count", total), throughput(total, time.Since(start)))
+		if err == nil && resume.SkipLines == 0 {
+			cityIDs = ids
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = run.phase(PhaseAlternateNames, func(resume Resume) error {
+		if cityIDs == nil {
+			logger.Info("Parsing city IDs...")
+			ids, err := p.ParseCityIDs()
+			if err != nil {
+				return fmt.Errorf("failed to parse cities: %w", err)
+			}
+			cityIDs = ids
+		}
+
+		var totalCityTranslations, totalCountryTranslations, totalAdminTranslations, totalCityNames int
+		start := time.Now()
+		err := p.ProcessAlternateNamesForTargets(ctx, AlternateNameTargets{
+			CityIDs:             cityIDs,
+			CountryCodes:        countryCodeMap,
+			CountryGeonameIDs:   CreateCountryGeonameIDMap(countries),
+			AdminGeonameIDs:     CreateAdminGeonameIDMap(divisions),
+			ContinentGeonameIDs: CreateContinentGeonameIDMap(Continents),
+			Resume:              resume,
+			CityCallback: func(batch []model.CityTranslation) error {
+				if err := repos.Translation.BulkInsertCityTranslations(ctx, batch); err != nil {
+					return fmt.Errorf("failed to insert city translations batch: %w", err)
+				}
+				totalCityTranslations += len(batch)
+				return nil
+			},
+			CityNameCallback: func(batch []model.CityAlternateName) error {
+				if err := repos.Translation.BulkInsertCityAlternateNames(ctx, batch); err != nil {
+					return fmt.Errorf("failed to insert city names batch: %w", err)
+				}
+				totalCityNames += len(batch)
+				return nil
+			},
+			CountryCallback: func(batch []model.CountryTranslation) error {
+				if err := repos.Translation.BulkInsertCountryTranslations(ctx, batch); err != nil {
+					return fmt.Errorf("failed to insert country translations batch: %w", err)
+				}
+				totalCountryTranslations += len(batch)
+				return nil
+			},
+			AdminCallback: func(batch []model.AdminDivisionTranslation) error {
+				if err := repos.Translation.BulkInsertAdminDivisionTranslations(ctx, batch); err != nil {
+					return fmt.Errorf("failed to insert admin translations batch: %w", err)
+				}
+				totalAdminTranslations += len(batch)
+				return nil
+			},
+			ContinentCallback: func(batch []model.ContinentTranslation) error {
+				if err := repos.Translation.BulkInsertContinentTranslations(ctx, batch); err != nil {
+					return fmt.Errorf("failed to insert continent translations batch: %w", err)
+				}
+				return nil
+			},
+		})
+		logger.Info("Processed translations",
+			zap.Int("city_translations", totalCityTranslations),
+			zap.Int("country_translations", totalCountryTranslations),
+			zap.Int("admin_translations", totalAdminTranslations),
+			zap.Int("city_names", totalCityNames),
+			throughput(totalCityTranslations+totalCountryTranslations+totalAdminTranslations+totalCityNames, time.Since(start)),
+		)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if dumpDate != "" {
+		if err := repos.Update.RecordUpdate(ctx, model.DataUpdate{Date: dumpDate, Source: model.UpdateSourceDump}); err != nil {
+			return fmt.Errorf("failed to record dump date: %w", err)
+		}
+	}
+	return nil
+}
+
+// seedRun runs the phases of a seed against its checkpoints
+type seedRun struct {
+	ctx         context.Context
+	repo        repository.SeedStateRepository
+	checkpoints map[string]model.SeedCheckpoint
+	logger      *zap.Logger
+}
+
+// phase runs load unless the phase completed earlier, passing it the
+// offset to resume from, and checkpoints its progress
+func (r *seedRun) phase(name string, load func(resume Resume) error) error {
+	checkpoint := r.checkpoints[name]
+	if checkpoint.Completed {
+		r.logger.Info("Skipping completed seed phase", zap.String("phase", name))
+		return nil
+	}
+	if checkpoint.Offset > 0 {
+		r.logger.Info("Resuming seed phase", zap.String("phase", name), zap.Int64("offset", checkpoint.Offset))
+	} else {
+		r.logger.Info("Starting seed phase", zap.String("phase", name))
+	}
+
+	save := func(offset int64, completed bool) error {
+		checkpoint = model.SeedCheckpoint{Phase: name, Offset: offset, Completed: completed}
+		return r.repo.SaveCheckpoint(r.ctx, checkpoint)
+	}
+	resume := Resume{
+		SkipLines:  checkpoint.Offset,
+		Checkpoint: func(lines int64) error { return save(lines, false) },
+	}
+	if err := save(checkpoint.Offset, false); err != nil {
+		return fmt.Errorf("failed to save %s checkpoint: %w", name, err)
+	}
+
+	start := time.Now()
+	if err := load(resume); err != nil {
+		return fmt.Errorf("failed to import %s: %w", name, err)
+	}
+	r.logger.Info("Finished seed phase", zap.String("phase", name), zap.Duration("duration", time.Since(start)))
+	if err := save(checkpoint.Offset, true); err != nil {
+		return fmt.Errorf("failed to save %s checkpoint: %w", name, err)
+	}
+	return nil
+}
+
+// throughput is a log field with the rows written per second
+func throughput(rows int, elapsed time.Duration) zap.Field {
+	if elapsed <= 0 {
+		return zap.Skip()
+	}
+	return zap.Float64("rows_per_sec", float64(rows)/elapsed.Seconds())
+}
@@ -0,0 +1,277 @@
+package seeder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/config"
+	"github.com/alexivanou/geocity-api/internal/database"
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/alexivanou/geocity-api/internal/repository"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSeedStateRepository keeps checkpoints in memory
+type fakeSeedStateRepository struct {
+	checkpoints map[string]model.SeedCheckpoint
+}
+
+func (f *fakeSeedStateRepository) GetCheckpoints(ctx context.Context) (map[string]model.SeedCheckpoint, error) {
+	return f.checkpoints, nil
+}
+
+func (f *fakeSeedStateRepository) SaveCheckpoint(ctx context.Context, checkpoint model.SeedCheckpoint) error {
+	f.checkpoints[checkpoint.Phase] = checkpoint
+	return nil
+}
+
+func (f *fakeSeedStateRepository) ClearCheckpoints(ctx context.Context) error {
+	f.checkpoints = map[string]model.SeedCheckpoint{}
+	return nil
+}
+
+func TestSeedNeeded(t *testing.T) {
+	complete := map[string]model.SeedCheckpoint{}
+	for _, phase := range Phases {
+		complete[phase] = model.SeedCheckpoint{Phase: phase, Completed: true}
+	}
+	interrupted := map[string]model.SeedCheckpoint{
+		PhaseCountries: {Phase: PhaseCountries, Completed: true},
+		PhaseHierarchy: {Phase: PhaseHierarchy, Offset: 20000},
+	}
+
+	tests := []struct {
+		name        string
+		checkpoints map[string]model.SeedCheckpoint
+		empty       bool
+		want        bool
+		wantCleared bool
+	}{
+		{name: "Fresh database", checkpoints: map[string]model.SeedCheckpoint{}, empty: true, want: true},
+		{name: "Seeded before checkpoints existed", checkpoints: map[string]model.SeedCheckpoint{}, empty: false, want: false},
+		{name: "Interrupted seed", checkpoints: interrupted, empty: false, want: true},
+		{name: "Interrupted before cities", checkpoints: interrupted, empty: true, want: true},
+		{name: "Finished seed", checkpoints: complete, empty: false, want: false},
+		{name: "Finished seed of an emptied database", checkpoints: complete, empty: true, want: true, wantCleared: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSeedStateRepository{checkpoints: tt.checkpoints}
+			needed, err := SeedNeeded(context.Background(), repo, tt.empty)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, needed)
+			assert.Equal(t, tt.wantCleared, len(repo.checkpoints) == 0 && len(tt.checkpoints) > 0)
+		})
+	}
+}
+
+// failingTranslationRepository fails the failOn-th city translation batch
+type failingTranslationRepository struct {
+	repository.TranslationRepository
+	calls  int
+	failOn int
+}
+
+func (r *failingTranslationRepository) BulkInsertCityTranslations(ctx context.Context, translations []model.CityTranslation) error {
+	r.calls++
+	if r.calls == r.failOn {
+		return assert.AnError
+	}
+	return r.TranslationRepository.BulkInsertCityTranslations(ctx, translations)
+}
+
+// failingCityRepository records the cities inserted and fails the
+// failOn-th batch
+type failingCityRepository struct {
+	repository.CityRepository
+	calls    int
+	failOn   int
+	inserted []int
+}
+
+func (r *failingCityRepository) BulkInsertCities(ctx context.Context, cities []model.City) error {
+	r.calls++
+	if r.calls == r.failOn {
+		return assert.AnError
+	}
+	for _, city := range cities {
+		r.inserted = append(r.inserted, city.ID)
+	}
+	return r.CityRepository.BulkInsertCities(ctx, cities)
+}
+
+// migratedTestDB returns a migrated in-memory SQLite database
+func migratedTestDB(t *testing.T, name string) *sqlx.DB {
+	t.Helper()
+	db, err := database.Connect(context.Background(), config.DBConfig{Type: config.DBTypeMemory, Name: name})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	driver, err := sqlite3.WithInstance(db.DB, &sqlite3.Config{})
+	require.NoError(t, err)
+	m, err := migrate.NewWithDatabaseInstance("file://../../migrations/sqlite", "sqlite3", driver)
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+	return db
+}
+
+func TestSeed_Resume(t *testing.T) {
+	ctx := context.Background()
+	db := migratedTestDB(t, "seed_test")
+
+	dir := t.TempDir()
+	writeUpdateFiles(t, dir, map[string]string{
+		"countryInfo.txt": "DE\tDEU\t276\tGM\tGermany\tBerlin\t357021\t82927922\tEU\t.de\tEUR\tEuro\t49\t#####\t^(\\d{5})$\tde\t2921044\tCH,PL\t\n",
+		"cities1000.txt":  geonameLine("2950159", "Berlin", "P", "3700000") + geonameLine("2852458", "Potsdam", "P", "180000"),
+		"alternateNames.txt": "1\t2950159\tfr\tBerlin\n" +
+			"2\t2950159\tru\tБерлин\n" +
+			"3\t2852458\tru\tПотсдам\n" +
+			"4\t2921044\tfr\tAllemagne\n",
+	})
+	parser := NewParser(dir, config.SeederConfig{BatchSize: 1})
+
+	repos := repository.NewRepositories(db, config.DBTypeMemory)
+	translations := &failingTranslationRepository{TranslationRepository: repos.Translation, failOn: 3}
+	repos.Translation = translations
+
+	// The third batch of translations fails, after two lines were checkpointed
+	require.Error(t, Seed(ctx, parser, repos, zap.NewNop()))
+	checkpoints, err := repos.SeedState.GetCheckpoints(ctx)
+	require.NoError(t, err)
+	assert.True(t, checkpoints[PhaseCities].Completed)
+	assert.Equal(t, model.SeedCheckpoint{Phase: PhaseAlternateNames, Offset: 2}, checkpoints[PhaseAlternateNames])
+
+	needed, err := SeedNeeded(ctx, repos.SeedState, false)
+	require.NoError(t, err)
+	assert.True(t, needed, "a partial seed is not served")
+
+	// The next run only imports the lines after the checkpoint
+	translations.calls, translations.failOn = 0, 0
+	require.NoError(t, Seed(ctx, parser, repos, zap.NewNop()))
+	assert.Equal(t, 1, translations.calls)
+
+	checkpoints, err = repos.SeedState.GetCheckpoints(ctx)
+	require.NoError(t, err)
+	for _, phase := range Phases {
+		assert.True(t, checkpoints[phase].Completed, phase)
+	}
+	assert.Equal(t, int64(4), checkpoints[PhaseAlternateNames].Offset)
+
+	for id, want := range map[int]string{2950159: "Берлин", 2852458: "Потсдам"} {
+		name, err := repos.City.GetCityName(ctx, id, "ru")
+		require.NoError(t, err)
+		assert.Equal(t, want, name)
+	}
+	country, err := repos.Country.GetCountryName(ctx, "DE", "fr")
+	require.NoError(t, err)
+	assert.Equal(t, "Allemagne", country)
+
+	needed, err = SeedNeeded(ctx, repos.SeedState, false)
+	require.NoError(t, err)
+	assert.False(t, needed)
+
+	// Without a known dump date the cities file's time stands in for it
+	last, err := repos.Update.LastUpdate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, parser.FileDumpDate(), last)
+	assert.NotEmpty(t, last)
+}
+
+func TestSeed_ResumeMatchesUninterruptedSeed(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	writeUpdateFiles(t, dir, map[string]string{
+		"countryInfo.txt": "DE\tDEU\t276\tGM\tGermany\tBerlin\t357021\t82927922\tEU\t.de\tEUR\tEuro\t49\t#####\t^(\\d{5})$\tde\t2921044\tCH,PL\t\n",
+		"cities1000.txt":  geonameLine("2950159", "Berlin", "P", "3700000") + geonameLine("2852458", "Potsdam", "P", "180000"),
+		// The preferred names come before the checkpoint, other names of
+		// the same places after it
+		"alternateNames.txt": "1\t2950159\tde\tBerlin\t1\n" +
+			"2\t2921044\tfr\tAllemagne\t1\n" +
+			"3\t2852458\tru\tПотсдам\n" +
+			"4\t2950159\tde\tBärlin\n" +
+			"5\t2921044\tfr\tRépublique fédérale d'Allemagne\n",
+	})
+	parser := NewParser(dir, config.SeederConfig{BatchSize: 1})
+
+	cleanDB := migratedTestDB(t, "seed_clean_test")
+	require.NoError(t, Seed(ctx, parser, repository.NewRepositories(cleanDB, config.DBTypeMemory), zap.NewNop()))
+
+	resumedDB := migratedTestDB(t, "seed_resumed_test")
+	repos := repository.NewRepositories(resumedDB, config.DBTypeMemory)
+	translations := &failingTranslationRepository{TranslationRepository: repos.Translation, failOn: 2}
+	repos.Translation = translations
+
+	// Potsdam's translation fails after the preferred names were checkpointed
+	require.Error(t, Seed(ctx, parser, repos, zap.NewNop()))
+	checkpoints, err := repos.SeedState.GetCheckpoints(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, model.SeedCheckpoint{Phase: PhaseAlternateNames, Offset: 2}, checkpoints[PhaseAlternateNames])
+
+	translations.calls, translations.failOn = 0, 0
+	require.NoError(t, Seed(ctx, parser, repos, zap.NewNop()))
+
+	assert.Equal(t, translationRows(t, cleanDB), translationRows(t, resumedDB))
+	name, err := repos.City.GetCityName(ctx, 2950159, "de")
+	require.NoError(t, err)
+	assert.Equal(t, "Berlin", name, "the preferred name survives the resume")
+	country, err := repos.Country.GetCountryName(ctx, "DE", "fr")
+	require.NoError(t, err)
+	assert.Equal(t, "Allemagne", country)
+}
+
+// translationRows lists the city and country translations of db
+func translationRows(t *testing.T, db *sqlx.DB) []string {
+	t.Helper()
+	var rows []string
+	require.NoError(t, db.Select(&rows, `
+		SELECT CAST(city_id AS TEXT) || ' ' || lang || ' ' || name FROM city_translations
+		UNION ALL
+		SELECT country_code || ' ' || lang || ' ' || name FROM country_translations
+		ORDER BY 1`))
+	return rows
+}
+
+func TestSeed_ResumeCitiesWithOtherMinPopulation(t *testing.T) {
+	ctx := context.Background()
+	db := migratedTestDB(t, "seed_cities_test")
+
+	dir := t.TempDir()
+	writeUpdateFiles(t, dir, map[string]string{
+		"countryInfo.txt": "DE\tDEU\t276\tGM\tGermany\tBerlin\t357021\t82927922\tEU\t.de\tEUR\tEuro\t49\t#####\t^(\\d{5})$\tde\t2921044\tCH,PL\t\n",
+		"cities1000.txt": geonameLine("1", "Hamlet", "P", "500") +
+			geonameLine("2950159", "Berlin", "P", "3700000") +
+			geonameLine("2852458", "Potsdam", "P", "180000") +
+			geonameLine("2879139", "Leipzig", "P", "600000"),
+		"alternateNames.txt": "",
+	})
+
+	repos := repository.NewRepositories(db, config.DBTypeMemory)
+	cities := &failingCityRepository{CityRepository: repos.City, failOn: 2}
+	repos.City = cities
+
+	// Potsdam fails after Berlin, on line 2, was checkpointed
+	parser := NewParser(dir, config.SeederConfig{BatchSize: 1, MinPopulation: 1000})
+	require.Error(t, Seed(ctx, parser, repos, zap.NewNop()))
+	checkpoints, err := repos.SeedState.GetCheckpoints(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, model.SeedCheckpoint{Phase: PhaseCities, Offset: 2}, checkpoints[PhaseCities])
+
+	// The offset is a line of the file, so a resume that keeps more cities
+	// continues after Berlin rather than after the first city it parses
+	cities.calls, cities.failOn, cities.inserted = 0, 0, nil
+	parser = NewParser(dir, config.SeederConfig{BatchSize: 1})
+	require.NoError(t, Seed(ctx, parser, repos, zap.NewNop()))
+	assert.Equal(t, []int{2852458, 2879139}, cities.inserted)
+
+	checkpoints, err = repos.SeedState.GetCheckpoints(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, model.SeedCheckpoint{Phase: PhaseCities, Offset: 4, Completed: true}, checkpoints[PhaseCities])
+}
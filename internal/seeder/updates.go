@@ -0,0 +1,241 @@
+package seeder
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/alexivanou/geocity-api/internal/repository"
+)
+
+// updateDateLayout is the date format of the daily update file names
+const updateDateLayout = "2006-01-02"
+
+// dailyUpdateFile matches the GeoNames daily update files, e.g.
+// "modifications-2024-05-01.txt" and "alternateNamesDeletes-2024-05-01.txt"
+var dailyUpdateFile = regexp.MustCompile(
+	`^(modifications|deletes|alternateNamesModifications|alternateNamesDeletes)-(\d{4}-\d{2}-\d{2})\.txt$`)
+
+// PendingUpdateDates returns the dates (YYYY-MM-DD) after the given one for
+// which the data directory holds daily update files, oldest first. An empty
+// after returns all of them; a missing directory none.
+func (p *Parser) PendingUpdateDates(after string) ([]string, error) {
+	entries, err := os.ReadDir(p.dataDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data directory: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var dates []string
+	for _, entry := range entries {
+		match := dailyUpdateFile.FindStringSubmatch(entry.Name())
+		if match == nil || seen[match[2]] || match[2] <= after {
+			continue
+		}
+		if _, err := time.Parse(updateDateLayout, match[2]); err != nil {
+			continue
+		}
+		seen[match[2]] = true
+		dates = append(dates, match[2])
+	}
+	sort.Strings(dates)
+	return dates, nil
+}
+
+// ParseDailyUpdate reads the daily update files of a date. Each of the four
+// files is optional. Modified places that are not cities of at least the
+// configured population are returned as deleted, so that places which stop
+// qualifying leave the database.
+func (p *Parser) ParseDailyUpdate(date string) (*model.DailyUpdate, error) {
+	update := &model.DailyUpdate{Date: date}
+
+	err := p.readUpdateFile("modifications-"+date+".txt", func(line string) {
+		city, ok := parseGeoname(line)
+		if !ok {
+			return
+		}
+		if city.FeatureClass == "P" && city.Population >= p.minPopulation {
+			update.Cities = append(update.Cities, city)
+		} else {
+			update.DeletedCityIDs = append(update.DeletedCityIDs, city.ID)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// deletes: geonameid, name, comment
+	err = p.readUpdateFile("deletes-"+date+".txt", func(line string) {
+		id, err := strconv.Atoi(strings.SplitN(line, "\t", 2)[0])
+		if err == nil {
+			update.DeletedCityIDs = append(update.DeletedCityIDs, id)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.readUpdateFile("alternateNamesModifications-"+date+".txt", func(line string) {
+		if name, ok := p.parseAlternateName(line); ok {
+			update.Names = append(update.Names, name)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// alternateNamesDeletes: alternateNameId, geonameid, comment
+	err = p.readUpdateFile("alternateNamesDeletes-"+date+".txt", func(line string) {
+		id, err := strconv.ParseInt(strings.SplitN(line, "\t", 2)[0], 10, 64)
+		if err == nil {
+			update.DeletedNameIDs = append(update.DeletedNameIDs, id)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return update, nil
+}
+
+// readUpdateFile calls fn for each line of a daily update file, if it exists
+func (p *Parser) readUpdateFile(name string, fn func(line string)) error {
+	file, err := os.Open(filepath.Join(p.dataDir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to scan %s: %w", name, err)
+	}
+	return nil
+}
+
+// dumpHeadersFile holds the HTTP response headers of the cities download,
+// saved by `make download-data`
+const dumpHeadersFile = "cities1000.headers"
+
+// DumpDate returns the date (YYYY-MM-DD) the full dump is current as of:
+// the configured DumpDate if set, else the day before the Last-Modified date
+// of the cities download, since a dump holds the changes up to the previous
+// day. It returns "" if neither is known.
+func (p *Parser) DumpDate() (string, error) {
+	if p.dumpDate != "" {
+		return p.dumpDate, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dataDir, dumpHeadersFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dumpHeadersFile, err)
+	}
+
+	// curl -L saves the headers of every response; the last one is the file's
+	var lastModified string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "HTTP/") {
+			lastModified = ""
+		} else if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Last-Modified") {
+			lastModified = strings.TrimSpace(value)
+		}
+	}
+	if lastModified == "" {
+		return "", nil
+	}
+
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return "", fmt.Errorf("invalid Last-Modified in %s: %w", dumpHeadersFile, err)
+	}
+	return modified.UTC().AddDate(0, 0, -1).Format(updateDateLayout), nil
+}
+
+// FileDumpDate guesses the date the full dump is current as of from the
+// cities file when DumpDate is unknown: the day before the file was written,
+// which is the day it was downloaded unless its time was kept. It returns ""
+// if the cities file is missing.
+func (p *Parser) FileDumpDate() string {
+	for _, name := range []string{"cities1000.zip", "cities1000.txt"} {
+		if info, err := os.Stat(filepath.Join(p.dataDir, name)); err == nil {
+			return info.ModTime().UTC().AddDate(0, 0, -1).Format(updateDateLayout)
+		}
+	}
+	return ""
+}
+
+// ErrUnknownDumpDate is returned by ApplyPendingUpdates when neither a dump
+// nor an update was recorded, so it cannot tell which update files are
+// older than the data
+var ErrUnknownDumpDate = errors.New("no dump date recorded; run the seeder with -updates -dump-date=YYYY-MM-DD")
+
+// ApplyPendingUpdates applies the daily update files newer than the last
+// recorded update, oldest first, and returns the applied updates. Each day
+// is applied and recorded in one transaction, so the run can be repeated
+// after a failure. If nothing was recorded it records DumpDate as the dump
+// first, or returns ErrUnknownDumpDate rather than apply files that may be
+// older than the data.
+func ApplyPendingUpdates(ctx context.Context, p *Parser, repo repository.UpdateRepository) ([]model.DataUpdate, error) {
+	last, err := repo.LastUpdate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last update: %w", err)
+	}
+
+	dates, err := p.PendingUpdateDates(last)
+	if err != nil {
+		return nil, err
+	}
+	if last == "" && len(dates) > 0 {
+		dumpDate, err := p.DumpDate()
+		if err != nil {
+			return nil, err
+		}
+		if dumpDate == "" {
+			return nil, ErrUnknownDumpDate
+		}
+		if err := repo.RecordUpdate(ctx, model.DataUpdate{Date: dumpDate, Source: model.UpdateSourceDump}); err != nil {
+			return nil, fmt.Errorf("failed to record dump date: %w", err)
+		}
+		if dates, err = p.PendingUpdateDates(dumpDate); err != nil {
+			return nil, err
+		}
+	}
+
+	var applied []model.DataUpdate
+	for _, date := range dates {
+		update, err := p.ParseDailyUpdate(date)
+		if err != nil {
+			return applied, fmt.Errorf("failed to parse update %s: %w", date, err)
+		}
+		record, err := repo.ApplyDailyUpdate(ctx, *update)
+		if err != nil {
+			return applied, fmt.Errorf("failed to apply update %s: %w", date, err)
+		}
+		applied = append(applied, *record)
+	}
+	return applied, nil
+}
@@ -0,0 +1,201 @@
+package seeder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexivanou/geocity-api/internal/config"
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// geonameLine renders a line of a modifications file
+func geonameLine(id, name, featureClass, population string) string {
+	return id + "\t" + name + "\t" + name + "\t\t52.52\t13.405\t" + featureClass + "\tPPL\tDE\t\t16\t00\t\t\t" +
+		population + "\t34\t43\tEurope/Berlin\t2024-05-01\n"
+}
+
+func writeUpdateFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestParser_PendingUpdateDates(t *testing.T) {
+	dir := t.TempDir()
+	writeUpdateFiles(t, dir, map[string]string{
+		"modifications-2024-05-02.txt":               "",
+		"alternateNamesDeletes-2024-05-01.txt":       "",
+		"deletes-2024-04-30.txt":                     "",
+		"alternateNamesModifications-2024-05-02.txt": "",
+		"modifications-2024-13-01.txt":               "",
+		"cities1000.txt":                             "",
+	})
+	parser := NewParser(dir, config.SeederConfig{})
+
+	dates, err := parser.PendingUpdateDates("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-04-30", "2024-05-01", "2024-05-02"}, dates)
+
+	dates, err = parser.PendingUpdateDates("2024-05-01")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-05-02"}, dates)
+
+	dates, err = NewParser(filepath.Join(dir, "missing"), config.SeederConfig{}).PendingUpdateDates("")
+	require.NoError(t, err)
+	assert.Empty(t, dates)
+}
+
+func TestParser_ParseDailyUpdate(t *testing.T) {
+	dir := t.TempDir()
+	writeUpdateFiles(t, dir, map[string]string{
+		"modifications-2024-05-01.txt": geonameLine("2950159", "Berlin", "P", "3700000") +
+			geonameLine("2", "Village", "P", "500") +
+			geonameLine("3", "Brandenburger Tor", "S", "0") +
+			"broken\n",
+		"deletes-2024-05-01.txt": "4\tGone\tduplicate of 5\n",
+		"alternateNamesModifications-2024-05-01.txt": "100\t2950159\tde\tBärlin\t0\t0\t1\t0\t\t\n" +
+			"101\t2950159\tlink\thttps://example.org\t\t\t\t\t\t\n",
+		"alternateNamesDeletes-2024-05-01.txt": "99\t2950159\twrong language\n",
+	})
+	parser := NewParser(dir, config.SeederConfig{MinPopulation: 1000})
+
+	update, err := parser.ParseDailyUpdate("2024-05-01")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01", update.Date)
+	require.Len(t, update.Cities, 1)
+	assert.Equal(t, 2950159, update.Cities[0].ID)
+	assert.Equal(t, 3700000, update.Cities[0].Population)
+	assert.Equal(t, []int{2, 3, 4}, update.DeletedCityIDs, "places that no longer qualify are deleted")
+	assert.Equal(t, []model.CityAlternateName{
+		{ID: 100, CityID: 2950159, Lang: "de", Name: "Bärlin", IsColloquial: true},
+	}, update.Names)
+	assert.Equal(t, []int64{99}, update.DeletedNameIDs)
+
+	update, err = parser.ParseDailyUpdate("2024-05-02")
+	require.NoError(t, err)
+	assert.Empty(t, update.Cities, "missing files are skipped")
+}
+
+func TestParser_DumpDate(t *testing.T) {
+	dir := t.TempDir()
+	parser := NewParser(dir, config.SeederConfig{})
+	date, err := parser.DumpDate()
+	require.NoError(t, err)
+	assert.Empty(t, date)
+
+	assert.Empty(t, parser.FileDumpDate())
+
+	// The file's mtime is the download time, not the dump's, so it is only
+	// a fallback
+	path := filepath.Join(dir, "cities1000.zip")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	written := time.Date(2024, 5, 3, 6, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, written, written))
+	date, err = parser.DumpDate()
+	require.NoError(t, err)
+	assert.Empty(t, date)
+	assert.Equal(t, "2024-05-02", parser.FileDumpDate())
+
+	// curl -L -D saves the redirect's headers too; the last response counts
+	headers := "HTTP/1.1 301 Moved Permanently\r\nLast-Modified: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		"HTTP/1.1 200 OK\r\nlast-modified: Thu, 02 May 2024 06:00:00 GMT\r\nContent-Length: 0\r\n\r\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cities1000.headers"), []byte(headers), 0644))
+	date, err = parser.DumpDate()
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01", date)
+
+	date, err = NewParser(dir, config.SeederConfig{DumpDate: "2024-04-20"}).DumpDate()
+	require.NoError(t, err)
+	assert.Equal(t, "2024-04-20", date, "an explicit date wins")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cities1000.headers"), []byte("Last-Modified: yesterday\r\n"), 0644))
+	_, err = parser.DumpDate()
+	assert.Error(t, err)
+}
+
+// fakeUpdateRepository records applied updates in memory
+type fakeUpdateRepository struct {
+	last    string
+	applied []model.DailyUpdate
+	failOn  string
+}
+
+func (f *fakeUpdateRepository) LastUpdate(ctx context.Context) (string, error) {
+	return f.last, nil
+}
+
+func (f *fakeUpdateRepository) RecordUpdate(ctx context.Context, update model.DataUpdate) error {
+	f.last = update.Date
+	return nil
+}
+
+func (f *fakeUpdateRepository) ApplyDailyUpdate(ctx context.Context, update model.DailyUpdate) (*model.DataUpdate, error) {
+	if update.Date == f.failOn {
+		return nil, assert.AnError
+	}
+	f.applied = append(f.applied, update)
+	f.last = update.Date
+	return &model.DataUpdate{Date: update.Date, Source: model.UpdateSourceDaily, CitiesDeleted: len(update.DeletedCityIDs)}, nil
+}
+
+func (f *fakeUpdateRepository) DataVersion(ctx context.Context) (string, error) {
+	return f.last, nil
+}
+
+func TestApplyPendingUpdates(t *testing.T) {
+	dir := t.TempDir()
+	writeUpdateFiles(t, dir, map[string]string{
+		"deletes-2024-04-30.txt": "1\tOld\t\n",
+		"deletes-2024-05-01.txt": "2\tGone\t\n",
+		"deletes-2024-05-02.txt": "3\tGone\t\n",
+	})
+	parser := NewParser(dir, config.SeederConfig{})
+	repo := &fakeUpdateRepository{last: "2024-04-30", failOn: "2024-05-02"}
+	ctx := context.Background()
+
+	applied, err := ApplyPendingUpdates(ctx, parser, repo)
+	require.ErrorIs(t, err, assert.AnError)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "2024-05-01", applied[0].Date)
+	assert.Equal(t, []int{2}, repo.applied[0].DeletedCityIDs)
+
+	// A repeated run resumes after the last applied day
+	repo.failOn = ""
+	applied, err = ApplyPendingUpdates(ctx, parser, repo)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "2024-05-02", applied[0].Date)
+
+	applied, err = ApplyPendingUpdates(ctx, parser, repo)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestApplyPendingUpdates_UnknownDumpDate(t *testing.T) {
+	dir := t.TempDir()
+	writeUpdateFiles(t, dir, map[string]string{
+		"deletes-2024-04-30.txt": "1\tOld\t\n",
+		"deletes-2024-05-02.txt": "3\tGone\t\n",
+	})
+	repo := &fakeUpdateRepository{}
+	ctx := context.Background()
+
+	// Without a recorded dump, older files could undo newer dump data
+	applied, err := ApplyPendingUpdates(ctx, NewParser(dir, config.SeederConfig{}), repo)
+	require.ErrorIs(t, err, ErrUnknownDumpDate)
+	assert.Empty(t, applied)
+	assert.Empty(t, repo.applied)
+	assert.Empty(t, repo.last)
+
+	// A given dump date is recorded and only later files are applied
+	applied, err = ApplyPendingUpdates(ctx, NewParser(dir, config.SeederConfig{DumpDate: "2024-05-01"}), repo)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "2024-05-02", applied[0].Date)
+}
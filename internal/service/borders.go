@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+)
+
+// borderGraph is the undirected graph of land borders between countries,
+// built from the neighbours column of countryInfo.txt
+type borderGraph struct {
+	// adjacency lists are sorted so that searches are deterministic
+	adjacent map[string][]string
+}
+
+// newBorderGraph builds the graph over countries. A border listed by only
+// one of the two countries still connects both, and neighbours that were not
+// imported are ignored.
+func newBorderGraph(countries []model.CountryWithName) *borderGraph {
+	known := make(map[string]bool, len(countries))
+	for _, c := range countries {
+		known[c.Code] = true
+	}
+
+	edges := make(map[string]map[string]bool, len(countries))
+	link := func(a, b string) {
+		if edges[a] == nil {
+			edges[a] = make(map[string]bool)
+		}
+		edges[a][b] = true
+	}
+	for _, c := range countries {
+		for _, n := range splitList(c.Neighbours) {
+			if known[n] && n != c.Code {
+				link(c.Code, n)
+				link(n, c.Code)
+			}
+		}
+	}
+
+	g := &borderGraph{adjacent: make(map[string][]string, len(countries))}
+	for _, c := range countries {
+		neighbours := make([]string, 0, len(edges[c.Code]))
+		for n := range edges[c.Code] {
+			neighbours = append(neighbours, n)
+		}
+		sort.Strings(neighbours)
+		g.adjacent[c.Code] = neighbours
+	}
+	return g
+}
+
+// has reports whether code is a country of the graph
+func (g *borderGraph) has(code string) bool {
+	_, ok := g.adjacent[code]
+	return ok
+}
+
+// Neighbours returns the countries sharing a land border with code
+func (g *borderGraph) Neighbours(code string) []string {
+	return g.adjacent[code]
+}
+
+// ShortestPath returns the countries crossed on a route from one country to
+// another, both included, with as few borders as possible. It returns nil
+// when no land route exists.
+func (g *borderGraph) ShortestPath(from, to string) []string {
+	if !g.has(from) || !g.has(to) {
+		return nil
+	}
+
+	// Breadth-first search; prev doubles as the visited set
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 && !isVisited(prev, to) {
+		current := queue[0]
+		queue = queue[1:]
+		for _, n := range g.adjacent[current] {
+			if !isVisited(prev, n) {
+				prev[n] = current
+				queue = append(queue, n)
+			}
+		}
+	}
+	if !isVisited(prev, to) {
+		return nil
+	}
+
+	var path []string
+	for code := to; code != ""; code = prev[code] {
+		path = append(path, code)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func isVisited(prev map[string]string, code string) bool {
+	_, ok := prev[code]
+	return ok
+}
+
+// borderGraph returns the in-memory border graph, building it on first use
+// and again whenever the data version changed. Reloads and daily updates
+// run in the seeder process, so the version is read on every call.
+func (s *Service) borderGraph(ctx context.Context) (*borderGraph, error) {
+	version, err := s.updateRepo.DataVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data version: %w", err)
+	}
+
+	s.bordersMu.Lock()
+	defer s.bordersMu.Unlock()
+
+	if s.borders != nil && s.bordersVersion == version {
+		return s.borders, nil
+	}
+
+	countries, err := s.countryRepo.ListCountries(ctx, defaultLang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load countries: %w", err)
+	}
+	s.borders, s.bordersVersion = newBorderGraph(countries), version
+	return s.borders, nil
+}
+
+// GetCountryNeighbours returns the countries bordering code, with names in
+// lang, or nil if the country is unknown
+func (s *Service) GetCountryNeighbours(ctx context.Context, code string, lang string) (*model.CountriesResponse, error) {
+	code, err := normalizeCountryCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if lang == "" {
+		lang = defaultLang
+	}
+
+	graph, err := s.borderGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !graph.has(code) {
+		return nil, nil
+	}
+
+	results, err := s.localizedCountries(ctx, graph.Neighbours(code), lang)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CountriesResponse{Results: results}, nil
+}
+
+// FindBorderPath finds the route from one country to another that crosses
+// the fewest land borders. It returns nil if either country is unknown.
+func (s *Service) FindBorderPath(ctx context.Context, from, to string, lang string) (*model.BorderPathResponse, error) {
+	from, err := normalizeCountryCode(from)
+	if err != nil {
+		return nil, err
+	}
+	to, err = normalizeCountryCode(to)
+	if err != nil {
+		return nil, err
+	}
+	if lang == "" {
+		lang = defaultLang
+	}
+
+	graph, err := s.borderGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !graph.has(from) || !graph.has(to) {
+		return nil, nil
+	}
+
+	response := &model.BorderPathResponse{From: from, To: to, Path: []model.CountryResponse{}}
+	path := graph.ShortestPath(from, to)
+	if path == nil {
+		return response, nil
+	}
+
+	response.Path, err = s.localizedCountries(ctx, path, lang)
+	if err != nil {
+		return nil, err
+	}
+	response.Reachable = true
+	response.Borders = len(path) - 1
+	return response, nil
+}
+
+// localizedCountries returns the given countries, in the given order, with
+// names in lang
+func (s *Service) localizedCountries(ctx context.Context, codes []string, lang string) ([]model.CountryResponse, error) {
+	results := make([]model.CountryResponse, 0, len(codes))
+	if len(codes) == 0 {
+		return results, nil
+	}
+
+	countries, err := s.countryRepo.ListCountries(ctx, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list countries: %w", err)
+	}
+	byCode := make(map[string]*model.CountryWithName, len(countries))
+	for i := range countries {
+		byCode[countries[i].Code] = &countries[i]
+	}
+
+	for _, code := range codes {
+		if c, ok := byCode[code]; ok {
+			results = append(results, countryResponse(c))
+		}
+	}
+	return results, nil
+}
+
+// normalizeCountryCode validates an ISO 3166-1 alpha-2 code and upper-cases it
+func normalizeCountryCode(code string) (string, error) {
+	if len(code) != 2 {
+		return "", fmt.Errorf("%w: invalid country code %q", ErrInvalidArgument, code)
+	}
+	return strings.ToUpper(code), nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// borderFixture is a corner of Europe plus an island. ES lists no
+// neighbours, so its borders only come from FR and PT.
+var borderFixture = []model.CountryWithName{
+	{Country: model.Country{Code: "AT", Neighbours: "CH,DE,IT"}, Name: "Austria"},
+	{Country: model.Country{Code: "CH", Neighbours: "AT,DE,FR,IT,LI"}, Name: "Switzerland"},
+	{Country: model.Country{Code: "DE", Neighbours: "AT,CH,FR,PL"}, Name: "Germany"},
+	{Country: model.Country{Code: "ES"}, Name: "Spain"},
+	{Country: model.Country{Code: "FR", Neighbours: "CH,DE,ES,IT"}, Name: "France"},
+	{Country: model.Country{Code: "IS"}, Name: "Iceland"},
+	{Country: model.Country{Code: "IT", Neighbours: "AT,CH,FR"}, Name: "Italy"},
+	{Country: model.Country{Code: "PT", Neighbours: "ES"}, Name: "Portugal"},
+}
+
+func TestBorderGraph(t *testing.T) {
+	g := newBorderGraph(borderFixture)
+
+	assert.Equal(t, []string{"FR", "PT"}, g.Neighbours("ES"), "borders are symmetric")
+	assert.Equal(t, []string{"AT", "CH", "FR"}, g.Neighbours("DE"), "unknown neighbours are dropped")
+	assert.Empty(t, g.Neighbours("IS"))
+
+	assert.Equal(t, []string{"AT", "CH", "FR", "ES", "PT"}, g.ShortestPath("AT", "PT"))
+	assert.Equal(t, []string{"PT", "ES", "FR", "DE"}, g.ShortestPath("PT", "DE"))
+	assert.Equal(t, []string{"IT"}, g.ShortestPath("IT", "IT"))
+	assert.Nil(t, g.ShortestPath("DE", "IS"))
+	assert.Nil(t, g.ShortestPath("DE", "ZZ"))
+}
+
+func TestService_FindBorderPath(t *testing.T) {
+	mockCountryRepo := new(MockCountryRepository)
+	mockCountryRepo.On("ListCountries", mock.Anything, "en").Return(borderFixture, nil).Once()
+	mockCountryRepo.On("ListCountries", mock.Anything, "de").Return([]model.CountryWithName{
+		{Country: model.Country{Code: "DE"}, Name: "Deutschland"},
+		{Country: model.Country{Code: "ES"}, Name: "Spanien"},
+		{Country: model.Country{Code: "FR"}, Name: "Frankreich"},
+	}, nil)
+
+	mockUpdateRepo := new(MockUpdateRepository)
+	mockUpdateRepo.On("DataVersion", mock.Anything).Return("1/2024-05-01/2024-05-01", nil)
+
+	svc := NewService(new(MockCityRepository), mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), mockUpdateRepo)
+	ctx := context.Background()
+
+	resp, err := svc.FindBorderPath(ctx, "de", "ES", "de")
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.Reachable)
+	assert.Equal(t, 2, resp.Borders)
+	require.Len(t, resp.Path, 3)
+	assert.Equal(t, "Frankreich", resp.Path[1].Name)
+
+	// The graph is built once and reused
+	resp, err = svc.FindBorderPath(ctx, "DE", "IS", "de")
+	require.NoError(t, err)
+	assert.False(t, resp.Reachable)
+	assert.Empty(t, resp.Path)
+
+	resp, err = svc.FindBorderPath(ctx, "DE", "ZZ", "de")
+	require.NoError(t, err)
+	assert.Nil(t, resp)
+
+	_, err = svc.FindBorderPath(ctx, "DEU", "ES", "de")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	mockCountryRepo.AssertExpectations(t)
+}
+
+func TestService_GetCountryNeighbours(t *testing.T) {
+	mockCountryRepo := new(MockCountryRepository)
+	mockCountryRepo.On("ListCountries", mock.Anything, "en").Return(borderFixture, nil)
+	mockUpdateRepo := new(MockUpdateRepository)
+	mockUpdateRepo.On("DataVersion", mock.Anything).Return("", nil)
+
+	svc := NewService(new(MockCityRepository), mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), mockUpdateRepo)
+
+	resp, err := svc.GetCountryNeighbours(context.Background(), "pt", "")
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "Spain", resp.Results[0].Name)
+
+	resp, err = svc.GetCountryNeighbours(context.Background(), "ZZ", "")
+	require.NoError(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestService_BorderGraphFollowsDataVersion(t *testing.T) {
+	mockCountryRepo := new(MockCountryRepository)
+	mockCountryRepo.On("ListCountries", mock.Anything, "en").Return(borderFixture, nil).Once()
+	mockCountryRepo.On("ListCountries", mock.Anything, "de").Return([]model.CountryWithName{
+		{Country: model.Country{Code: "GL"}, Name: "Grönland"},
+	}, nil)
+	mockUpdateRepo := new(MockUpdateRepository)
+	mockUpdateRepo.On("DataVersion", mock.Anything).Return("1/2024-05-01/2024-05-01", nil).Twice()
+
+	svc := NewService(new(MockCityRepository), mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), mockUpdateRepo)
+	ctx := context.Background()
+
+	for range 2 {
+		resp, err := svc.GetCountryNeighbours(ctx, "IS", "de")
+		require.NoError(t, err)
+		assert.Empty(t, resp.Results)
+	}
+
+	// Another process reloaded the data and gave Iceland a neighbour
+	reloaded := append([]model.CountryWithName{
+		{Country: model.Country{Code: "GL", Neighbours: "IS"}, Name: "Greenland"},
+	}, borderFixture...)
+	mockCountryRepo.On("ListCountries", mock.Anything, "en").Return(reloaded, nil).Once()
+	mockUpdateRepo.On("DataVersion", mock.Anything).Return("1/2024-05-02/2024-05-02", nil)
+
+	resp, err := svc.GetCountryNeighbours(ctx, "IS", "de")
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "Grönland", resp.Results[0].Name)
+
+	mockUpdateRepo.On("DataVersion", mock.Anything).Unset()
+	mockUpdateRepo.On("DataVersion", mock.Anything).Return("", assert.AnError)
+	_, err = svc.GetCountryNeighbours(ctx, "IS", "de")
+	assert.ErrorIs(t, err, assert.AnError)
+
+	mockCountryRepo.AssertExpectations(t)
+}
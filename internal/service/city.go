@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/alexivanou/geocity-api/internal/model"
 )
@@ -11,6 +13,17 @@ const (
 	defaultLang    = "en"
 	defaultLimit   = 10
 	minQueryLength = 2
+
+	// defaultFuzzyThreshold matches pg_trgm's default similarity threshold
+	defaultFuzzyThreshold = 0.3
+
+	maxNearestCount = 50
+
+	maxWithinRadiusKm = 1000
+	maxPageSize       = 100
+
+	defaultBBoxLimit = 50
+	maxBBoxLimit     = 500
 )
 
 // SuggestCities searches for cities and returns localized results
@@ -30,14 +43,76 @@ func (s *Service) SuggestCities(ctx context.Context, req model.SuggestRequest) (
 	if limit <= 0 {
 		limit = defaultLimit
 	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	// Fetch one extra row to learn whether another page exists
+	req.Lang, req.Limit = lang, limit+1
+
+	if req.Fuzzy {
+		if req.FuzzyThreshold == 0 {
+			req.FuzzyThreshold = defaultFuzzyThreshold
+		}
+		if req.FuzzyThreshold < 0 || req.FuzzyThreshold > 1 {
+			return nil, fmt.Errorf("%w: fuzzy_threshold must be between 0 and 1", ErrInvalidArgument)
+		}
+	}
+
+	if err := normalizeSuggestFilters(&req); err != nil {
+		return nil, err
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeSuggestCursor(req.Cursor, req)
+		if err != nil {
+			return nil, err
+		}
+		req.After = after
+	}
 
 	// Search cities with localized names in a single query (solves N+1 problem)
-	results, err := s.cityRepo.SearchCitiesWithLang(ctx, req.Query, lang, limit)
+	results, err := s.cityRepo.SearchCitiesWithLang(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search cities: %w", err)
 	}
 
-	return &model.SuggestResponse{Results: results}, nil
+	response := &model.SuggestResponse{Results: results}
+	if len(results) > limit {
+		response.Results = results[:limit]
+		last := results[limit-1]
+		response.NextCursor = encodeSuggestCursor(model.SuggestCursor{
+			Score: last.Score, Population: last.Population, ID: last.ID,
+		}, req)
+	}
+
+	return response, nil
+}
+
+// normalizeSuggestFilters validates the optional suggest filters and
+// upper-cases the country codes to match the stored ISO codes
+func normalizeSuggestFilters(req *model.SuggestRequest) error {
+	var codes []string
+	for _, code := range req.CountryCodes {
+		if len(code) != 2 {
+			return fmt.Errorf("%w: invalid country code %q", ErrInvalidArgument, code)
+		}
+		codes = append(codes, strings.ToUpper(code))
+	}
+	req.CountryCodes = codes
+
+	if req.MinPopulation < 0 || req.MaxPopulation < 0 {
+		return fmt.Errorf("%w: population bounds must not be negative", ErrInvalidArgument)
+	}
+	if req.MaxPopulation > 0 && req.MinPopulation > req.MaxPopulation {
+		return fmt.Errorf("%w: min_population must not exceed max_population", ErrInvalidArgument)
+	}
+
+	featureCodes, err := resolveFeatureFilter(req.FeatureCodes, req.FeatureRank)
+	if err != nil {
+		return err
+	}
+	req.FeatureCodes, req.FeatureRank = featureCodes, ""
+	return nil
 }
 
 // GetCityByID retrieves detailed information about a city
@@ -56,6 +131,52 @@ func (s *Service) GetCityByID(ctx context.Context, id int, lang string) (*model.
 		lang = defaultLang
 	}
 
+	return s.cityDetail(ctx, city, lang)
+}
+
+// GetCityNames lists every stored name of a city with its GeoNames flags,
+// including the colloquial and historic names left out of translations.
+// Name is the display name in lang. It returns nil if the city does not exist.
+func (s *Service) GetCityNames(ctx context.Context, id int, lang string) (*model.CityNamesResponse, error) {
+	if lang == "" {
+		lang = defaultLang
+	}
+
+	city, err := s.cityRepo.GetCityByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city: %w", err)
+	}
+	if city == nil {
+		return nil, nil
+	}
+
+	name, err := s.cityRepo.GetCityName(ctx, city.ID, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city name: %w", err)
+	}
+
+	alternateNames, err := s.cityRepo.GetCityAlternateNames(ctx, city.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city names: %w", err)
+	}
+
+	names := make([]model.AlternateNameResponse, 0, len(alternateNames))
+	for _, n := range alternateNames {
+		names = append(names, model.AlternateNameResponse{
+			Name:         n.Name,
+			Lang:         n.Lang,
+			IsPreferred:  n.IsPreferred,
+			IsShort:      n.IsShort,
+			IsColloquial: n.IsColloquial,
+			IsHistoric:   n.IsHistoric,
+		})
+	}
+
+	return &model.CityNamesResponse{ID: city.ID, Name: name, Names: names}, nil
+}
+
+// cityDetail builds the localized detail response for a city
+func (s *Service) cityDetail(ctx context.Context, city *model.City, lang string) (*model.CityDetailResponse, error) {
 	// Get localized city name
 	cityName, err := s.cityRepo.GetCityName(ctx, city.ID, lang)
 	if err != nil {
@@ -68,20 +189,51 @@ func (s *Service) GetCityByID(ctx context.Context, id int, lang string) (*model.
 		return nil, fmt.Errorf("failed to get country name: %w", err)
 	}
 
-	response := &model.CityDetailResponse{
-		ID:      city.ID,
-		Name:    cityName,
-		Country: countryName,
+	region, subregion, err := s.regionNames(ctx, city, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newCityDetail(city, model.CityNames{
+		Name: cityName, Country: countryName, Region: region, Subregion: subregion,
+	}), nil
+}
+
+// newCityDetail builds the detail of city from its localized names
+func (s *Service) newCityDetail(city *model.City, names model.CityNames) *model.CityDetailResponse {
+	return &model.CityDetailResponse{
+		ID:        city.ID,
+		Name:      names.Name,
+		Country:   names.Country,
+		Region:    names.Region,
+		Subregion: names.Subregion,
 		Coordinates: model.Coordinate{
 			Lat: city.Lat,
 			Lon: city.Lon,
 		},
-		Elevation:  city.Elevation,
-		Population: city.Population,
-		Timezone:   city.Timezone,
+		Elevation:    city.Elevation,
+		Population:   city.Population,
+		Timezone:     city.Timezone,
+		FeatureClass: city.FeatureClass,
+		FeatureCode:  city.FeatureCode,
+		Time:         timeInfo(city.Timezone, s.now()),
 	}
+}
 
-	return response, nil
+// regionNames returns the localized names of the city's admin1 and admin2
+// divisions; cities without admin codes have none
+func (s *Service) regionNames(ctx context.Context, city *model.City, lang string) (region, subregion string, err error) {
+	if key := city.Admin1Key(); key != "" {
+		if region, err = s.countryRepo.GetAdminDivisionName(ctx, key, lang); err != nil {
+			return "", "", fmt.Errorf("failed to get region name: %w", err)
+		}
+	}
+	if key := city.Admin2Key(); key != "" {
+		if subregion, err = s.countryRepo.GetAdminDivisionName(ctx, key, lang); err != nil {
+			return "", "", fmt.Errorf("failed to get subregion name: %w", err)
+		}
+	}
+	return region, subregion, nil
 }
 
 // FindNearestCity finds the closest city to the given coordinates
@@ -98,28 +250,134 @@ func (s *Service) FindNearestCity(ctx context.Context, lat, lon float64, lang st
 		return nil, nil
 	}
 
-	// Get localized names
-	cityName, err := s.cityRepo.GetCityName(ctx, city.ID, lang)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get city name: %w", err)
-	}
-
-	countryName, err := s.countryRepo.GetCountryName(ctx, city.CountryCode, lang)
+	detail, err := s.cityDetail(ctx, city, lang)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get country name: %w", err)
+		return nil, err
 	}
 
 	return &model.NearestCityResponse{
-		City: model.CityDetailResponse{
-			ID:          city.ID,
-			Name:        cityName,
-			Country:     countryName,
-			Coordinates: model.Coordinate{Lat: city.Lat, Lon: city.Lon},
-			Elevation:   city.Elevation,
-			Population:  city.Population,
-			Timezone:    city.Timezone,
-		},
+		City:               *detail,
 		RequestCoordinates: model.Coordinate{Lat: lat, Lon: lon},
 		DistanceKm:         dist,
 	}, nil
 }
+
+// FindNearestCities finds up to req.Count closest cities, ordered by distance.
+// When req.MaxKm is set, cities farther away than that are left out.
+func (s *Service) FindNearestCities(ctx context.Context, req model.NearestRequest) (*model.NearestCitiesResponse, error) {
+	if req.Lang == "" {
+		req.Lang = defaultLang
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	if req.Count > maxNearestCount {
+		req.Count = maxNearestCount
+	}
+	if req.MaxKm < 0 {
+		return nil, fmt.Errorf("%w: max_km must not be negative", ErrInvalidArgument)
+	}
+	featureCodes, err := resolveFeatureFilter(req.FeatureCodes, req.FeatureRank)
+	if err != nil {
+		return nil, err
+	}
+	req.FeatureCodes, req.FeatureRank = featureCodes, ""
+
+	cities, err := s.cityRepo.FindNearestCities(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearest cities: %w", err)
+	}
+
+	// The repository returns the cities with their names in req.Lang
+	results := make([]model.NearestCityResponse, 0, len(cities))
+	for i := range cities {
+		results = append(results, model.NearestCityResponse{
+			City:               *s.newCityDetail(&cities[i].City, cities[i].CityNames),
+			RequestCoordinates: model.Coordinate{Lat: req.Lat, Lon: req.Lon},
+			DistanceKm:         cities[i].Distance,
+		})
+	}
+
+	return &model.NearestCitiesResponse{Results: results}, nil
+}
+
+// FindCitiesWithin returns a page of cities within req.RadiusKm of the given point
+func (s *Service) FindCitiesWithin(ctx context.Context, req model.WithinRequest) (*model.WithinResponse, error) {
+	if req.RadiusKm <= 0 || req.RadiusKm > maxWithinRadiusKm {
+		return nil, fmt.Errorf("%w: radius_km must be greater than 0 and at most %d", ErrInvalidArgument, maxWithinRadiusKm)
+	}
+	if req.SortBy == "" {
+		req.SortBy = model.SortByDistance
+	}
+	if req.SortBy != model.SortByDistance && req.SortBy != model.SortByPopulation {
+		return nil, fmt.Errorf("%w: unknown sort order %q", ErrInvalidArgument, req.SortBy)
+	}
+	if req.Lang == "" {
+		req.Lang = defaultLang
+	}
+	pageSize := req.Limit
+	if pageSize <= 0 {
+		pageSize = defaultLimit
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeWithinCursor(req.Cursor, req)
+		if err != nil {
+			return nil, err
+		}
+		req.After = after
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	req.Limit = pageSize + 1
+	results, err := s.cityRepo.FindCitiesWithin(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cities within radius: %w", err)
+	}
+
+	response := &model.WithinResponse{Results: results}
+	if len(results) > pageSize {
+		response.Results = results[:pageSize]
+		last := results[pageSize-1]
+		response.NextCursor = encodeWithinCursor(model.WithinCursor{
+			Distance: *last.DistanceKm, Population: last.Population, ID: last.ID,
+		}, req)
+	}
+	if response.Results == nil {
+		response.Results = []model.CityResult{}
+	}
+	return response, nil
+}
+
+// FindCitiesInBBox returns the largest cities inside a map viewport.
+// A viewport with MinLon > MaxLon is taken to cross the antimeridian.
+func (s *Service) FindCitiesInBBox(ctx context.Context, req model.BBoxRequest) (*model.BBoxResponse, error) {
+	if req.MinLat < -90 || req.MaxLat > 90 || math.Abs(req.MinLon) > 180 || math.Abs(req.MaxLon) > 180 {
+		return nil, fmt.Errorf("%w: bounding box outside valid coordinate range", ErrInvalidArgument)
+	}
+	if req.MinLat > req.MaxLat {
+		return nil, fmt.Errorf("%w: min_lat must not exceed max_lat", ErrInvalidArgument)
+	}
+	if req.Lang == "" {
+		req.Lang = defaultLang
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultBBoxLimit
+	}
+	if req.Limit > maxBBoxLimit {
+		req.Limit = maxBBoxLimit
+	}
+
+	results, err := s.cityRepo.FindCitiesInBBox(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cities in bounding box: %w", err)
+	}
+	if results == nil {
+		results = []model.CityResult{}
+	}
+
+	return &model.BBoxResponse{Results: results}, nil
+}
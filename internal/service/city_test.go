@@ -7,6 +7,7 @@ import (
 	"github.com/alexivanou/geocity-api/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockCityRepository implements repository.CityRepository interface
@@ -22,8 +23,8 @@ func (m *MockCityRepository) SearchCities(ctx context.Context, query string, lim
 	return args.Get(0).([]model.City), args.Error(1)
 }
 
-func (m *MockCityRepository) SearchCitiesWithLang(ctx context.Context, query string, lang string, limit int) ([]model.CityResult, error) {
-	args := m.Called(ctx, query, lang, limit)
+func (m *MockCityRepository) SearchCitiesWithLang(ctx context.Context, req model.SuggestRequest) ([]model.CityResult, error) {
+	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -38,6 +39,30 @@ func (m *MockCityRepository) FindNearestCity(ctx context.Context, lat, lon float
 	return args.Get(0).(*model.City), args.Get(1).(float64), args.Error(2)
 }
 
+func (m *MockCityRepository) FindNearestCities(ctx context.Context, req model.NearestRequest) ([]model.CityWithDistance, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CityWithDistance), args.Error(1)
+}
+
+func (m *MockCityRepository) FindCitiesWithin(ctx context.Context, req model.WithinRequest) ([]model.CityResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CityResult), args.Error(1)
+}
+
+func (m *MockCityRepository) FindCitiesInBBox(ctx context.Context, req model.BBoxRequest) ([]model.CityResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CityResult), args.Error(1)
+}
+
 func (m *MockCityRepository) GetCityByID(ctx context.Context, id int) (*model.City, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -51,6 +76,14 @@ func (m *MockCityRepository) GetCityName(ctx context.Context, cityID int, lang s
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockCityRepository) GetCityAlternateNames(ctx context.Context, cityID int) ([]model.CityAlternateName, error) {
+	args := m.Called(ctx, cityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CityAlternateName), args.Error(1)
+}
+
 func (m *MockCityRepository) BulkInsertCities(ctx context.Context, cities []model.City) error {
 	args := m.Called(ctx, cities)
 	return args.Error(0)
@@ -71,6 +104,47 @@ func (m *MockCountryRepository) BulkInsertCountries(ctx context.Context, countri
 	return args.Error(0)
 }
 
+func (m *MockCountryRepository) ListCountries(ctx context.Context, lang string) ([]model.CountryWithName, error) {
+	args := m.Called(ctx, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CountryWithName), args.Error(1)
+}
+
+func (m *MockCountryRepository) GetCountry(ctx context.Context, code string, lang string) (*model.CountryWithName, error) {
+	args := m.Called(ctx, code, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CountryWithName), args.Error(1)
+}
+
+func (m *MockCountryRepository) GetAdminDivisionName(ctx context.Context, code string, lang string) (string, error) {
+	args := m.Called(ctx, code, lang)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCountryRepository) BulkInsertAdminDivisions(ctx context.Context, divisions []model.AdminDivision) error {
+	args := m.Called(ctx, divisions)
+	return args.Error(0)
+}
+func (m *MockCountryRepository) BulkInsertContinents(ctx context.Context, continents []model.Continent) error {
+	args := m.Called(ctx, continents)
+	return args.Error(0)
+}
+func (m *MockCountryRepository) GetPlaceAncestors(ctx context.Context, geonameID int, lang string) ([]model.Place, error) {
+	args := m.Called(ctx, geonameID, lang)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Place), args.Error(1)
+}
+func (m *MockCountryRepository) BulkInsertHierarchy(ctx context.Context, edges []model.HierarchyEdge) error {
+	args := m.Called(ctx, edges)
+	return args.Error(0)
+}
+
 type MockTranslationRepository struct {
 	mock.Mock
 }
@@ -83,6 +157,18 @@ func (m *MockTranslationRepository) BulkInsertCountryTranslations(ctx context.Co
 	args := m.Called(ctx, translations)
 	return args.Error(0)
 }
+func (m *MockTranslationRepository) BulkInsertAdminDivisionTranslations(ctx context.Context, translations []model.AdminDivisionTranslation) error {
+	args := m.Called(ctx, translations)
+	return args.Error(0)
+}
+func (m *MockTranslationRepository) BulkInsertContinentTranslations(ctx context.Context, translations []model.ContinentTranslation) error {
+	args := m.Called(ctx, translations)
+	return args.Error(0)
+}
+func (m *MockTranslationRepository) BulkInsertCityAlternateNames(ctx context.Context, names []model.CityAlternateName) error {
+	args := m.Called(ctx, names)
+	return args.Error(0)
+}
 func (m *MockTranslationRepository) GetAvailableLanguages(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -91,6 +177,53 @@ func (m *MockTranslationRepository) GetAvailableLanguages(ctx context.Context) (
 	return args.Get(0).([]string), args.Error(1)
 }
 
+type MockPostalCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockPostalCodeRepository) FindPostalCodes(ctx context.Context, countryCode, postalCode string) ([]model.PostalCode, error) {
+	args := m.Called(ctx, countryCode, postalCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PostalCode), args.Error(1)
+}
+func (m *MockPostalCodeRepository) FindPostalCodesNear(ctx context.Context, req model.PostalNearestRequest) ([]model.PostalCodeWithDistance, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PostalCodeWithDistance), args.Error(1)
+}
+func (m *MockPostalCodeRepository) BulkInsertPostalCodes(ctx context.Context, codes []model.PostalCode) error {
+	args := m.Called(ctx, codes)
+	return args.Error(0)
+}
+
+type MockUpdateRepository struct {
+	mock.Mock
+}
+
+func (m *MockUpdateRepository) LastUpdate(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+func (m *MockUpdateRepository) RecordUpdate(ctx context.Context, update model.DataUpdate) error {
+	args := m.Called(ctx, update)
+	return args.Error(0)
+}
+func (m *MockUpdateRepository) ApplyDailyUpdate(ctx context.Context, update model.DailyUpdate) (*model.DataUpdate, error) {
+	args := m.Called(ctx, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DataUpdate), args.Error(1)
+}
+func (m *MockUpdateRepository) DataVersion(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
 func TestService_SuggestCities(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -107,12 +240,77 @@ func TestService_SuggestCities(t *testing.T) {
 				Limit: 10,
 			},
 			setupMocks: func(cityRepo *MockCityRepository, countryRepo *MockCountryRepository) {
-				cityRepo.On("SearchCitiesWithLang", mock.Anything, "Dub", "en", 10).Return([]model.CityResult{
+				cityRepo.On("SearchCitiesWithLang", mock.Anything, model.SuggestRequest{Query: "Dub", Lang: "en", Limit: 11}).Return([]model.CityResult{
 					{ID: 1, Name: "Dublin", Country: "Ireland", CountryCode: "IE", Population: 500000},
 				}, nil)
 			},
 			expectedCount: 1,
 		},
+		{
+			name: "fuzzy search uses default threshold",
+			req: model.SuggestRequest{
+				Query: "Berlln",
+				Fuzzy: true,
+			},
+			setupMocks: func(cityRepo *MockCityRepository, countryRepo *MockCountryRepository) {
+				cityRepo.On("SearchCitiesWithLang", mock.Anything, model.SuggestRequest{
+					Query: "Berlln", Lang: "en", Limit: 11, Fuzzy: true, FuzzyThreshold: defaultFuzzyThreshold,
+				}).Return([]model.CityResult{
+					{ID: 2, Name: "Berlin", Country: "Germany", CountryCode: "DE", Population: 3600000},
+				}, nil)
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "fuzzy threshold out of range",
+			req: model.SuggestRequest{
+				Query:          "Berlln",
+				Fuzzy:          true,
+				FuzzyThreshold: 1.5,
+			},
+			expectedError: "fuzzy_threshold must be between 0 and 1",
+		},
+		{
+			name: "country codes are upper-cased",
+			req: model.SuggestRequest{
+				Query:         "Fr",
+				CountryCodes:  []string{"de", "AT"},
+				MinPopulation: 100000,
+			},
+			setupMocks: func(cityRepo *MockCityRepository, countryRepo *MockCountryRepository) {
+				cityRepo.On("SearchCitiesWithLang", mock.Anything, model.SuggestRequest{
+					Query: "Fr", Lang: "en", Limit: 11, CountryCodes: []string{"DE", "AT"}, MinPopulation: 100000,
+				}).Return([]model.CityResult{
+					{ID: 3, Name: "Frankfurt", Country: "Germany", CountryCode: "DE", Population: 750000},
+				}, nil)
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "malformed cursor",
+			req: model.SuggestRequest{
+				Query:  "Fr",
+				Cursor: "not a cursor",
+			},
+			expectedError: "malformed cursor",
+		},
+		{
+			name: "invalid country code",
+			req: model.SuggestRequest{
+				Query:        "Fr",
+				CountryCodes: []string{"DEU"},
+			},
+			expectedError: "invalid country code",
+		},
+		{
+			name: "min population above max",
+			req: model.SuggestRequest{
+				Query:         "Fr",
+				MinPopulation: 500000,
+				MaxPopulation: 1000,
+			},
+			expectedError: "min_population must not exceed max_population",
+		},
 		{
 			name: "query too short",
 			req: model.SuggestRequest{
@@ -141,7 +339,7 @@ func TestService_SuggestCities(t *testing.T) {
 				tt.setupMocks(mockCityRepo, mockCountryRepo)
 			}
 
-			svc := NewService(mockCityRepo, mockCountryRepo, mockTranslationRepo)
+			svc := NewService(mockCityRepo, mockCountryRepo, mockTranslationRepo, new(MockPostalCodeRepository), new(MockUpdateRepository))
 
 			resp, err := svc.SuggestCities(context.Background(), tt.req)
 
@@ -157,3 +355,230 @@ func TestService_SuggestCities(t *testing.T) {
 		})
 	}
 }
+
+func TestService_SuggestCities_Pagination(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	svc := NewService(mockCityRepo, new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	ctx := context.Background()
+
+	page := []model.CityResult{
+		{ID: 1, Name: "Paris", Population: 2100000, Score: 3000},
+		{ID: 2, Name: "Parma", Population: 200000, Score: 3000},
+		{ID: 3, Name: "Sparta", Population: 9000000, Score: 1000},
+	}
+	mockCityRepo.On("SearchCitiesWithLang", ctx, model.SuggestRequest{Query: "Par", Lang: "en", Limit: 3}).
+		Return(page, nil).Once()
+
+	resp, err := svc.SuggestCities(ctx, model.SuggestRequest{Query: "Par", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+	require.NotEmpty(t, resp.NextCursor)
+
+	// The cursor resumes after the last returned row
+	mockCityRepo.On("SearchCitiesWithLang", ctx, model.SuggestRequest{
+		Query: "Par", Lang: "en", Limit: 3, Cursor: resp.NextCursor,
+		After: &model.SuggestCursor{Score: 3000, Population: 200000, ID: 2},
+	}).Return(page[2:], nil).Once()
+
+	cursor := resp.NextCursor
+	resp, err = svc.SuggestCities(ctx, model.SuggestRequest{Query: "Par", Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 1)
+	assert.Empty(t, resp.NextCursor)
+
+	// The cursor is bound to the query and filters it was issued for
+	for _, other := range []model.SuggestRequest{
+		{Query: "Ber", Limit: 2, Cursor: cursor},
+		{Query: "Par", Lang: "de", Limit: 2, Cursor: cursor},
+		{Query: "Par", Limit: 2, CountryCodes: []string{"fr"}, Cursor: cursor},
+	} {
+		_, err = svc.SuggestCities(ctx, other)
+		assert.ErrorIs(t, err, ErrInvalidArgument, "%+v", other)
+	}
+
+	// Oversized limits are capped
+	mockCityRepo.On("SearchCitiesWithLang", ctx, model.SuggestRequest{Query: "Par", Lang: "en", Limit: maxPageSize + 1}).
+		Return([]model.CityResult{}, nil).Once()
+	_, err = svc.SuggestCities(ctx, model.SuggestRequest{Query: "Par", Limit: 10000})
+	require.NoError(t, err)
+
+	mockCityRepo.AssertExpectations(t)
+}
+
+func TestService_GetCityByID_Region(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	mockCountryRepo := new(MockCountryRepository)
+
+	mockCityRepo.On("GetCityByID", mock.Anything, 4887398).Return(&model.City{
+		ID: 4887398, CountryCode: "US", NameDefault: "Chicago", Admin1Code: "IL", Admin2Code: "031",
+	}, nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 4887398, "de").Return("Chicago", nil)
+	mockCountryRepo.On("GetCountryName", mock.Anything, "US", "de").Return("Vereinigte Staaten", nil)
+	mockCountryRepo.On("GetAdminDivisionName", mock.Anything, "US.IL", "de").Return("Illinois", nil)
+	mockCountryRepo.On("GetAdminDivisionName", mock.Anything, "US.IL.031", "de").Return("Cook County", nil)
+
+	svc := NewService(mockCityRepo, mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	resp, err := svc.GetCityByID(context.Background(), 4887398, "de")
+	require.NoError(t, err)
+	assert.Equal(t, "Illinois", resp.Region)
+	assert.Equal(t, "Cook County", resp.Subregion)
+	mockCountryRepo.AssertExpectations(t)
+}
+
+func TestService_FindNearestCities(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	mockCountryRepo := new(MockCountryRepository)
+	mockTranslationRepo := new(MockTranslationRepository)
+
+	// Count above the cap is clamped before reaching the repository
+	mockCityRepo.On("FindNearestCities", mock.Anything, mock.MatchedBy(func(req model.NearestRequest) bool {
+		return req.Count == maxNearestCount && req.MaxKm == 30 && req.Lang == "en"
+	})).Return([]model.CityWithDistance{
+		{
+			City:      model.City{ID: 1, CountryCode: "DE", NameDefault: "Berlin"},
+			Distance:  0.4,
+			CityNames: model.CityNames{Name: "Berlin", Country: "Germany", Region: "Land Berlin"},
+		},
+		{
+			City:      model.City{ID: 2, CountryCode: "DE", NameDefault: "Potsdam"},
+			Distance:  26.1,
+			CityNames: model.CityNames{Name: "Potsdam", Country: "Germany", Region: "Brandenburg"},
+		},
+	}, nil)
+
+	svc := NewService(mockCityRepo, mockCountryRepo, mockTranslationRepo, new(MockPostalCodeRepository), new(MockUpdateRepository))
+
+	resp, err := svc.FindNearestCities(context.Background(), model.NearestRequest{
+		Lat: 52.5, Lon: 13.4, Count: 500, MaxKm: 30,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+	assert.Equal(t, "Berlin", resp.Results[0].City.Name)
+	assert.Equal(t, "Potsdam", resp.Results[1].City.Name)
+	assert.Equal(t, "Brandenburg", resp.Results[1].City.Region)
+	assert.Equal(t, "Germany", resp.Results[1].City.Country)
+	assert.Equal(t, 26.1, resp.Results[1].DistanceKm)
+	assert.Equal(t, 52.5, resp.Results[1].RequestCoordinates.Lat)
+
+	// The names come with the cities; nothing is looked up per result
+	mockCityRepo.AssertNotCalled(t, "GetCityName", mock.Anything, mock.Anything, mock.Anything)
+	mockCountryRepo.AssertNotCalled(t, "GetCountryName", mock.Anything, mock.Anything, mock.Anything)
+	mockCountryRepo.AssertNotCalled(t, "GetAdminDivisionName", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_FindNearestCities_FeatureRank(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	mockCityRepo.On("FindNearestCities", mock.Anything, model.NearestRequest{
+		Lat: 52.5, Lon: 13.4, Count: 1, Lang: "en", FeatureCodes: []string{"PPLA", "PPLC", "PPLG"},
+	}).Return([]model.CityWithDistance{}, nil)
+
+	svc := NewService(mockCityRepo, new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	resp, err := svc.FindNearestCities(context.Background(), model.NearestRequest{Lat: 52.5, Lon: 13.4, FeatureRank: "ppla"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+	mockCityRepo.AssertExpectations(t)
+}
+
+func TestResolveFeatureFilter(t *testing.T) {
+	codes, err := resolveFeatureFilter([]string{"pplc", "PPLA2"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PPLC", "PPLA2"}, codes)
+
+	codes, err = resolveFeatureFilter(nil, "PPLA2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PPLA", "PPLA2", "PPLC", "PPLG"}, codes)
+
+	codes, err = resolveFeatureFilter(nil, "")
+	require.NoError(t, err)
+	assert.Nil(t, codes)
+
+	_, err = resolveFeatureFilter(nil, "PPL")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = resolveFeatureFilter([]string{"PPLC"}, "PPLA")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestService_FindCitiesWithin(t *testing.T) {
+	t.Run("next page cursor", func(t *testing.T) {
+		distance := func(d float64) *float64 { return &d }
+		page := []model.CityResult{
+			{ID: 1, DistanceKm: distance(1)},
+			{ID: 2, Population: 500, DistanceKm: distance(2)},
+			{ID: 3, DistanceKm: distance(3)},
+		}
+		mockCityRepo := new(MockCityRepository)
+		mockCityRepo.On("FindCitiesWithin", mock.Anything, model.WithinRequest{
+			Lat: 52.5, Lon: 13.4, RadiusKm: 50, Lang: "en", SortBy: model.SortByDistance, Limit: 3,
+		}).Return(page, nil).Once()
+
+		svc := NewService(mockCityRepo, new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+		resp, err := svc.FindCitiesWithin(context.Background(), model.WithinRequest{
+			Lat: 52.5, Lon: 13.4, RadiusKm: 50, Limit: 2,
+		})
+		require.NoError(t, err)
+		assert.Len(t, resp.Results, 2)
+		require.NotEmpty(t, resp.NextCursor)
+
+		// The cursor resumes after the last returned row
+		mockCityRepo.On("FindCitiesWithin", mock.Anything, model.WithinRequest{
+			Lat: 52.5, Lon: 13.4, RadiusKm: 50, Lang: "en", SortBy: model.SortByDistance, Limit: 3,
+			Cursor: resp.NextCursor, After: &model.WithinCursor{Distance: 2, Population: 500, ID: 2},
+		}).Return(page[2:], nil).Once()
+
+		next, err := svc.FindCitiesWithin(context.Background(), model.WithinRequest{
+			Lat: 52.5, Lon: 13.4, RadiusKm: 50, Limit: 2, Cursor: resp.NextCursor,
+		})
+		require.NoError(t, err)
+		assert.Len(t, next.Results, 1)
+		assert.Empty(t, next.NextCursor)
+
+		// A cursor only pages the circle and order it was issued for
+		_, err = svc.FindCitiesWithin(context.Background(), model.WithinRequest{
+			Lat: 52.5, Lon: 13.4, RadiusKm: 50, SortBy: model.SortByPopulation, Cursor: resp.NextCursor,
+		})
+		assert.ErrorIs(t, err, ErrInvalidArgument)
+		mockCityRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid radius", func(t *testing.T) {
+		svc := NewService(new(MockCityRepository), new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+		_, err := svc.FindCitiesWithin(context.Background(), model.WithinRequest{RadiusKm: 5000})
+		assert.ErrorIs(t, err, ErrInvalidArgument)
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		svc := NewService(new(MockCityRepository), new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+		_, err := svc.FindCitiesWithin(context.Background(), model.WithinRequest{RadiusKm: 10, SortBy: "name"})
+		assert.ErrorIs(t, err, ErrInvalidArgument)
+	})
+}
+
+func TestService_GetCityNames(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	mockCityRepo.On("GetCityByID", mock.Anything, 2950159).Return(&model.City{ID: 2950159, CountryCode: "DE"}, nil)
+	mockCityRepo.On("GetCityByID", mock.Anything, 1).Return(nil, nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 2950159, "en").Return("Berlin", nil)
+	mockCityRepo.On("GetCityAlternateNames", mock.Anything, 2950159).Return([]model.CityAlternateName{
+		{ID: 1, CityID: 2950159, Lang: "de", Name: "Berlin", IsPreferred: true},
+		{ID: 2, CityID: 2950159, Lang: "de", Name: "Spree-Athen", IsColloquial: true},
+		{ID: 3, CityID: 2950159, Lang: "la", Name: "Berolinum", IsHistoric: true},
+	}, nil)
+
+	svc := NewService(mockCityRepo, new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	ctx := context.Background()
+
+	resp, err := svc.GetCityNames(ctx, 2950159, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2950159, resp.ID)
+	assert.Equal(t, "Berlin", resp.Name)
+	assert.Equal(t, []model.AlternateNameResponse{
+		{Name: "Berlin", Lang: "de", IsPreferred: true},
+		{Name: "Spree-Athen", Lang: "de", IsColloquial: true},
+		{Name: "Berolinum", Lang: "la", IsHistoric: true},
+	}, resp.Names)
+
+	resp, err = svc.GetCityNames(ctx, 1, "en")
+	require.NoError(t, err)
+	assert.Nil(t, resp)
+}
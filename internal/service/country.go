@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+)
+
+// ListCountries returns the country catalogue with names in lang
+func (s *Service) ListCountries(ctx context.Context, lang string) (*model.CountriesResponse, error) {
+	if lang == "" {
+		lang = defaultLang
+	}
+
+	countries, err := s.countryRepo.ListCountries(ctx, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list countries: %w", err)
+	}
+
+	results := make([]model.CountryResponse, 0, len(countries))
+	for i := range countries {
+		results = append(results, countryResponse(&countries[i]))
+	}
+	return &model.CountriesResponse{Results: results}, nil
+}
+
+// GetCountry returns a single country with its name in lang, or nil if the
+// code is unknown
+func (s *Service) GetCountry(ctx context.Context, code string, lang string) (*model.CountryResponse, error) {
+	code, err := normalizeCountryCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if lang == "" {
+		lang = defaultLang
+	}
+
+	country, err := s.countryRepo.GetCountry(ctx, code, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get country: %w", err)
+	}
+	if country == nil {
+		return nil, nil
+	}
+
+	response := countryResponse(country)
+	return &response, nil
+}
+
+func countryResponse(c *model.CountryWithName) model.CountryResponse {
+	return model.CountryResponse{
+		Code:             c.Code,
+		ISO3:             c.ISO3,
+		ISONumeric:       c.ISONumeric,
+		Name:             c.Name,
+		Capital:          c.Capital,
+		AreaSqKm:         c.AreaSqKm,
+		Population:       c.Population,
+		Continent:        c.Continent,
+		TLD:              c.TLD,
+		CurrencyCode:     c.CurrencyCode,
+		CurrencyName:     c.CurrencyName,
+		Phone:            c.Phone,
+		PostalCodeFormat: c.PostalCodeFormat,
+		PostalCodeRegex:  c.PostalCodeRegex,
+		Languages:        splitList(c.Languages),
+		Neighbours:       splitList(c.Neighbours),
+	}
+}
+
+// splitList splits a comma-separated countryInfo column; an empty column
+// yields an empty (not nil) list so that it renders as []
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ListCountries(t *testing.T) {
+	mockCountryRepo := new(MockCountryRepository)
+	mockCountryRepo.On("ListCountries", mock.Anything, "en").Return([]model.CountryWithName{
+		{Country: model.Country{Code: "AQ", Languages: "", Neighbours: ""}, Name: "Antarctica"},
+		{Country: model.Country{Code: "AT", Languages: "de-AT,hr,hu,sl", Neighbours: "CH,DE"}, Name: "Austria"},
+	}, nil)
+
+	svc := NewService(new(MockCityRepository), mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	resp, err := svc.ListCountries(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, []string{}, resp.Results[0].Languages)
+	assert.Equal(t, []string{}, resp.Results[0].Neighbours)
+	assert.Equal(t, "Austria", resp.Results[1].Name)
+	assert.Equal(t, []string{"de-AT", "hr", "hu", "sl"}, resp.Results[1].Languages)
+	assert.Equal(t, []string{"CH", "DE"}, resp.Results[1].Neighbours)
+}
+
+func TestService_GetCountry(t *testing.T) {
+	t.Run("code is upper-cased", func(t *testing.T) {
+		mockCountryRepo := new(MockCountryRepository)
+		mockCountryRepo.On("GetCountry", mock.Anything, "AT", "de").Return(&model.CountryWithName{
+			Country: model.Country{Code: "AT", ISO3: "AUT", Capital: "Vienna"}, Name: "Österreich",
+		}, nil)
+
+		svc := NewService(new(MockCityRepository), mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+		resp, err := svc.GetCountry(context.Background(), "at", "de")
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "Österreich", resp.Name)
+		assert.Equal(t, "AUT", resp.ISO3)
+	})
+
+	t.Run("unknown country", func(t *testing.T) {
+		mockCountryRepo := new(MockCountryRepository)
+		mockCountryRepo.On("GetCountry", mock.Anything, "ZZ", "en").Return(nil, nil)
+
+		svc := NewService(new(MockCityRepository), mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+		resp, err := svc.GetCountry(context.Background(), "ZZ", "en")
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		svc := NewService(new(MockCityRepository), new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+		_, err := svc.GetCountry(context.Background(), "AUT", "en")
+		assert.ErrorIs(t, err, ErrInvalidArgument)
+	})
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+)
+
+// cursorToken is what an opaque next_cursor holds: the position of the last
+// result of a page and a hash of the query and filters of that page, so that
+// a cursor cannot be replayed against a different query
+type cursorToken struct {
+	Query    string          `json:"q"`
+	Position json.RawMessage `json:"p"`
+}
+
+// queryHash hashes the paged request scope; it must not include the page
+// size or position
+func queryHash(scope any) string {
+	data, _ := json.Marshal(scope)
+	h := fnv.New64a()
+	h.Write(data)
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// encodeCursor turns a page position into the opaque token handed to clients
+func encodeCursor(position, scope any) string {
+	data, _ := json.Marshal(position)
+	token, _ := json.Marshal(cursorToken{Query: queryHash(scope), Position: data})
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// decodeCursor parses a token produced by encodeCursor for the same scope
+// into position
+func decodeCursor(token string, scope, position any) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return fmt.Errorf("%w: malformed cursor", ErrInvalidArgument)
+	}
+	var c cursorToken
+	if err := json.Unmarshal(data, &c); err != nil || len(c.Position) == 0 {
+		return fmt.Errorf("%w: malformed cursor", ErrInvalidArgument)
+	}
+	if c.Query != queryHash(scope) {
+		return fmt.Errorf("%w: cursor was issued for different query parameters", ErrInvalidArgument)
+	}
+	if err := json.Unmarshal(c.Position, position); err != nil {
+		return fmt.Errorf("%w: malformed cursor", ErrInvalidArgument)
+	}
+	return nil
+}
+
+// suggestScope is the part of a suggest request a cursor is bound to
+func suggestScope(req model.SuggestRequest) model.SuggestRequest {
+	req.Limit, req.Cursor, req.After = 0, "", nil
+	return req
+}
+
+// encodeSuggestCursor turns the position of the last result of a page into the
+// opaque next_cursor token handed to clients
+func encodeSuggestCursor(c model.SuggestCursor, req model.SuggestRequest) string {
+	return encodeCursor(c, suggestScope(req))
+}
+
+// decodeSuggestCursor parses a token produced by encodeSuggestCursor for
+// the same query and filters
+func decodeSuggestCursor(token string, req model.SuggestRequest) (*model.SuggestCursor, error) {
+	var c model.SuggestCursor
+	if err := decodeCursor(token, suggestScope(req), &c); err != nil {
+		return nil, err
+	}
+	if c.ID <= 0 {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidArgument)
+	}
+	return &c, nil
+}
+
+// withinScope is the part of a radius search a cursor is bound to
+func withinScope(req model.WithinRequest) model.WithinRequest {
+	req.Limit, req.Cursor, req.After = 0, "", nil
+	return req
+}
+
+// encodeWithinCursor turns the last result of a radius search page into
+// its next_cursor token
+func encodeWithinCursor(c model.WithinCursor, req model.WithinRequest) string {
+	return encodeCursor(c, withinScope(req))
+}
+
+// decodeWithinCursor parses a token produced by encodeWithinCursor for the
+// same circle and sort order
+func decodeWithinCursor(token string, req model.WithinRequest) (*model.WithinCursor, error) {
+	var c model.WithinCursor
+	if err := decodeCursor(token, withinScope(req), &c); err != nil {
+		return nil, err
+	}
+	if c.ID <= 0 || c.Distance < 0 {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidArgument)
+	}
+	return &c, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// featureRanks orders the GeoNames populated-place codes by administrative
+// importance: capitals and seats of government first, then the seats of
+// first- through fourth-order divisions
+var featureRanks = map[string]int{
+	"PPLC":  0,
+	"PPLG":  0,
+	"PPLA":  1,
+	"PPLA2": 2,
+	"PPLA3": 3,
+	"PPLA4": 4,
+}
+
+// resolveFeatureFilter turns the feature_code and feature_rank parameters
+// into the list of feature codes repositories filter on. A rank such as
+// "PPLA" selects that code and every code ranked above it.
+func resolveFeatureFilter(codes []string, rank string) ([]string, error) {
+	if len(codes) > 0 && rank != "" {
+		return nil, fmt.Errorf("%w: feature_code and feature_rank are mutually exclusive", ErrInvalidArgument)
+	}
+
+	if rank != "" {
+		level, ok := featureRanks[strings.ToUpper(rank)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported feature_rank %q", ErrInvalidArgument, rank)
+		}
+		var resolved []string
+		for code, l := range featureRanks {
+			if l <= level {
+				resolved = append(resolved, code)
+			}
+		}
+		sort.Strings(resolved)
+		return resolved, nil
+	}
+
+	var resolved []string
+	for _, code := range codes {
+		if code == "" || len(code) > 10 {
+			return nil, fmt.Errorf("%w: invalid feature code %q", ErrInvalidArgument, code)
+		}
+		resolved = append(resolved, strings.ToUpper(code))
+	}
+	return resolved, nil
+}
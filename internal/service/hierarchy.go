@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+)
+
+// GetCityHierarchy returns the city's ancestry from hierarchy.txt, from the
+// continent down to the city itself, with localized names. Levels that were
+// not imported are left out. It returns nil if the city does not exist.
+func (s *Service) GetCityHierarchy(ctx context.Context, id int, lang string) (*model.CityHierarchyResponse, error) {
+	if lang == "" {
+		lang = defaultLang
+	}
+
+	city, err := s.cityRepo.GetCityByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city: %w", err)
+	}
+	if city == nil {
+		return nil, nil
+	}
+
+	name, err := s.cityRepo.GetCityName(ctx, city.ID, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city name: %w", err)
+	}
+
+	ancestors, err := s.countryRepo.GetPlaceAncestors(ctx, city.ID, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city hierarchy: %w", err)
+	}
+
+	// Ancestors come nearest first; the response starts at the top
+	hierarchy := make([]model.PlaceResponse, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		hierarchy = append(hierarchy, model.PlaceResponse{
+			GeonameID: ancestors[i].GeonameID,
+			Type:      ancestors[i].Kind,
+			Code:      ancestors[i].Code,
+			Name:      ancestors[i].Name,
+		})
+	}
+	hierarchy = append(hierarchy, model.PlaceResponse{GeonameID: city.ID, Type: model.PlaceCity, Name: name})
+
+	return &model.CityHierarchyResponse{ID: city.ID, Name: name, Hierarchy: hierarchy}, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GetCityHierarchy(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	mockCountryRepo := new(MockCountryRepository)
+	mockCityRepo.On("GetCityByID", mock.Anything, 2950159).Return(&model.City{ID: 2950159, CountryCode: "DE"}, nil)
+	mockCityRepo.On("GetCityByID", mock.Anything, 1).Return(nil, nil)
+	mockCityRepo.On("GetCityByID", mock.Anything, 2).Return(&model.City{ID: 2}, nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 2950159, "de").Return("Berlin", nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 2, "en").Return("Nowhere", nil)
+	mockCountryRepo.On("GetPlaceAncestors", mock.Anything, 2950159, "de").Return([]model.Place{
+		{GeonameID: 2950157, Kind: model.PlaceAdmin1, Code: "DE.16", Name: "Land Berlin"},
+		{GeonameID: 2921044, Kind: model.PlaceCountry, Code: "DE", Name: "Deutschland"},
+		{GeonameID: 6255148, Kind: model.PlaceContinent, Code: "EU", Name: "Europa"},
+	}, nil)
+	mockCountryRepo.On("GetPlaceAncestors", mock.Anything, 2, "en").Return(nil, nil)
+
+	svc := NewService(mockCityRepo, mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	ctx := context.Background()
+
+	resp, err := svc.GetCityHierarchy(ctx, 2950159, "de")
+	require.NoError(t, err)
+	assert.Equal(t, "Berlin", resp.Name)
+	assert.Equal(t, []model.PlaceResponse{
+		{GeonameID: 6255148, Type: "continent", Code: "EU", Name: "Europa"},
+		{GeonameID: 2921044, Type: "country", Code: "DE", Name: "Deutschland"},
+		{GeonameID: 2950157, Type: "admin1", Code: "DE.16", Name: "Land Berlin"},
+		{GeonameID: 2950159, Type: "city", Name: "Berlin"},
+	}, resp.Hierarchy)
+
+	resp, err = svc.GetCityHierarchy(ctx, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []model.PlaceResponse{{GeonameID: 2, Type: "city", Name: "Nowhere"}}, resp.Hierarchy,
+		"without hierarchy.txt only the city itself is listed")
+
+	resp, err = svc.GetCityHierarchy(ctx, 1, "en")
+	require.NoError(t, err)
+	assert.Nil(t, resp)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/alexivanou/geocity-api/internal/model"
 )
@@ -10,6 +11,19 @@ import (
 type ServiceInterface interface {
 	SuggestCities(ctx context.Context, req model.SuggestRequest) (*model.SuggestResponse, error)
 	GetCityByID(ctx context.Context, id int, lang string) (*model.CityDetailResponse, error)
+	GetCityTime(ctx context.Context, id int, at time.Time, lang string) (*model.CityTimeResponse, error)
+	GetCityHierarchy(ctx context.Context, id int, lang string) (*model.CityHierarchyResponse, error)
+	GetCityNames(ctx context.Context, id int, lang string) (*model.CityNamesResponse, error)
+	ConvertTime(ctx context.Context, req model.TimeConversionRequest) (*model.TimeConversionResponse, error)
 	FindNearestCity(ctx context.Context, lat, lon float64, lang string) (*model.NearestCityResponse, error)
+	FindNearestCities(ctx context.Context, req model.NearestRequest) (*model.NearestCitiesResponse, error)
+	FindCitiesWithin(ctx context.Context, req model.WithinRequest) (*model.WithinResponse, error)
+	FindCitiesInBBox(ctx context.Context, req model.BBoxRequest) (*model.BBoxResponse, error)
 	GetAvailableLanguages(ctx context.Context) ([]string, error)
+	ListCountries(ctx context.Context, lang string) (*model.CountriesResponse, error)
+	GetCountry(ctx context.Context, code string, lang string) (*model.CountryResponse, error)
+	GetCountryNeighbours(ctx context.Context, code string, lang string) (*model.CountriesResponse, error)
+	FindBorderPath(ctx context.Context, from, to string, lang string) (*model.BorderPathResponse, error)
+	GetPostalCode(ctx context.Context, country, code string, lang string) (*model.PostalCodeResponse, error)
+	FindNearestPostalCodes(ctx context.Context, req model.PostalNearestRequest) (*model.PostalCodeResponse, error)
 }
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+)
+
+const (
+	defaultPostalRadiusKm = 10
+	maxPostalRadiusKm     = 100
+)
+
+// GetPostalCode returns the places covered by a postal code, each with the
+// nearest city. Full GB, CA and NL codes ("SW1A 1AA") fall back to their
+// outward part, which is all the GeoNames dump carries. It returns nil if
+// the code is unknown.
+func (s *Service) GetPostalCode(ctx context.Context, country, code string, lang string) (*model.PostalCodeResponse, error) {
+	country, err := normalizeCountryCode(country)
+	if err != nil {
+		return nil, err
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, fmt.Errorf("%w: postal code is required", ErrInvalidArgument)
+	}
+	if lang == "" {
+		lang = defaultLang
+	}
+
+	codes, err := s.postalRepo.FindPostalCodes(ctx, country, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find postal code: %w", err)
+	}
+	if outward, _, found := strings.Cut(code, " "); len(codes) == 0 && found {
+		if codes, err = s.postalRepo.FindPostalCodes(ctx, country, outward); err != nil {
+			return nil, fmt.Errorf("failed to find postal code: %w", err)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	results := make([]model.PostalCodeResult, 0, len(codes))
+	for i := range codes {
+		result := postalCodeResult(&codes[i])
+		if result.NearestCity, err = s.FindNearestCity(ctx, codes[i].Lat, codes[i].Lon, lang); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return &model.PostalCodeResponse{Results: results}, nil
+}
+
+// FindNearestPostalCodes returns the postal codes within req.RadiusKm of a
+// point, nearest first
+func (s *Service) FindNearestPostalCodes(ctx context.Context, req model.PostalNearestRequest) (*model.PostalCodeResponse, error) {
+	if req.RadiusKm == 0 {
+		req.RadiusKm = defaultPostalRadiusKm
+	}
+	if req.RadiusKm < 0 || req.RadiusKm > maxPostalRadiusKm {
+		return nil, fmt.Errorf("%w: radius_km must be greater than 0 and at most %d", ErrInvalidArgument, maxPostalRadiusKm)
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+	if req.Limit > maxPageSize {
+		req.Limit = maxPageSize
+	}
+
+	codes, err := s.postalRepo.FindPostalCodesNear(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearest postal codes: %w", err)
+	}
+
+	results := make([]model.PostalCodeResult, 0, len(codes))
+	for i := range codes {
+		result := postalCodeResult(&codes[i].PostalCode)
+		result.DistanceKm = &codes[i].Distance
+		results = append(results, result)
+	}
+	return &model.PostalCodeResponse{Results: results}, nil
+}
+
+func postalCodeResult(p *model.PostalCode) model.PostalCodeResult {
+	return model.PostalCodeResult{
+		CountryCode: p.CountryCode,
+		PostalCode:  p.PostalCode,
+		PlaceName:   p.PlaceName,
+		Region:      p.Admin1Name,
+		Subregion:   p.Admin2Name,
+		Coordinates: model.Coordinate{Lat: p.Lat, Lon: p.Lon},
+		Accuracy:    p.Accuracy,
+	}
+}
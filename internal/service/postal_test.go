@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GetPostalCode(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	mockCountryRepo := new(MockCountryRepository)
+	mockPostalRepo := new(MockPostalCodeRepository)
+
+	mitte := model.PostalCode{
+		CountryCode: "DE", PostalCode: "10115", PlaceName: "Berlin", Admin1Name: "Berlin",
+		Lat: 52.5323, Lon: 13.3846,
+	}
+	westminster := model.PostalCode{CountryCode: "GB", PostalCode: "SW1A", PlaceName: "Westminster", Lat: 51.5, Lon: -0.13}
+	mockPostalRepo.On("FindPostalCodes", mock.Anything, "DE", "10115").Return([]model.PostalCode{mitte}, nil)
+	mockPostalRepo.On("FindPostalCodes", mock.Anything, "DE", "99999").Return(nil, nil)
+	mockPostalRepo.On("FindPostalCodes", mock.Anything, "GB", "SW1A 1AA").Return(nil, nil)
+	mockPostalRepo.On("FindPostalCodes", mock.Anything, "GB", "SW1A").Return([]model.PostalCode{westminster}, nil)
+
+	mockCityRepo.On("FindNearestCity", mock.Anything, 52.5323, 13.3846).Return(&model.City{ID: 2950159, CountryCode: "DE"}, 2.5, nil)
+	mockCityRepo.On("FindNearestCity", mock.Anything, 51.5, -0.13).Return(nil, 0.0, nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 2950159, "de").Return("Berlin", nil)
+	mockCountryRepo.On("GetCountryName", mock.Anything, "DE", "de").Return("Deutschland", nil)
+
+	svc := NewService(mockCityRepo, mockCountryRepo, new(MockTranslationRepository), mockPostalRepo, new(MockUpdateRepository))
+	ctx := context.Background()
+
+	t.Run("with nearest city", func(t *testing.T) {
+		resp, err := svc.GetPostalCode(ctx, "de", " 10115", "de")
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		result := resp.Results[0]
+		assert.Equal(t, "Berlin", result.Region)
+		assert.Equal(t, model.Coordinate{Lat: 52.5323, Lon: 13.3846}, result.Coordinates)
+		require.NotNil(t, result.NearestCity)
+		assert.Equal(t, "Deutschland", result.NearestCity.City.Country)
+		assert.Equal(t, 2.5, result.NearestCity.DistanceKm)
+		assert.Nil(t, result.DistanceKm)
+	})
+
+	t.Run("full code falls back to the outward part", func(t *testing.T) {
+		resp, err := svc.GetPostalCode(ctx, "GB", "sw1a 1aa", "")
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		assert.Equal(t, "Westminster", resp.Results[0].PlaceName)
+		assert.Nil(t, resp.Results[0].NearestCity)
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		resp, err := svc.GetPostalCode(ctx, "DE", "99999", "en")
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("invalid country", func(t *testing.T) {
+		_, err := svc.GetPostalCode(ctx, "DEU", "10115", "en")
+		assert.ErrorIs(t, err, ErrInvalidArgument)
+	})
+}
+
+func TestService_FindNearestPostalCodes(t *testing.T) {
+	mockPostalRepo := new(MockPostalCodeRepository)
+	mockPostalRepo.On("FindPostalCodesNear", mock.Anything, model.PostalNearestRequest{Lat: 52.52, Lon: 13.40, RadiusKm: 10, Limit: 10}).
+		Return([]model.PostalCodeWithDistance{
+			{PostalCode: model.PostalCode{CountryCode: "DE", PostalCode: "10178", PlaceName: "Berlin"}, Distance: 0.4},
+		}, nil)
+	mockPostalRepo.On("FindPostalCodesNear", mock.Anything, model.PostalNearestRequest{Lat: 0, Lon: 0, RadiusKm: 5, Limit: 100}).
+		Return(nil, nil)
+
+	svc := NewService(new(MockCityRepository), new(MockCountryRepository), new(MockTranslationRepository), mockPostalRepo, new(MockUpdateRepository))
+	ctx := context.Background()
+
+	resp, err := svc.FindNearestPostalCodes(ctx, model.PostalNearestRequest{Lat: 52.52, Lon: 13.40})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	require.NotNil(t, resp.Results[0].DistanceKm)
+	assert.Equal(t, 0.4, *resp.Results[0].DistanceKm)
+
+	resp, err = svc.FindNearestPostalCodes(ctx, model.PostalNearestRequest{RadiusKm: 5, Limit: 500})
+	require.NoError(t, err)
+	assert.NotNil(t, resp.Results, "empty results serialize as []")
+	assert.Empty(t, resp.Results)
+
+	_, err = svc.FindNearestPostalCodes(ctx, model.PostalNearestRequest{RadiusKm: 500})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
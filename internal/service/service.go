@@ -2,15 +2,32 @@ package service
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/alexivanou/geocity-api/internal/repository"
 )
 
+// ErrInvalidArgument is returned when request parameters fail validation
+var ErrInvalidArgument = errors.New("invalid argument")
+
 // Service provides business logic for the API
 type Service struct {
 	cityRepo        repository.CityRepository
 	countryRepo     repository.CountryRepository
 	translationRepo repository.TranslationRepository
+	postalRepo      repository.PostalCodeRepository
+	updateRepo      repository.UpdateRepository
+
+	// now is the clock; tests replace it to get deterministic local times
+	now func() time.Time
+
+	// borders is the country border graph, built on first use from the
+	// data of bordersVersion and rebuilt once the data version changes
+	bordersMu      sync.Mutex
+	borders        *borderGraph
+	bordersVersion string
 }
 
 // NewService creates a new service instance
@@ -18,11 +35,16 @@ func NewService(
 	cityRepo repository.CityRepository,
 	countryRepo repository.CountryRepository,
 	translationRepo repository.TranslationRepository,
+	postalRepo repository.PostalCodeRepository,
+	updateRepo repository.UpdateRepository,
 ) *Service {
 	return &Service{
 		cityRepo:        cityRepo,
 		countryRepo:     countryRepo,
 		translationRepo: translationRepo,
+		postalRepo:      postalRepo,
+		updateRepo:      updateRepo,
+		now:             time.Now,
 	}
 }
 
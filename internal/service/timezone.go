@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+)
+
+// ErrNoTimezone is returned for cities without a known IANA timezone
+var ErrNoTimezone = errors.New("city has no known timezone")
+
+// locations caches loaded zones; time.LoadLocation parses the zone data
+// on every call
+var locations sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// timeInfo describes the instant at in the given zone, or returns nil if the
+// zone is empty or unknown to the tz database
+func timeInfo(timezone *string, at time.Time) *model.TimeInfo {
+	if timezone == nil || *timezone == "" {
+		return nil
+	}
+	loc, err := loadLocation(*timezone)
+	if err != nil {
+		return nil
+	}
+
+	local := at.In(loc)
+	abbreviation, offset := local.Zone()
+	return &model.TimeInfo{
+		Timezone:         *timezone,
+		LocalTime:        local.Format(time.RFC3339),
+		UTCOffset:        formatUTCOffset(offset),
+		UTCOffsetSeconds: offset,
+		Abbreviation:     abbreviation,
+		DST:              local.IsDST(),
+	}
+}
+
+// formatUTCOffset renders an offset in seconds as "+05:30"
+func formatUTCOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// GetCityTime converts the instant at into the city's local time; a zero at
+// means now. It returns nil if the city does not exist.
+func (s *Service) GetCityTime(ctx context.Context, id int, at time.Time, lang string) (*model.CityTimeResponse, error) {
+	if lang == "" {
+		lang = defaultLang
+	}
+	if at.IsZero() {
+		at = s.now()
+	}
+
+	city, err := s.cityRepo.GetCityByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city: %w", err)
+	}
+	if city == nil {
+		return nil, nil
+	}
+
+	return s.cityTime(ctx, city, at, lang)
+}
+
+// localTimeLayouts are the accepted wall-clock formats of a time conversion
+var localTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// ConvertTime converts a wall-clock time in one city into the local time of
+// another. Both zones are applied with their DST rules at that date. It
+// returns nil if either city does not exist.
+func (s *Service) ConvertTime(ctx context.Context, req model.TimeConversionRequest) (*model.TimeConversionResponse, error) {
+	if req.Lang == "" {
+		req.Lang = defaultLang
+	}
+
+	fromCity, err := s.resolveCityRef(ctx, req.From)
+	if err != nil || fromCity == nil {
+		return nil, err
+	}
+	toCity, err := s.resolveCityRef(ctx, req.To)
+	if err != nil || toCity == nil {
+		return nil, err
+	}
+
+	if fromCity.Timezone == nil {
+		return nil, ErrNoTimezone
+	}
+	loc, err := loadLocation(*fromCity.Timezone)
+	if err != nil {
+		return nil, ErrNoTimezone
+	}
+
+	at := s.now()
+	if req.LocalTime != "" {
+		if at, err = parseLocalTime(req.LocalTime, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	from, err := s.cityTime(ctx, fromCity, at, req.Lang)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.cityTime(ctx, toCity, at, req.Lang)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := to.UTCOffsetSeconds - from.UTCOffsetSeconds
+	return &model.TimeConversionResponse{
+		From:                    *from,
+		To:                      *to,
+		OffsetDifference:        formatUTCOffset(diff),
+		OffsetDifferenceSeconds: diff,
+	}, nil
+}
+
+// parseLocalTime reads a wall-clock time in loc. Times skipped by a DST
+// change are normalized by the time package, e.g. 02:30 becomes 03:30.
+func parseLocalTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: at must be a local time like 2024-03-10T09:00", ErrInvalidArgument)
+}
+
+// resolveCityRef loads a city by ID or as the city nearest to the given
+// coordinates; it returns nil if there is no such city
+func (s *Service) resolveCityRef(ctx context.Context, ref model.CityRef) (*model.City, error) {
+	if ref.ID > 0 {
+		city, err := s.cityRepo.GetCityByID(ctx, ref.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get city: %w", err)
+		}
+		return city, nil
+	}
+	if ref.Coordinates == nil {
+		return nil, fmt.Errorf("%w: a city id or coordinates are required", ErrInvalidArgument)
+	}
+
+	city, _, err := s.cityRepo.FindNearestCity(ctx, ref.Coordinates.Lat, ref.Coordinates.Lon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearest city: %w", err)
+	}
+	return city, nil
+}
+
+// cityTime describes the instant at in the city's timezone
+func (s *Service) cityTime(ctx context.Context, city *model.City, at time.Time, lang string) (*model.CityTimeResponse, error) {
+	info := timeInfo(city.Timezone, at)
+	if info == nil {
+		return nil, ErrNoTimezone
+	}
+
+	name, err := s.cityRepo.GetCityName(ctx, city.ID, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city name: %w", err)
+	}
+
+	return &model.CityTimeResponse{
+		ID:       city.ID,
+		Name:     name,
+		At:       at.UTC().Format(time.RFC3339),
+		TimeInfo: *info,
+	}, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexivanou/geocity-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeInfo(t *testing.T) {
+	zone := func(name string) *string { return &name }
+	summer := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	winter := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	info := timeInfo(zone("Europe/Berlin"), summer)
+	require.NotNil(t, info)
+	assert.Equal(t, model.TimeInfo{
+		Timezone:         "Europe/Berlin",
+		LocalTime:        "2024-07-01T14:00:00+02:00",
+		UTCOffset:        "+02:00",
+		UTCOffsetSeconds: 7200,
+		Abbreviation:     "CEST",
+		DST:              true,
+	}, *info)
+
+	info = timeInfo(zone("Europe/Berlin"), winter)
+	require.NotNil(t, info)
+	assert.Equal(t, "+01:00", info.UTCOffset)
+	assert.Equal(t, "CET", info.Abbreviation)
+	assert.False(t, info.DST)
+
+	info = timeInfo(zone("Asia/Kolkata"), winter)
+	require.NotNil(t, info)
+	assert.Equal(t, "+05:30", info.UTCOffset)
+	assert.Equal(t, "2024-01-15T17:30:00+05:30", info.LocalTime)
+
+	info = timeInfo(zone("America/St_Johns"), summer)
+	require.NotNil(t, info)
+	assert.Equal(t, "-02:30", info.UTCOffset)
+	assert.True(t, info.DST)
+
+	assert.Nil(t, timeInfo(nil, summer))
+	assert.Nil(t, timeInfo(zone(""), summer))
+	assert.Nil(t, timeInfo(zone("Mars/Olympus_Mons"), summer))
+}
+
+func TestService_GetCityTime(t *testing.T) {
+	berlin, unknown := "Europe/Berlin", "Nowhere/Special"
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	mockCityRepo := new(MockCityRepository)
+	mockCityRepo.On("GetCityByID", mock.Anything, 1).Return(&model.City{ID: 1, Timezone: &berlin}, nil)
+	mockCityRepo.On("GetCityByID", mock.Anything, 2).Return(&model.City{ID: 2, Timezone: &unknown}, nil)
+	mockCityRepo.On("GetCityByID", mock.Anything, 3).Return(nil, nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 1, "en").Return("Berlin", nil)
+
+	svc := NewService(mockCityRepo, new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	t.Run("given instant", func(t *testing.T) {
+		resp, err := svc.GetCityTime(ctx, 1, time.Date(2024, 7, 1, 14, 0, 0, 0, time.FixedZone("", 7200)), "")
+		require.NoError(t, err)
+		assert.Equal(t, "Berlin", resp.Name)
+		assert.Equal(t, "2024-07-01T12:00:00Z", resp.At)
+		assert.Equal(t, "2024-07-01T14:00:00+02:00", resp.LocalTime)
+		assert.True(t, resp.DST)
+	})
+
+	t.Run("defaults to now", func(t *testing.T) {
+		resp, err := svc.GetCityTime(ctx, 1, time.Time{}, "en")
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-15T13:00:00+01:00", resp.LocalTime)
+		assert.Equal(t, "CET", resp.Abbreviation)
+	})
+
+	t.Run("unknown timezone", func(t *testing.T) {
+		_, err := svc.GetCityTime(ctx, 2, time.Time{}, "en")
+		assert.ErrorIs(t, err, ErrNoTimezone)
+	})
+
+	t.Run("unknown city", func(t *testing.T) {
+		resp, err := svc.GetCityTime(ctx, 3, time.Time{}, "en")
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestService_GetCityByID_Time(t *testing.T) {
+	tokyo := "Asia/Tokyo"
+	mockCityRepo := new(MockCityRepository)
+	mockCountryRepo := new(MockCountryRepository)
+	mockCityRepo.On("GetCityByID", mock.Anything, 1850147).Return(&model.City{ID: 1850147, CountryCode: "JP", Timezone: &tokyo}, nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 1850147, "en").Return("Tokyo", nil)
+	mockCountryRepo.On("GetCountryName", mock.Anything, "JP", "en").Return("Japan", nil)
+
+	svc := NewService(mockCityRepo, mockCountryRepo, new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	svc.now = func() time.Time { return time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC) }
+
+	resp, err := svc.GetCityByID(context.Background(), 1850147, "en")
+	require.NoError(t, err)
+	require.NotNil(t, resp.Time)
+	assert.Equal(t, "2024-07-01T21:00:00+09:00", resp.Time.LocalTime)
+	assert.Equal(t, "JST", resp.Time.Abbreviation)
+	assert.False(t, resp.Time.DST)
+}
+
+func TestService_ConvertTime(t *testing.T) {
+	berlin, newYork, kolkata := "Europe/Berlin", "America/New_York", "Asia/Kolkata"
+
+	mockCityRepo := new(MockCityRepository)
+	mockCityRepo.On("GetCityByID", mock.Anything, 1).Return(&model.City{ID: 1, Timezone: &berlin}, nil)
+	mockCityRepo.On("GetCityByID", mock.Anything, 2).Return(&model.City{ID: 2, Timezone: &newYork}, nil)
+	mockCityRepo.On("GetCityByID", mock.Anything, 3).Return(&model.City{ID: 3}, nil)
+	mockCityRepo.On("GetCityByID", mock.Anything, 4).Return(nil, nil)
+	mockCityRepo.On("FindNearestCity", mock.Anything, 22.57, 88.36).Return(&model.City{ID: 5, Timezone: &kolkata}, 1.2, nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 1, "en").Return("Berlin", nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 2, "en").Return("New York City", nil)
+	mockCityRepo.On("GetCityName", mock.Anything, 5, "en").Return("Kolkata", nil)
+
+	svc := NewService(mockCityRepo, new(MockCountryRepository), new(MockTranslationRepository), new(MockPostalCodeRepository), new(MockUpdateRepository))
+	svc.now = func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+	convert := func(from, to model.CityRef, at string) (*model.TimeConversionResponse, error) {
+		return svc.ConvertTime(ctx, model.TimeConversionRequest{From: from, To: to, LocalTime: at})
+	}
+
+	t.Run("between DST changes", func(t *testing.T) {
+		// New York moves its clocks on March 10, Berlin only on March 31
+		resp, err := convert(model.CityRef{ID: 1}, model.CityRef{ID: 2}, "2024-03-20T09:00")
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-20T09:00:00+01:00", resp.From.LocalTime)
+		assert.Equal(t, "2024-03-20T04:00:00-04:00", resp.To.LocalTime)
+		assert.Equal(t, "New York City", resp.To.Name)
+		assert.Equal(t, resp.From.At, resp.To.At)
+		assert.Equal(t, "-05:00", resp.OffsetDifference)
+		assert.Equal(t, -5*3600, resp.OffsetDifferenceSeconds)
+
+		resp, err = convert(model.CityRef{ID: 1}, model.CityRef{ID: 2}, "2024-01-15T09:00:30")
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-15T03:00:30-05:00", resp.To.LocalTime)
+		assert.Equal(t, "-06:00", resp.OffsetDifference)
+	})
+
+	t.Run("nearest city by coordinates", func(t *testing.T) {
+		resp, err := convert(model.CityRef{ID: 1}, model.CityRef{Coordinates: &model.Coordinate{Lat: 22.57, Lon: 88.36}}, "")
+		require.NoError(t, err)
+		assert.Equal(t, 5, resp.To.ID)
+		assert.Equal(t, "2024-01-15T17:30:00+05:30", resp.To.LocalTime)
+		assert.Equal(t, "+04:30", resp.OffsetDifference)
+	})
+
+	t.Run("invalid local time", func(t *testing.T) {
+		_, err := convert(model.CityRef{ID: 1}, model.CityRef{ID: 2}, "2024-03-20 9am")
+		assert.ErrorIs(t, err, ErrInvalidArgument)
+	})
+
+	t.Run("city without timezone", func(t *testing.T) {
+		_, err := convert(model.CityRef{ID: 3}, model.CityRef{ID: 2}, "")
+		assert.ErrorIs(t, err, ErrNoTimezone)
+		_, err = convert(model.CityRef{ID: 1}, model.CityRef{ID: 3}, "")
+		assert.ErrorIs(t, err, ErrNoTimezone)
+	})
+
+	t.Run("unknown city", func(t *testing.T) {
+		resp, err := convert(model.CityRef{ID: 1}, model.CityRef{ID: 4}, "")
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+}
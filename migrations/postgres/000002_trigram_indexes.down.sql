@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_city_translations_name_trgm;
+DROP INDEX IF EXISTS idx_cities_name_default_trgm;
+DROP FUNCTION IF EXISTS immutable_unaccent(text);
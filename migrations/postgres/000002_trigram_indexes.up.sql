@@ -0,0 +1,10 @@
+-- unaccent() is only STABLE, so it cannot appear in an index expression.
+-- This wrapper pins the dictionary, which makes it safe to mark IMMUTABLE.
+CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text AS $$
+    SELECT public.unaccent('public.unaccent', $1)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+CREATE INDEX IF NOT EXISTS idx_cities_name_default_trgm
+    ON cities USING gin (immutable_unaccent(LOWER(name_default)) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_city_translations_name_trgm
+    ON city_translations USING gin (immutable_unaccent(LOWER(name)) gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- The postgis extension itself is left installed; other objects may depend on it.
+DROP TRIGGER IF EXISTS trg_cities_set_geom ON cities;
+DROP FUNCTION IF EXISTS cities_set_geom();
+DROP INDEX IF EXISTS idx_cities_geom;
+ALTER TABLE cities DROP COLUMN IF EXISTS geom;
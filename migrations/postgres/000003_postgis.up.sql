@@ -0,0 +1,37 @@
+-- PostGIS is optional. When the extension is available, cities get a geography
+-- column kept in sync with lat/lon by a trigger and a GiST index that serves
+-- KNN (<->) nearest-city queries. Without it, or when the migrating role may
+-- not create it (managed databases, non-superusers), this migration does
+-- nothing and the repository keeps using the acos() distance formula.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'postgis') THEN
+        RAISE NOTICE 'postgis is not available, skipping the geography column';
+        RETURN;
+    END IF;
+
+    BEGIN
+        CREATE EXTENSION IF NOT EXISTS postgis;
+    EXCEPTION WHEN insufficient_privilege THEN
+        RAISE NOTICE 'not allowed to create the postgis extension, skipping the geography column';
+        RETURN;
+    END;
+
+    -- Dynamic SQL, because the geography type only exists once the extension does
+    EXECUTE 'ALTER TABLE cities ADD COLUMN IF NOT EXISTS geom geography(Point, 4326)';
+    EXECUTE 'UPDATE cities SET geom = ST_SetSRID(ST_MakePoint(lon, lat), 4326)::geography';
+    EXECUTE 'CREATE INDEX IF NOT EXISTS idx_cities_geom ON cities USING gist (geom)';
+
+    EXECUTE $sql$
+        CREATE OR REPLACE FUNCTION cities_set_geom() RETURNS trigger AS $fn$
+        BEGIN
+            NEW.geom := ST_SetSRID(ST_MakePoint(NEW.lon, NEW.lat), 4326)::geography;
+            RETURN NEW;
+        END
+        $fn$ LANGUAGE plpgsql
+    $sql$;
+    EXECUTE 'DROP TRIGGER IF EXISTS trg_cities_set_geom ON cities';
+    EXECUTE 'CREATE TRIGGER trg_cities_set_geom BEFORE INSERT OR UPDATE OF lat, lon ON cities
+             FOR EACH ROW EXECUTE FUNCTION cities_set_geom()';
+END
+$$;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS admin_division_translations;
+DROP TABLE IF EXISTS admin_divisions;
+
+ALTER TABLE cities DROP COLUMN IF EXISTS admin4_code;
+ALTER TABLE cities DROP COLUMN IF EXISTS admin3_code;
+ALTER TABLE cities DROP COLUMN IF EXISTS admin2_code;
+ALTER TABLE cities DROP COLUMN IF EXISTS admin1_code;
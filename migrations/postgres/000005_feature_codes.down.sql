@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_cities_feature_code;
+
+ALTER TABLE cities DROP COLUMN IF EXISTS feature_code;
+ALTER TABLE cities DROP COLUMN IF EXISTS feature_class;
@@ -0,0 +1,6 @@
+-- GeoNames feature class and code (cities1000 columns 6-7), e.g. P / PPLC for
+-- a capital or P / PPLA for the seat of a first-order division
+ALTER TABLE cities ADD COLUMN feature_class VARCHAR(1) NOT NULL DEFAULT '';
+ALTER TABLE cities ADD COLUMN feature_code VARCHAR(10) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_cities_feature_code ON cities(feature_code);
@@ -0,0 +1,15 @@
+ALTER TABLE countries DROP COLUMN IF EXISTS neighbours;
+ALTER TABLE countries DROP COLUMN IF EXISTS geoname_id;
+ALTER TABLE countries DROP COLUMN IF EXISTS languages;
+ALTER TABLE countries DROP COLUMN IF EXISTS postal_code_regex;
+ALTER TABLE countries DROP COLUMN IF EXISTS postal_code_format;
+ALTER TABLE countries DROP COLUMN IF EXISTS phone;
+ALTER TABLE countries DROP COLUMN IF EXISTS currency_name;
+ALTER TABLE countries DROP COLUMN IF EXISTS currency_code;
+ALTER TABLE countries DROP COLUMN IF EXISTS tld;
+ALTER TABLE countries DROP COLUMN IF EXISTS continent;
+ALTER TABLE countries DROP COLUMN IF EXISTS population;
+ALTER TABLE countries DROP COLUMN IF EXISTS area_sq_km;
+ALTER TABLE countries DROP COLUMN IF EXISTS capital;
+ALTER TABLE countries DROP COLUMN IF EXISTS iso_numeric;
+ALTER TABLE countries DROP COLUMN IF EXISTS iso3;
@@ -0,0 +1,18 @@
+-- Postal codes from the GeoNames postal code dump (export/zip). A code may
+-- cover several places, so rows have a surrogate key.
+CREATE TABLE postal_codes (
+    id BIGSERIAL PRIMARY KEY,
+    country_code VARCHAR(2) NOT NULL REFERENCES countries(code) ON DELETE CASCADE,
+    postal_code VARCHAR(20) NOT NULL,
+    place_name VARCHAR(180) NOT NULL,
+    admin1_name VARCHAR(100) NOT NULL DEFAULT '',
+    admin1_code VARCHAR(20) NOT NULL DEFAULT '',
+    admin2_name VARCHAR(100) NOT NULL DEFAULT '',
+    admin2_code VARCHAR(20) NOT NULL DEFAULT '',
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    accuracy SMALLINT
+);
+
+CREATE INDEX idx_postal_codes_code ON postal_codes(country_code, postal_code);
+CREATE INDEX idx_postal_codes_lat_lon ON postal_codes(lat, lon);
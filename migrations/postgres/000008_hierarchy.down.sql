@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_countries_geoname_id;
+DROP INDEX IF EXISTS idx_admin_divisions_geoname_id;
+ALTER TABLE admin_divisions DROP COLUMN IF EXISTS geoname_id;
+
+DROP TABLE IF EXISTS continent_translations;
+DROP TABLE IF EXISTS continents;
+DROP TABLE IF EXISTS hierarchy;
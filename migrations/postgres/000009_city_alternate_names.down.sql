@@ -0,0 +1 @@
+DROP TABLE IF EXISTS city_alternate_names;
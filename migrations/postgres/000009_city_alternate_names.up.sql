@@ -0,0 +1,15 @@
+-- Every alternate name of a city as listed in alternateNames.txt, including
+-- colloquial and historic names and several names per language.
+-- city_translations keeps the single display name per (city_id, lang).
+CREATE TABLE city_alternate_names (
+    id BIGINT PRIMARY KEY, -- GeoNames alternateNameId
+    city_id INTEGER NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    lang VARCHAR(10) NOT NULL DEFAULT '',
+    name VARCHAR(400) NOT NULL,
+    is_preferred BOOLEAN NOT NULL DEFAULT FALSE,
+    is_short BOOLEAN NOT NULL DEFAULT FALSE,
+    is_colloquial BOOLEAN NOT NULL DEFAULT FALSE,
+    is_historic BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX idx_city_alternate_names_city_id ON city_alternate_names(city_id);
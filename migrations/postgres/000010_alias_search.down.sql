@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_city_alternate_names_alias_trgm;
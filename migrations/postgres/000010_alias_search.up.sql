@@ -0,0 +1,5 @@
+-- Historic and colloquial names are searchable as aliases when suggest is
+-- called with include_aliases=true. The partial index covers exactly them.
+CREATE INDEX IF NOT EXISTS idx_city_alternate_names_alias_trgm
+    ON city_alternate_names USING gin (immutable_unaccent(LOWER(name)) gin_trgm_ops)
+    WHERE is_historic OR is_colloquial;
@@ -0,0 +1,11 @@
+-- Full dump imports and GeoNames daily update files applied by the seeder.
+-- The latest update_date (YYYY-MM-DD) is where the next update run resumes.
+CREATE TABLE data_updates (
+    update_date VARCHAR(10) PRIMARY KEY,
+    source VARCHAR(10) NOT NULL,
+    cities_modified INTEGER NOT NULL DEFAULT 0,
+    cities_deleted INTEGER NOT NULL DEFAULT 0,
+    names_modified INTEGER NOT NULL DEFAULT 0,
+    names_deleted INTEGER NOT NULL DEFAULT 0,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,9 @@
+-- Progress of the seeder per import phase. line_offset is the number of
+-- lines of the phase's file imported so far; an interrupted seed resumes
+-- there. Completed phases are skipped.
+CREATE TABLE seed_state (
+    phase VARCHAR(32) PRIMARY KEY,
+    line_offset BIGINT NOT NULL DEFAULT 0,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS admin_division_translations;
+DROP TABLE IF EXISTS admin_divisions;
+
+ALTER TABLE cities DROP COLUMN admin4_code;
+ALTER TABLE cities DROP COLUMN admin3_code;
+ALTER TABLE cities DROP COLUMN admin2_code;
+ALTER TABLE cities DROP COLUMN admin1_code;
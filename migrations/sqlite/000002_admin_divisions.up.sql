@@ -0,0 +1,23 @@
+-- GeoNames admin codes (cities1000 columns 10-13). Empty when not applicable.
+ALTER TABLE cities ADD COLUMN admin1_code VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE cities ADD COLUMN admin2_code VARCHAR(80) NOT NULL DEFAULT '';
+ALTER TABLE cities ADD COLUMN admin3_code VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE cities ADD COLUMN admin4_code VARCHAR(20) NOT NULL DEFAULT '';
+
+-- First and second level administrative divisions from admin1CodesASCII.txt
+-- and admin2Codes.txt. Codes are the GeoNames keys: "US.IL" and "US.IL.031".
+CREATE TABLE admin_divisions (
+    code VARCHAR(110) PRIMARY KEY,
+    country_code VARCHAR(2) NOT NULL REFERENCES countries(code) ON DELETE CASCADE,
+    level SMALLINT NOT NULL,
+    name_default VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE admin_division_translations (
+    division_code VARCHAR(110) NOT NULL REFERENCES admin_divisions(code) ON DELETE CASCADE,
+    lang VARCHAR(2) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (division_code, lang)
+);
+
+CREATE INDEX idx_admin_divisions_country_code ON admin_divisions(country_code);
@@ -0,0 +1,15 @@
+ALTER TABLE countries DROP COLUMN neighbours;
+ALTER TABLE countries DROP COLUMN geoname_id;
+ALTER TABLE countries DROP COLUMN languages;
+ALTER TABLE countries DROP COLUMN postal_code_regex;
+ALTER TABLE countries DROP COLUMN postal_code_format;
+ALTER TABLE countries DROP COLUMN phone;
+ALTER TABLE countries DROP COLUMN currency_name;
+ALTER TABLE countries DROP COLUMN currency_code;
+ALTER TABLE countries DROP COLUMN tld;
+ALTER TABLE countries DROP COLUMN continent;
+ALTER TABLE countries DROP COLUMN population;
+ALTER TABLE countries DROP COLUMN area_sq_km;
+ALTER TABLE countries DROP COLUMN capital;
+ALTER TABLE countries DROP COLUMN iso_numeric;
+ALTER TABLE countries DROP COLUMN iso3;
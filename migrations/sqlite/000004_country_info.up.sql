@@ -0,0 +1,17 @@
+-- The remaining countryInfo.txt columns. Languages and neighbours keep the
+-- comma-separated form of the source file.
+ALTER TABLE countries ADD COLUMN iso3 VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN iso_numeric VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN capital VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN area_sq_km DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE countries ADD COLUMN population BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE countries ADD COLUMN continent VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN tld VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN currency_code VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN currency_name VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN phone VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN postal_code_format VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN postal_code_regex VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN languages VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE countries ADD COLUMN geoname_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE countries ADD COLUMN neighbours VARCHAR(255) NOT NULL DEFAULT '';
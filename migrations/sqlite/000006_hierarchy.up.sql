@@ -0,0 +1,29 @@
+-- Parent/child pairs from hierarchy.txt. Both ends are geonameids, which may
+-- be cities, admin divisions, countries, continents or places that are not
+-- imported (third-level divisions, the Earth), so there are no foreign keys.
+CREATE TABLE hierarchy (
+    parent_id INTEGER NOT NULL,
+    child_id INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL DEFAULT '',
+    PRIMARY KEY (child_id, parent_id)
+);
+
+-- Continents are the top of the hierarchy below the Earth
+CREATE TABLE continents (
+    code VARCHAR(2) PRIMARY KEY,
+    geoname_id INTEGER NOT NULL,
+    name_default VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE continent_translations (
+    continent_code VARCHAR(2) NOT NULL REFERENCES continents(code) ON DELETE CASCADE,
+    lang VARCHAR(2) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (continent_code, lang)
+);
+
+-- Admin divisions and countries are found by geonameid when walking the hierarchy
+ALTER TABLE admin_divisions ADD COLUMN geoname_id INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_admin_divisions_geoname_id ON admin_divisions(geoname_id);
+CREATE INDEX idx_countries_geoname_id ON countries(geoname_id);